@@ -60,3 +60,58 @@ func (t *tracer) finish() {
 	t.EndTime = now.Format(time.RFC3339Nano)
 	t.Duration = now.Sub(t.GoStartTime).Nanoseconds()
 }
+
+// apolloTracer writes apollo tracing data to the tracing extension of the response
+// The tracing data is stored within the ctx of the request
+type apolloTracer struct{}
+
+// apolloSpan reports it's start offset and duration relative to the start of the request
+type apolloSpan struct {
+	ctx    *Ctx
+	start  time.Time
+	report func(offset, duration int64)
+}
+
+func (s *apolloSpan) End() {
+	offset := s.start.Sub(s.ctx.tracing.GoStartTime).Nanoseconds()
+	duration := time.Since(s.start).Nanoseconds()
+	s.report(offset, duration)
+}
+
+type apolloOperationSpan struct{}
+
+func (apolloOperationSpan) End() {
+	// The tracing data is finished when it's written to the response
+}
+
+func (apolloTracer) StartOperation(ctx *Ctx, query []byte, operationName string) Span {
+	ctx.tracing.reset()
+	return apolloOperationSpan{}
+}
+
+func (apolloTracer) StartParsing(ctx *Ctx) Span {
+	return &apolloSpan{ctx: ctx, start: time.Now(), report: func(offset, duration int64) {
+		ctx.tracing.Parsing.StartOffset = offset
+		ctx.tracing.Parsing.Duration = duration
+	}}
+}
+
+func (apolloTracer) StartValidation(ctx *Ctx) Span {
+	return &apolloSpan{ctx: ctx, start: time.Now(), report: func(offset, duration int64) {
+		ctx.tracing.Validation.StartOffset = offset
+		ctx.tracing.Validation.Duration = duration
+	}}
+}
+
+func (apolloTracer) StartField(ctx *Ctx, field TracedField) Span {
+	return &apolloSpan{ctx: ctx, start: time.Now(), report: func(offset, duration int64) {
+		ctx.tracing.Execution.Resolvers = append(ctx.tracing.Execution.Resolvers, tracerResolver{
+			Path:        field.Path,
+			ParentType:  field.ParentType,
+			FieldName:   field.FieldName,
+			ReturnType:  field.ReturnType,
+			StartOffset: offset,
+			Duration:    duration,
+		})
+	}}
+}
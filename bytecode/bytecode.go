@@ -5,6 +5,7 @@ import (
 	"errors"
 	"hash"
 	"hash/fnv"
+	"sort"
 	"unicode/utf16"
 	"unicode/utf8"
 	"unsafe"
@@ -16,6 +17,7 @@ import (
 type ParserCtx struct {
 	Res                  []byte
 	FragmentLocations    []int
+	Locations            []int // Pairs of a Res index followed by the Query index the instruction at that Res index was parsed from
 	Query                []byte
 	charNr               int
 	Errors               []error
@@ -24,7 +26,8 @@ type ParserCtx struct {
 	TargetIdx            int // -1 = no matching target was found, >= 0 = res index of target
 	Hasher               hash.Hash32
 	cache                *cache.BytecodeCache
-	CacheableQueryMinLen int // Default = 300
+	CacheableQueryMinLen int  // Default = 300
+	constValue           bool // Set while parsing a value using ParseValueToBytecode, variables are not allowed in these values
 }
 
 // NewParserCtx returns a new instance of ParserCtx
@@ -32,6 +35,7 @@ func NewParserCtx() *ParserCtx {
 	return &ParserCtx{
 		Res:                  make([]byte, 2048),
 		FragmentLocations:    make([]int, 8),
+		Locations:            make([]int, 64),
 		Query:                make([]byte, 2048),
 		Errors:               []error{},
 		Hasher:               fnv.New32(),
@@ -46,6 +50,7 @@ func (ctx *ParserCtx) ParseQueryToBytecode(target *string) {
 	*ctx = ParserCtx{
 		Res:                  ctx.Res[:0],
 		FragmentLocations:    ctx.FragmentLocations[:0],
+		Locations:            ctx.Locations[:0],
 		Query:                ctx.Query,
 		Errors:               ctx.Errors[:0],
 		target:               target,
@@ -58,10 +63,11 @@ func (ctx *ParserCtx) ParseQueryToBytecode(target *string) {
 
 	cacheableQuery := len(ctx.Query) > ctx.CacheableQueryMinLen
 	if cacheableQuery {
-		res, fragmentLocations, targetIdx := ctx.cache.GetEntry(ctx.Query, target)
+		res, fragmentLocations, locations, targetIdx := ctx.cache.GetEntry(ctx.Query, target)
 		if res != nil {
 			ctx.Res = append(ctx.Res, res...)
 			ctx.FragmentLocations = append(ctx.FragmentLocations, fragmentLocations...)
+			ctx.Locations = append(ctx.Locations, locations...)
 			ctx.TargetIdx = targetIdx
 			return
 		}
@@ -70,13 +76,56 @@ func (ctx *ParserCtx) ParseQueryToBytecode(target *string) {
 	for {
 		if ctx.parseOperatorOrFragment() {
 			if cacheableQuery && len(ctx.Errors) == 0 {
-				ctx.cache.SetEntry(ctx.Query, ctx.Res, target, ctx.TargetIdx, ctx.FragmentLocations)
+				ctx.cache.SetEntry(ctx.Query, ctx.Res, target, ctx.TargetIdx, ctx.FragmentLocations, ctx.Locations)
 			}
 			return
 		}
 	}
 }
 
+// ParseValueToBytecode parses a constant input value, like the default value of an argument, into (*ParserCtx).Res
+// The value is written as a single value instruction starting at the first byte of (*ParserCtx).Res
+func (ctx *ParserCtx) ParseValueToBytecode(value []byte) {
+	*ctx = ParserCtx{
+		Res:               ctx.Res[:0],
+		FragmentLocations: ctx.FragmentLocations[:0],
+		Locations:         ctx.Locations[:0],
+		// The parser expects a character after names and numbers so a space is added after the value
+		Query:                append(append(ctx.Query[:0], value...), ' '),
+		Errors:               ctx.Errors[:0],
+		TargetIdx:            -1,
+		Hasher:               ctx.Hasher,
+		cache:                ctx.cache,
+		CacheableQueryMinLen: ctx.CacheableQueryMinLen,
+		constValue:           true,
+	}
+
+	if ctx.parseInputValue() {
+		return
+	}
+
+	c, eof := ctx.mightIgnoreNextTokens()
+	if !eof {
+		ctx.err(`unexpected character after value: "` + string(c) + `"`)
+	}
+}
+
+// LoadBytecode sets the parser result to the result of a earlier parsed query
+// This can be used to skip parsing of queries that are known ahead of time
+func (ctx *ParserCtx) LoadBytecode(query []byte, res []byte, fragmentLocations []int, locations []int, targetIdx int) {
+	*ctx = ParserCtx{
+		Res:                  append(ctx.Res[:0], res...),
+		FragmentLocations:    append(ctx.FragmentLocations[:0], fragmentLocations...),
+		Locations:            append(ctx.Locations[:0], locations...),
+		Query:                append(ctx.Query[:0], query...),
+		Errors:               ctx.Errors[:0],
+		TargetIdx:            targetIdx,
+		Hasher:               ctx.Hasher,
+		cache:                ctx.cache,
+		CacheableQueryMinLen: ctx.CacheableQueryMinLen,
+	}
+}
+
 func (ctx *ParserCtx) writeUint32(value uint32, at int) {
 	ctx.Res[at] = byte(0xff & value)
 	ctx.Res[at+1] = byte(0xff & (value >> 8))
@@ -84,6 +133,11 @@ func (ctx *ParserCtx) writeUint32(value uint32, at int) {
 	ctx.Res[at+3] = byte(0xff & (value >> 24))
 }
 
+// markLocation remembers the query location of the instruction that is about to be written to ctx.Res
+func (ctx *ParserCtx) markLocation() {
+	ctx.Locations = append(ctx.Locations, len(ctx.Res), ctx.charNr)
+}
+
 // - https://spec.graphql.org/October2021/#sec-Language.Operations
 // - https://spec.graphql.org/October2021/#FragmentDefinition
 func (ctx *ParserCtx) parseOperatorOrFragment() (stop bool) {
@@ -93,6 +147,7 @@ func (ctx *ParserCtx) parseOperatorOrFragment() (stop bool) {
 	}
 
 	operationStartsAt := len(ctx.Res)
+	ctx.markLocation()
 	if c == '{' {
 		if !ctx.hasTarget {
 			ctx.TargetIdx = operationStartsAt
@@ -259,6 +314,7 @@ func (ctx *ParserCtx) parseOperatorArguments() bool {
 		if c != '$' {
 			return ctx.err(`expected "$" but got "` + string(c) + `"`)
 		}
+		ctx.markLocation()
 		ctx.charNr++
 
 		criticalErr := ctx.parseOperatorArgument()
@@ -348,6 +404,7 @@ func (ctx *ParserCtx) parseDirectives() (directivesAmount uint8, criticalErr boo
 		}
 
 		directivesAmount++
+		ctx.markLocation()
 		ctx.charNr++
 		ctx.instructionNewDirective()
 		hasArgsFlag := len(ctx.Res) - 1
@@ -448,6 +505,7 @@ func (ctx *ParserCtx) parseSelectionSet() bool {
 	}
 
 	for {
+		ctx.markLocation()
 		ctx.instructionNewField()
 		directivesCountLocation := len(ctx.Res) - 9
 		startField := len(ctx.Res)
@@ -460,9 +518,11 @@ func (ctx *ParserCtx) parseSelectionSet() bool {
 		ctx.Res[startField] = aliasOrNameLen
 
 		if aliasOrNameLen == 0 {
-			// Revert changes from ctx.instructionNewField()
+			// Revert changes from ctx.instructionNewField() and ctx.markLocation()
 			ctx.Res = ctx.Res[:len(ctx.Res)-12]
+			ctx.Locations = ctx.Locations[:len(ctx.Locations)-2]
 
+			ctx.markLocation()
 			if ctx.matches("...") == 0 {
 				// Is pointer to fragment or inline fragment
 				_, eof := ctx.mightIgnoreNextTokens()
@@ -659,6 +719,7 @@ func (ctx *ParserCtx) parseAssignmentSet(closure byte) bool {
 	}
 
 	for {
+		ctx.markLocation()
 		ctx.instructionStartNewValueObjectField()
 
 		nameLen, criticalErr := ctx.parseAndWriteName()
@@ -708,8 +769,12 @@ func (ctx *ParserCtx) parseInputValue() bool {
 	if eof {
 		return ctx.unexpectedEOF()
 	}
+	ctx.markLocation()
 
 	if c == '$' {
+		if ctx.constValue {
+			return ctx.err("variables are not allowed in constant values")
+		}
 		ctx.charNr++
 		ctx.instructionNewValueVariable()
 		startOfVariable := len(ctx.Res)
@@ -1269,10 +1334,20 @@ func (e ErrorWLocation) Error() string {
 }
 
 func (ctx *ParserCtx) err(err string) bool {
-	line := uint(1)
-	column := uint(0)
+	line, column := ctx.LineAndColumn(ctx.charNr)
+	ctx.Errors = append(ctx.Errors, ErrorWLocation{
+		errors.New(err),
+		line,
+		column,
+	})
+	return true
+}
+
+// LineAndColumn returns the line and column of a index in (*ParserCtx).Query
+func (ctx *ParserCtx) LineAndColumn(queryIdx int) (line uint, column uint) {
+	line = 1
 	for idx, char := range ctx.Query {
-		if idx == ctx.charNr {
+		if idx == queryIdx {
 			break
 		}
 
@@ -1291,13 +1366,21 @@ func (ctx *ParserCtx) err(err string) bool {
 			column++
 		}
 	}
+	return line, column
+}
 
-	ctx.Errors = append(ctx.Errors, ErrorWLocation{
-		errors.New(err),
-		line,
-		uint(column),
+// LocationOf returns the line and column in the query of the instruction that starts at resIdx
+// ok is false if the location of the instruction is not known
+func (ctx *ParserCtx) LocationOf(resIdx int) (line uint, column uint, ok bool) {
+	pairs := len(ctx.Locations) / 2
+	idx := sort.Search(pairs, func(i int) bool {
+		return ctx.Locations[i*2] >= resIdx
 	})
-	return true
+	if idx == pairs || ctx.Locations[idx*2] != resIdx {
+		return 0, 0, false
+	}
+	line, column = ctx.LineAndColumn(ctx.Locations[idx*2+1])
+	return line, column, true
 }
 
 func (ctx *ParserCtx) unexpectedEOF() bool {
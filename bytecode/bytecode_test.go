@@ -731,6 +731,34 @@ func TestMoreThan255Directives(t *testing.T) {
 	parseQueryAndExpectErr(t, `{bar`+strings.Repeat(" @foo", 256)+`}`, "cannot have more than 255 directives")
 }
 
+func TestLocationOf(t *testing.T) {
+	i := NewParserCtx()
+	i.Query = []byte("{\n  foo\n  ...bar\n}")
+	i.ParseQueryToBytecode(nil)
+	a.Equal(t, 0, len(i.Errors))
+
+	// operation, field and spread
+	a.Equal(t, 6, len(i.Locations))
+
+	line, column, ok := i.LocationOf(0)
+	a.True(t, ok)
+	a.Equal(t, uint(1), line)
+	a.Equal(t, uint(0), column)
+
+	line, column, ok = i.LocationOf(i.Locations[2])
+	a.True(t, ok)
+	a.Equal(t, uint(2), line)
+	a.Equal(t, uint(2), column)
+
+	line, column, ok = i.LocationOf(i.Locations[4])
+	a.True(t, ok)
+	a.Equal(t, uint(3), line)
+	a.Equal(t, uint(2), column)
+
+	_, _, ok = i.LocationOf(1)
+	a.False(t, ok)
+}
+
 // tests if parser doesn't panic nor hangs on wired inputs
 func injectCodeSurviveTest(baseQuery string, extraChars ...[][]byte) {
 	toTest := [][][]byte{
@@ -793,3 +821,48 @@ func injectCodeSurviveTest(baseQuery string, extraChars ...[][]byte) {
 
 	wg.Wait()
 }
+
+func TestLoadBytecode(t *testing.T) {
+	parsed := NewParserCtx()
+	parsed.Query = []byte("query foo {\n  bar\n}")
+	parsed.ParseQueryToBytecode(nil)
+	a.Equal(t, 0, len(parsed.Errors))
+
+	i := NewParserCtx()
+	i.Query = []byte("{baz}")
+	i.ParseQueryToBytecode(nil)
+	i.LoadBytecode(parsed.Query, parsed.Res, parsed.FragmentLocations, parsed.Locations, parsed.TargetIdx)
+
+	a.Equal(t, parsed.Res, i.Res)
+	a.Equal(t, parsed.TargetIdx, i.TargetIdx)
+	line, column, ok := i.LocationOf(parsed.Locations[2])
+	a.True(t, ok)
+	a.Equal(t, uint(2), line)
+	a.Equal(t, uint(2), column)
+}
+
+func TestParseValueToBytecode(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected testValue
+	}{
+		{`20`, testValue{kind: ValueInt, intValue: 20}},
+		{` "foo" `, testValue{kind: ValueString, stringValue: "foo"}},
+		{`ACTIVE`, testValue{kind: ValueEnum, enumValue: "ACTIVE"}},
+		{`true`, testValue{kind: ValueBoolean, boolValue: true}},
+		{`[1, 2]`, testValue{kind: ValueList, list: []testValue{{kind: ValueInt, intValue: 1}, {kind: ValueInt, intValue: 2}}}},
+		{`{a: null}`, testValue{kind: ValueObject, objectValue: []typeObjectValue{{name: "a", value: testValue{kind: ValueNull}}}}},
+	}
+
+	i := NewParserCtx()
+	for _, testCase := range testCases {
+		i.ParseValueToBytecode([]byte(testCase.value))
+		a.Equal(t, 0, len(i.Errors), testCase.value)
+		a.Equal(t, testCase.expected.toBytes([]byte{}), i.Res, testCase.value)
+	}
+
+	for _, value := range []string{``, `$foo`, `[$foo]`, `1 2`, `{a: 1`} {
+		i.ParseValueToBytecode([]byte(value))
+		a.NotEqual(t, 0, len(i.Errors), value)
+	}
+}
@@ -17,26 +17,27 @@ type cacheEntry struct {
 	target           *string
 	targetIdx        int
 	fragmentLocation []int
+	locations        []int
 }
 
-// GetEntry might return the bytecode, the fragment locations of the query and targetIdx
-func (c BytecodeCache) GetEntry(query []byte, target *string) ([]byte, []int, int) {
+// GetEntry might return the bytecode, the fragment locations of the query, the instruction locations and targetIdx
+func (c BytecodeCache) GetEntry(query []byte, target *string) ([]byte, []int, []int, int) {
 	entries, ok := c[len(query)]
 	if !ok {
-		return nil, nil, -1
+		return nil, nil, nil, -1
 	}
 
 	for _, entry := range entries {
 		if bytes.Equal(entry.query, query) && ((target == nil && entry.target == nil) || (target != nil && entry.target != nil && *target == *entry.target)) {
-			return entry.bytecode, entry.fragmentLocation, entry.targetIdx
+			return entry.bytecode, entry.fragmentLocation, entry.locations, entry.targetIdx
 		}
 	}
 
-	return nil, nil, -1
+	return nil, nil, nil, -1
 }
 
 // SetEntry sets a new entry in the cache
-func (c BytecodeCache) SetEntry(query, bytecode []byte, target *string, targetIdx int, fragmentLocation []int) {
+func (c BytecodeCache) SetEntry(query, bytecode []byte, target *string, targetIdx int, fragmentLocation []int, locations []int) {
 	if len(c) == 100 {
 		// Remove some random entries
 		// FIXME Dunno if this is a good value to start dropping stuff
@@ -70,10 +71,13 @@ func (c BytecodeCache) SetEntry(query, bytecode []byte, target *string, targetId
 		bytecode:         make([]byte, len(bytecode)),
 		target:           targetCopy,
 		targetIdx:        targetIdx,
-		fragmentLocation: fragmentLocation,
+		fragmentLocation: make([]int, len(fragmentLocation)),
+		locations:        make([]int, len(locations)),
 	}
 	copy(newCacheEntry.query, query)
 	copy(newCacheEntry.bytecode, bytecode)
+	copy(newCacheEntry.fragmentLocation, fragmentLocation)
+	copy(newCacheEntry.locations, locations)
 
 	c[queryLen] = append([]cacheEntry{newCacheEntry}, entries...)
 }
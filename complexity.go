@@ -0,0 +1,275 @@
+package yarql
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/mjarkk/yarql/bytecode"
+	"github.com/valyala/fastjson"
+)
+
+// This file contains the static query complexity calculation, it runs after validation and before execution
+//
+// Every field costs 1 unless another cost is defined using the gq struct tag (`gq:",cost=10"`) or a Costs method
+// The cost of a field with a selection set is it's own cost plus the cost of the selection set,
+// for list fields the cost of the selection set is multiplied by the value of the first, last or limit argument
+
+// Coster can be implemented by structs to set the complexity cost of their fields
+// The keys of the returned map are the graphql names of the fields
+//
+// Example:
+//
+//	func (User) Costs() map[string]int {
+//		return map[string]int{
+//			"posts": 10,
+//		}
+//	}
+//
+// Struct fields can also have a cost using the gq struct tag, these take precedence over the costs returned by Costs
+//
+//	type User struct {
+//		Friends []User `gq:",cost=10"`
+//	}
+type Coster interface {
+	Costs() map[string]int
+}
+
+var costerType = reflect.TypeOf((*Coster)(nil)).Elem()
+
+const defaultFieldCost = 1
+
+// maxInt is the max value of a int, complexities are capped at this value so they cannot overflow
+const maxInt = int(^uint(0) >> 1)
+
+// complexityMultiplierArguments are the arguments of list fields that multiply the cost of the selection set
+var complexityMultiplierArguments = []string{"first", "last", "limit"}
+
+// getCosts returns the costs of a struct type that implements Coster
+func getCosts(t reflect.Type) map[string]int {
+	if t.Kind() != reflect.Struct || !reflect.PtrTo(t).Implements(costerType) {
+		return nil
+	}
+	return reflect.New(t).Interface().(Coster).Costs()
+}
+
+// fieldCost returns the cost of a field or nil if the default cost should be used
+func fieldCost(costs map[string]int, qlFieldName string) *int {
+	cost, ok := costs[qlFieldName]
+	if !ok {
+		return nil
+	}
+	return &cost
+}
+
+// checkComplexity calculates the complexity of the operation that will be executed
+// If the complexity is higher than ctx.maxComplexity a error is added
+func (ctx *Ctx) checkComplexity() {
+	start := ctx.query.TargetIdx
+	if start == -1 {
+		return
+	}
+
+	ctx.complexity = ctx.operationComplexity(start)
+	if ctx.complexity > ctx.maxComplexity {
+		ctx.validationErrf(start, "Query complexity of %d exceeds the max allowed complexity of %d.", ctx.complexity, ctx.maxComplexity)
+	}
+}
+
+func (ctx *Ctx) operationComplexity(start int) int {
+	res := ctx.query.Res
+
+	kind := res[start+2]
+	hasArguments := res[start+3] == 't'
+	directivesCount := res[start+4]
+	_, pos := ctx.readNameAt(start + 5)
+
+	if hasArguments {
+		pos += 5 + int(ctx.readUint32(pos+1))
+	}
+	pos = ctx.skipDirectives(pos, directivesCount)
+
+	switch kind {
+	case bytecode.OperatorMutation:
+		return ctx.selectionSetComplexity(pos, ctx.schema.rootMethod)
+	case bytecode.OperatorSubscription:
+		return ctx.selectionSetComplexity(pos, ctx.schema.rootSubscription)
+	default:
+		return ctx.selectionSetComplexity(pos, ctx.schema.rootQuery)
+	}
+}
+
+// selectionSetComplexity returns the complexity of the selection set starting at pos
+// Fragments are always counted, even if they target another type than the resolved value
+func (ctx *Ctx) selectionSetComplexity(pos int, parentType *obj) int {
+	res := ctx.query.Res
+	complexity := 0
+	for {
+		switch res[pos+1] {
+		case bytecode.ActionField:
+			end := pos + 11 + int(ctx.readUint32(pos+3))
+			complexity = addComplexity(complexity, ctx.fieldComplexity(pos, parentType))
+			pos = end
+		case bytecode.ActionSpread:
+			isInline := res[pos+2] == 't'
+			directivesCount := res[pos+3]
+			end := pos + 8 + int(ctx.readUint32(pos+4))
+			name, selectionStart := ctx.readNameAt(pos + 8)
+			if isInline {
+				typeObj, ok := ctx.schema.types[b2s(name)]
+				if !ok {
+					typeObj = ctx.schema.interfaces[b2s(name)]
+				}
+				complexity = addComplexity(complexity, ctx.selectionSetComplexity(ctx.skipDirectives(selectionStart, directivesCount), typeObj))
+			} else {
+				// Validation already made sure the fragment exists and has no cycles
+				for _, definition := range ctx.validator.definitions {
+					if definition.isFragment && bytes.Equal(definition.name, name) {
+						complexity = addComplexity(complexity, ctx.selectionSetComplexity(definition.selectionStart, definition.typeObj))
+						break
+					}
+				}
+			}
+			pos = end
+		default:
+			// bytecode.ActionEnd
+			return complexity
+		}
+	}
+}
+
+func (ctx *Ctx) fieldComplexity(start int, parentType *obj) int {
+	res := ctx.query.Res
+
+	directivesCount := res[start+2]
+	nameKey := ctx.readUint32(start + 7)
+	aliasLen := int(res[start+11])
+	nameLen := int(res[start+12+aliasLen])
+	pos := start + 13 + aliasLen + nameLen
+
+	field := parentType.objContents[nameKey]
+	if field == nil {
+		// __typename
+		return 0
+	}
+
+	pos = ctx.skipDirectives(pos, directivesCount)
+
+	multiplier := 1
+	if res[pos+1] == bytecode.ActionValue {
+		argumentsEnd := pos + 7 + int(ctx.readUint32(pos+3))
+		if ctx.schema.isListField(field) {
+			for argument := pos + 7; res[argument+1] == bytecode.ActionObjectValueField; {
+				name, valueStart := ctx.readNameAt(argument + 2)
+				argument = valueStart + 7 + int(ctx.readUint32(valueStart+3))
+				if !containsString(complexityMultiplierArguments, b2s(name)) {
+					continue
+				}
+				value, ok := ctx.intValue(valueStart)
+				if !ok || value < 0 {
+					continue
+				}
+				if value > math.MaxInt32 {
+					ctx.validationErrf(valueStart, "Int cannot represent non 32-bit signed integer value: %s", ctx.appendValue(valueStart, nil))
+					continue
+				}
+				multiplier = int(value)
+			}
+		}
+		pos = argumentsEnd
+	}
+
+	cost := defaultFieldCost
+	if field.cost != nil {
+		cost = *field.cost
+	}
+
+	if res[pos+1] == bytecode.ActionEnd {
+		return cost
+	}
+	return addComplexity(cost, multiplyComplexity(multiplier, ctx.selectionSetComplexity(pos, ctx.schema.namedOutputType(field))))
+}
+
+// addComplexity returns a + b capped at maxInt
+func addComplexity(a, b int) int {
+	if b > 0 && a > maxInt-b {
+		return maxInt
+	}
+	return a + b
+}
+
+// multiplyComplexity returns a * b capped at maxInt
+func multiplyComplexity(a, b int) int {
+	if a > 0 && b > maxInt/a {
+		return maxInt
+	}
+	return a * b
+}
+
+// isListField returns true if the field resolves to a list
+func (s *Schema) isListField(item *obj) bool {
+	for {
+		switch item.valueType {
+		case valueTypeMethod:
+			item = &item.method.outType
+		case valueTypePtr:
+			item = item.innerContent
+		case valueTypeArray:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// intValue returns the int value of the value starting at pos
+// Variables are resolved using the request variables or the default value of the variable
+// Values that don't fit in a int64 are capped at the min or max int64
+func (ctx *Ctx) intValue(pos int) (int64, bool) {
+	res := ctx.query.Res
+	start := pos + 7
+	end := start + int(ctx.readUint32(pos+3))
+
+	switch res[pos+2] {
+	case bytecode.ValueInt:
+		value, err := strconv.ParseInt(b2s(res[start:end]), 10, 64)
+		return value, err == nil || errors.Is(err, strconv.ErrRange)
+	case bytecode.ValueVariable:
+		name := res[start:end]
+		hasVariables, _ := ctx.parseVariables()
+		if hasVariables {
+			value := ctx.variables.Get(b2s(name))
+			if value != nil {
+				if value.Type() != fastjson.TypeNumber {
+					return 0, false
+				}
+				number := value.GetFloat64()
+				switch {
+				case number != math.Trunc(number):
+					return 0, false
+				case number >= math.MaxInt64:
+					return math.MaxInt64, true
+				case number <= math.MinInt64:
+					return math.MinInt64, true
+				}
+				return int64(number), true
+			}
+		}
+
+		v := &ctx.validator
+		for _, definition := range v.definitions {
+			if definition.isFragment || definition.start != ctx.query.TargetIdx {
+				continue
+			}
+			for _, variable := range v.variables[definition.variablesStart:definition.variablesEnd] {
+				if variable.hasDefault && bytes.Equal(variable.name, name) {
+					_, typeEnd := ctx.readNameAt(variable.typeStart)
+					return ctx.intValue(typeEnd + 2)
+				}
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,148 @@
+package yarql
+
+import (
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+type TestComplexityData struct {
+	Name    string
+	Friends []TestComplexityUser `gq:",cost=2"`
+	Best    TestComplexityUser
+}
+
+func (TestComplexityData) Costs() map[string]int {
+	return map[string]int{
+		"users": 5,
+		"best":  3,
+	}
+}
+
+func (TestComplexityData) ResolveUsers(args struct {
+	First *int
+}) []TestComplexityUser {
+	return []TestComplexityUser{{Name: "a"}, {Name: "b"}}
+}
+
+func (TestComplexityData) ResolveRanked(args struct {
+	Limit *float64
+}) []TestComplexityUser {
+	return []TestComplexityUser{}
+}
+
+type TestComplexityUser struct {
+	Name string
+	Age  int `gq:",cost=0"`
+}
+
+func (TestComplexityUser) ResolveFollowers(args struct {
+	First *int
+}) []TestComplexityUser {
+	return []TestComplexityUser{}
+}
+
+func complexityOf(t *testing.T, query string, opts ...ResolveOptions) (int, []error) {
+	s := NewSchema()
+	err := s.Parse(TestComplexityData{}, M{}, nil)
+	a.NoError(t, err)
+
+	options := ResolveOptions{MaxComplexity: 1000}
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	errs := s.Resolve([]byte(query), options)
+	return s.ctx.complexity, errs
+}
+
+func TestComplexity(t *testing.T) {
+	options := []struct {
+		query      string
+		complexity int
+	}{
+		{`{name}`, 1},
+		{`{__typename name}`, 1},
+		{`{name age: name}`, 2},
+		{`{best {name age}}`, 4},
+		{`{friends {name}}`, 3},
+		{`{users {name}}`, 6},
+		{`{users(first: 10) {name}}`, 15},
+		{`{users(first: 0) {name}}`, 5},
+		{`{best {...user} users(first: 2) {...user}} fragment user on TestComplexityUser {name age}`, 11},
+		{`{best {... on TestComplexityUser {name age}}}`, 4},
+	}
+
+	for _, option := range options {
+		complexity, errs := complexityOf(t, option.query)
+		for _, err := range errs {
+			panic(err.Error())
+		}
+		a.Equal(t, option.complexity, complexity, option.query)
+	}
+}
+
+func TestComplexityVariables(t *testing.T) {
+	query := `query($first: Int = 4) {users(first: $first) {name}}`
+
+	complexity, errs := complexityOf(t, query)
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, 9, complexity)
+
+	complexity, errs = complexityOf(t, query, ResolveOptions{MaxComplexity: 1000, Variables: `{"first": 20}`})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, 25, complexity)
+}
+
+func TestComplexityMax(t *testing.T) {
+	s := NewSchema()
+	err := s.Parse(TestComplexityData{}, M{}, nil)
+	a.NoError(t, err)
+
+	errs := s.Resolve([]byte(`{users(first: 100) {name}}`), ResolveOptions{MaxComplexity: 100})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "Query complexity of 105 exceeds the max allowed complexity of 100.", errs[0].Error())
+	a.Equal(t, `{"data":{},"errors":[{"message":"Query complexity of 105 exceeds the max allowed complexity of 100.","locations":[{"line":1,"column":1}]}],"extensions":{"complexity":{"cost":105,"max":100}}}`, string(s.Result))
+
+	errs = s.Resolve([]byte(`{users(first: 1) {name}}`), ResolveOptions{MaxComplexity: 100})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"users":[{"name":"a"},{"name":"b"}]},"extensions":{"complexity":{"cost":6,"max":100}}}`, string(s.Result))
+
+	// Without a max complexity the complexity is not calculated
+	errs = s.Resolve([]byte(`{users(first: 100) {name}}`), ResolveOptions{})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"users":[{"name":"a"},{"name":"b"}]}}`, string(s.Result))
+}
+
+func TestComplexityOverflow(t *testing.T) {
+	options := ResolveOptions{MaxComplexity: 100}
+
+	// The complexity is capped instead of overflowing
+	query := `{users(first: 2147483647) {followers(first: 2147483647) {followers(first: 2147483647) {followers(first: 2147483647) {name}}}}}`
+	complexity, errs := complexityOf(t, query, options)
+	a.Equal(t, maxInt, complexity)
+	a.Equal(t, 1, len(errs))
+
+	// Multipliers outside of the Int range are rejected
+	_, errs = complexityOf(t, `{users(first: 4611686018427387904) {followers(first: 3) {name}}}`, options)
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "Int cannot represent non 32-bit signed integer value: 4611686018427387904", errs[0].Error())
+
+	options.Variables = `{"first": 4611686018427387904}`
+	_, errs = complexityOf(t, `query($first: Int) {users(first: $first) {followers(first: 3) {name}}}`, options)
+	a.Equal(t, 1, len(errs))
+
+	_, errs = complexityOf(t, `{ranked(limit: 4611686018427387904) {name}}`, ResolveOptions{MaxComplexity: 100})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "Int cannot represent non 32-bit signed integer value: 4611686018427387904", errs[0].Error())
+
+	_, errs = complexityOf(t, `{ranked(limit: 99999999999999999999999) {name}}`, ResolveOptions{MaxComplexity: 100})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "Int cannot represent non 32-bit signed integer value: 99999999999999999999999", errs[0].Error())
+}
+
+func TestComplexityInvalidCostTag(t *testing.T) {
+	err := NewSchema().Parse(struct {
+		Name string `gq:",cost=abc"`
+	}{}, M{}, nil)
+	a.Error(t, err)
+}
@@ -23,6 +23,11 @@ func (s *Schema) Copy() *Schema {
 		enums[idx] = *enum.copy()
 	}
 
+	scalars := make([]scalar, len(s.definedScalars))
+	for idx, scalar := range s.definedScalars {
+		scalars[idx] = *scalar.copy()
+	}
+
 	directives := map[DirectiveLocation][]*Directive{}
 	for key, value := range s.definedDirectives {
 		directivesToAdd := make([]*Directive, len(value))
@@ -39,13 +44,20 @@ func (s *Schema) Copy() *Schema {
 		inTypes:    *s.inTypes.copy(),
 		interfaces: *interfaces,
 
-		rootQuery:         s.rootQuery.copy(),
-		rootQueryValue:    s.rootQueryValue,
-		rootMethod:        s.rootMethod.copy(),
-		rootMethodValue:   s.rootMethodValue,
-		MaxDepth:          s.MaxDepth,
-		definedEnums:      enums,
-		definedDirectives: directives,
+		rootQuery:             s.rootQuery.copy(),
+		rootQueryValue:        s.rootQueryValue,
+		rootMethod:            s.rootMethod.copy(),
+		rootMethodValue:       s.rootMethodValue,
+		rootSubscriptionValue: s.rootSubscriptionValue,
+		MaxDepth:              s.MaxDepth,
+		definedEnums:          enums,
+		definedScalars:        scalars,
+		definedDirectives:     directives,
+		persistedQueries:      s.persistedQueries,
+		trustedDocuments:      s.trustedDocuments,
+		middlewares:           append([]Middleware{}, s.middlewares...),
+		tracer:                s.tracer,
+		usesJSONScalar:        s.usesJSONScalar,
 
 		Result:           make([]byte, len(s.Result)),
 		graphqlTypesMap:  nil,
@@ -53,6 +65,10 @@ func (s *Schema) Copy() *Schema {
 		graphqlObjFields: map[string][]qlField{},
 	}
 
+	if s.rootSubscription != nil {
+		res.rootSubscription = s.rootSubscription.copy()
+	}
+
 	res.ctx = s.ctx.copy(res)
 
 	return res
@@ -66,11 +82,12 @@ func (ctx *Ctx) copy(schema *Schema) *Ctx {
 		context:                  nil,
 		path:                     []byte{},
 		getFormFile:              ctx.getFormFile,
+		result:                   make([]byte, 0, cap(ctx.result)),
 		operatorHasArguments:     ctx.operatorHasArguments,
 		operatorArgumentsStartAt: ctx.operatorArgumentsStartAt,
+		tracer:                   ctx.tracer,
 		tracingEnabled:           ctx.tracingEnabled,
 		tracing:                  ctx.tracing,
-		prefRecordingStartTime:   ctx.prefRecordingStartTime,
 		rawVariables:             ctx.rawVariables,
 		variablesParsed:          false,
 		variablesJSONParser:      &fastjson.Parser{},
@@ -133,8 +150,12 @@ func (m *qlType) copy() *qlType {
 		*res.Name = *m.Name
 	}
 	if m.Description != nil {
-		res.Name = helpers.StrPtr("")
-		*res.Name = *m.Name
+		res.Description = helpers.StrPtr("")
+		*res.Description = *m.Description
+	}
+	if m.SpecifiedByURL != nil {
+		res.SpecifiedByURL = helpers.StrPtr("")
+		*res.SpecifiedByURL = *m.SpecifiedByURL
 	}
 	if m.Interfaces != nil {
 		res.Interfaces = make([]qlType, len(m.Interfaces))
@@ -174,16 +195,25 @@ func (o *obj) copy() *obj {
 		goTypeName:     o.goTypeName,
 		goPkgPath:      o.goPkgPath,
 		qlFieldName:    o.qlFieldName[:],
+		hidden:         o.hidden,
 		customObjValue: o.customObjValue, // maybe TODO
 		structFieldIdx: o.structFieldIdx,
+		embeddedPath:   o.embeddedPath,
 		dataValueType:  o.dataValueType,
 		isID:           o.isID,
 		enumTypeIndex:  o.enumTypeIndex,
+		scalarIndex:    o.scalarIndex,
+		isUnion:        o.isUnion,
+		description:    o.description,
+
+		deprecationReason: o.deprecationReason,
+		cost:              o.cost,
 	}
 
 	if o.innerContent != nil {
 		res.innerContent = o.innerContent.copy()
 	}
+	res.mapEntryType = o.mapEntryType
 
 	if o.method != nil {
 		res.method = o.method.copy()
@@ -207,12 +237,16 @@ func (o *obj) copy() *obj {
 
 func (m *objMethod) copy() *objMethod {
 	res := objMethod{
-		isTypeMethod:   m.isTypeMethod,
-		goFunctionName: m.goFunctionName,
-		goType:         m.goType,
-		checkedIns:     m.checkedIns,
-		outNr:          m.outNr,
-		outType:        *m.outType.copy(),
+		isTypeMethod:     m.isTypeMethod,
+		goFunctionName:   m.goFunctionName,
+		goType:           m.goType,
+		checkedIns:       m.checkedIns,
+		hasDefaultValues: m.hasDefaultValues,
+		outNr:            m.outNr,
+		outType:          *m.outType.copy(),
+		returnsChan:      m.returnsChan,
+		returnsThunk:     m.returnsThunk,
+		thunkHasError:    m.thunkHasError,
 	}
 	if m.errorOutNr != nil {
 		errOutNr := 0
@@ -261,18 +295,29 @@ func (m *input) copy() *input {
 	}
 
 	return &input{
-		kind:             m.kind,
-		isEnum:           m.isEnum,
-		enumTypeIndex:    m.enumTypeIndex,
-		isID:             m.isID,
-		isFile:           m.isFile,
-		isTime:           m.isTime,
-		goFieldIdx:       m.goFieldIdx,
-		gqFieldName:      m.gqFieldName,
-		elem:             elem,
-		isStructPointers: m.isStructPointers,
-		structName:       m.structName,
-		structContent:    structContent,
+		kind:          m.kind,
+		isEnum:        m.isEnum,
+		enumTypeIndex: m.enumTypeIndex,
+		isID:          m.isID,
+		isFile:        m.isFile,
+		isTime:        m.isTime,
+		isScalar:      m.isScalar,
+		scalarIndex:   m.scalarIndex,
+		isJSON:        m.isJSON,
+		goFieldIdx:    m.goFieldIdx,
+		gqFieldName:   m.gqFieldName,
+		description:   m.description,
+
+		goEmbeddedPath:    m.goEmbeddedPath,
+		deprecationReason: m.deprecationReason,
+		elem:              elem,
+		mapEntryType:      m.mapEntryType,
+		defaultValue:      m.defaultValue,
+		defaultBytecode:   m.defaultBytecode,
+		isStructPointers:  m.isStructPointers,
+		structName:        m.structName,
+		structContent:     structContent,
+		hasDefaultValues:  m.hasDefaultValues,
 	}
 }
 
@@ -0,0 +1,138 @@
+package yarql
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mjarkk/yarql/bytecode"
+)
+
+// Default values of arguments and input fields are defined using the default modifier of the gq tag, for example:
+//
+//   Limit int `gq:"limit,default=20"`
+//
+// The value is a graphql value that is parsed into bytecode when the schema is parsed,
+// if the argument or input field is omitted the bytecode is bound to the go value like a value within the query
+//
+// Like the graphql spec describes a argument or input field is omitted if it's not in the query or it's set to a variable without a value,
+// a explicit null is kept as null
+
+// pendingDefaultValue is a default value that is validated after all types are parsed
+type pendingDefaultValue struct {
+	goType    reflect.Type
+	structure input
+	fieldName string
+}
+
+// parseDefaultValue parses the default value of a argument or input field into bytecode
+func (c *parseCtx) parseDefaultValue(value string, goType reflect.Type, structure *input) error {
+	parser := bytecode.NewParserCtx()
+	parser.ParseValueToBytecode([]byte(value))
+	if len(parser.Errors) > 0 {
+		return fmt.Errorf("invalid default value %s, %s", value, parser.Errors[0].Error())
+	}
+
+	structure.defaultValue = &value
+	// Values are read until the NULL byte of the next instruction so a NULL byte is added after the value
+	structure.defaultBytecode = append(append([]byte{}, parser.Res...), 0)
+
+	c.defaultValues = append(c.defaultValues, pendingDefaultValue{
+		goType:    goType,
+		structure: *structure,
+		fieldName: structure.gqFieldName,
+	})
+	return nil
+}
+
+// checkDefaultValues validates the default values against the go type they will be bound to
+// This is done after all types are parsed as default values can contain input objects
+func (c *parseCtx) checkDefaultValues() error {
+	for _, pending := range c.defaultValues {
+		ctx := &Ctx{schema: c.schema}
+		goValue := reflect.New(pending.goType).Elem()
+		ctx.bindDefaultValue(&goValue, &pending.structure)
+		if len(ctx.query.Errors) > 0 {
+			return fmt.Errorf("invalid default value %s for %s, %s", *pending.structure.defaultValue, pending.fieldName, ctx.query.Errors[0].Error())
+		}
+	}
+	return nil
+}
+
+// defaultValueComplete returns false if the value contains a unclosed list, object or string
+func defaultValueComplete(value string) bool {
+	dept := 0
+	inString := false
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '[', '{':
+			dept++
+		case ']', '}':
+			dept--
+		}
+	}
+	return dept <= 0 && !inString
+}
+
+// bindDefaultValue binds the default value of valueStructure to goValue
+func (ctx *Ctx) bindDefaultValue(goValue *reflect.Value, valueStructure *input) (criticalErr bool) {
+	res := ctx.query.Res
+	charNr := ctx.charNr
+
+	ctx.query.Res = valueStructure.defaultBytecode
+	ctx.charNr = 1 // the value instruction starts after the first NULL byte
+	_, criticalErr = ctx.bindInputToGoValue(goValue, valueStructure, false)
+
+	ctx.query.Res = res
+	ctx.charNr = charNr
+	return criticalErr
+}
+
+// bindOmittedDefaultValues binds the default values of the fields within structure that are not in provided
+func (ctx *Ctx) bindOmittedDefaultValues(goValue *reflect.Value, structure *input, provided [][]byte) (criticalErr bool) {
+	for name, fieldStructure := range structure.structContent {
+		if fieldStructure.defaultValue == nil || containsName(provided, name) {
+			continue
+		}
+
+		field := embeddedInputField(*goValue, fieldStructure.goEmbeddedPath, fieldStructure.goFieldIdx)
+		if ctx.bindDefaultValue(&field, &fieldStructure) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOmittedVariable returns true if the value at the current position is a variable without a value
+// Variables without a value in the request but with a default value in the operation are not omitted
+func (ctx *Ctx) isOmittedVariable() bool {
+	res := ctx.query.Res
+	if res[ctx.charNr+1] != bytecode.ValueVariable {
+		return false
+	}
+	start := ctx.charNr + 6
+	end := start + int(ctx.readUint32(ctx.charNr+2))
+	name := b2s(res[start:end])
+
+	hasVariables, _ := ctx.parseVariables()
+	if hasVariables && ctx.variables.Get(name) != nil {
+		return false
+	}
+
+	originalCharNr := ctx.charNr
+	hasDefault := ctx.findOperatorArgumentDefault(name)
+	ctx.charNr = originalCharNr
+	return !hasDefault
+}
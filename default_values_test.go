@@ -0,0 +1,175 @@
+package yarql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+type TestDefaultValuesStatus string
+
+type TestDefaultValuesFilter struct {
+	Name   string `gq:"name,default=\"all\""`
+	Offset int    `gq:"offset,default=5"`
+}
+
+type TestDefaultValuesData struct{}
+
+func (TestDefaultValuesData) ResolveList(args struct {
+	Limit  int                     `gq:"limit,default=20"`
+	Tags   []string                `gq:"tags,default=[\"a\", \"b,c\"]"`
+	Status TestDefaultValuesStatus `gq:"status,default=ACTIVE"`
+	Filter *TestDefaultValuesFilter
+}) string {
+	filter := "nil"
+	if args.Filter != nil {
+		filter = fmt.Sprintf("%s/%d", args.Filter.Name, args.Filter.Offset)
+	}
+	return fmt.Sprintf("%d %s %s %s", args.Limit, strings.Join(args.Tags, "|"), args.Status, filter)
+}
+
+func (TestDefaultValuesData) ResolveNested(args struct {
+	Filter TestDefaultValuesFilter `gq:"filter,default={offset: 2}"`
+}) string {
+	return fmt.Sprintf("%s/%d", args.Filter.Name, args.Filter.Offset)
+}
+
+type TestDefaultValuesPageOptions struct {
+	Size *int `gq:"size,default=10"`
+}
+
+func (TestDefaultValuesData) ResolvePage(args struct {
+	Number  *int `gq:"number,default=1"`
+	Options *TestDefaultValuesPageOptions
+}) string {
+	format := func(value *int) string {
+		if value == nil {
+			return "null"
+		}
+		return fmt.Sprint(*value)
+	}
+	size := "nil"
+	if args.Options != nil {
+		size = format(args.Options.Size)
+	}
+	return format(args.Number) + " " + size
+}
+
+func TestDefaultValues(t *testing.T) {
+	testCases := []struct {
+		query     string
+		variables string
+		expected  string
+	}{
+		{`{list}`, ``, `{"list":"20 a|b,c active nil"}`},
+		{`{list(limit: 1, tags: [], status: INACTIVE)}`, ``, `{"list":"1  inactive nil"}`},
+		{`{list(filter: {offset: 1})}`, ``, `{"list":"20 a|b,c active all/1"}`},
+		{`query($filter: TestDefaultValuesFilter) {list(filter: $filter)}`, `{"filter": {"name": "foo"}}`, `{"list":"20 a|b,c active foo/5"}`},
+		{`query($limit: Int) {list(limit: $limit)}`, `{"limit": 3}`, `{"list":"3 a|b,c active nil"}`},
+		{`query($limit: Int) {list(limit: $limit)}`, ``, `{"list":"20 a|b,c active nil"}`},
+		{`query($offset: Int) {list(filter: {offset: $offset})}`, ``, `{"list":"20 a|b,c active all/5"}`},
+		{`query($filter: TestDefaultValuesFilter) {list(filter: $filter)}`, `{"filter": {"offset": 1}}`, `{"list":"20 a|b,c active all/1"}`},
+
+		// Explicit nulls are kept, only omitted values and variables without a value get the default value
+		{`{page}`, ``, `{"page":"1 nil"}`},
+		{`{page(number: null, options: {size: null})}`, ``, `{"page":"null null"}`},
+		{`{page(options: {})}`, ``, `{"page":"1 10"}`},
+		{`query($number: Int, $size: Int) {page(number: $number, options: {size: $size})}`, ``, `{"page":"1 10"}`},
+		{`query($number: Int, $size: Int) {page(number: $number, options: {size: $size})}`, `{"number": null, "size": null}`, `{"page":"null null"}`},
+		{`query($number: Int = 3, $size: Int = 4) {page(number: $number, options: {size: $size})}`, ``, `{"page":"3 4"}`},
+		{`query($number: Int = 3) {page(number: $number)}`, `{"number": null}`, `{"page":"null nil"}`},
+		{`query($options: TestDefaultValuesPageOptions) {page(options: $options)}`, `{"options": {"size": null}}`, `{"page":"1 null"}`},
+		{`query($options: TestDefaultValuesPageOptions) {page(options: $options)}`, `{"options": {}}`, `{"page":"1 10"}`},
+		{`{nested}`, ``, `{"nested":"all/2"}`},
+		{`{nested(filter: {})}`, ``, `{"nested":"all/5"}`},
+	}
+
+	for _, testCase := range testCases {
+		s := NewSchema()
+		_, err := s.RegisterEnum(map[string]TestDefaultValuesStatus{"ACTIVE": "active", "INACTIVE": "inactive"})
+		a.NoError(t, err)
+
+		res, errs := bytecodeParse(t, s, testCase.query, TestDefaultValuesData{}, M{}, ResolveOptions{
+			NoMeta:    true,
+			Variables: testCase.variables,
+		})
+		for _, err := range errs {
+			a.NoError(t, err, testCase.query)
+		}
+		a.Equal(t, testCase.expected, res, testCase.query)
+	}
+}
+
+func TestDefaultValuesSchema(t *testing.T) {
+	s := NewSchema()
+	_, err := s.RegisterEnum(map[string]TestDefaultValuesStatus{"ACTIVE": "active", "INACTIVE": "inactive"})
+	a.NoError(t, err)
+	err = s.Parse(TestDefaultValuesData{}, M{}, nil)
+	a.NoError(t, err)
+
+	sdl, err := s.SDL()
+	a.NoError(t, err)
+	a.True(t, strings.Contains(sdl, `list(filter: TestDefaultValuesFilter, limit: Int! = 20, status: TestDefaultValuesStatus! = ACTIVE, tags: [String!] = ["a", "b,c"]): String!`), sdl)
+	a.True(t, strings.Contains(sdl, `nested(filter: TestDefaultValuesFilter! = {offset: 2}): String!`), sdl)
+	a.True(t, strings.Contains(sdl, "input TestDefaultValuesFilter {\n  name: String! = \"all\"\n  offset: Int! = 5\n}\n"), sdl)
+
+	s = NewSchema()
+	_, err = s.RegisterEnum(map[string]TestDefaultValuesStatus{"ACTIVE": "active", "INACTIVE": "inactive"})
+	a.NoError(t, err)
+
+	res, errs := bytecodeParse(t, s, `{__type(name: "TestDefaultValuesFilter") {inputFields {name defaultValue}}}`, TestDefaultValuesData{}, M{})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"__type":{"inputFields":[{"name":"name","defaultValue":"\"all\""},{"name":"offset","defaultValue":"5"}]}}`, res)
+}
+
+func TestDefaultValuesInvalid(t *testing.T) {
+	type Query struct{}
+
+	testCases := []struct {
+		name     string
+		methods  interface{}
+		expected string
+	}{
+		{"wrong type", struct {
+			ResolveA func(struct {
+				Limit int `gq:"limit,default=\"foo\""`
+			}) string
+		}{}, `invalid default value "foo" for limit, cannot assign string to <int Value>`},
+		{"unknown enum value", struct {
+			ResolveA func(struct {
+				Status TestDefaultValuesStatus `gq:"status,default=UNKNOWN"`
+			}) string
+		}{}, `invalid default value UNKNOWN for status, unknown enum value UNKNOWN for enum TestDefaultValuesStatus`},
+		{"variable", struct {
+			ResolveA func(struct {
+				Limit int `gq:"limit,default=$limit"`
+			}) string
+		}{}, `invalid default value $limit, variables are not allowed in constant values, struct field: Limit`},
+		{"unclosed list", struct {
+			ResolveA func(struct {
+				Tags []string `gq:"tags,default=[\"a\""`
+			}) string
+		}{}, `invalid default value ["a", unexpected EOF, struct field: Tags`},
+		{"empty", struct {
+			ResolveA func(struct {
+				Limit int `gq:"limit,default="`
+			}) string
+		}{}, `invalid field tag gq default, expected a value: default=, struct field: Limit`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			s := NewSchema()
+			_, err := s.RegisterEnum(map[string]TestDefaultValuesStatus{"ACTIVE": "active", "INACTIVE": "inactive"})
+			a.NoError(t, err)
+
+			err = s.Parse(Query{}, testCase.methods, nil)
+			a.Error(t, err)
+			if err != nil {
+				a.True(t, strings.HasPrefix(err.Error(), testCase.expected), err.Error())
+			}
+		})
+	}
+}
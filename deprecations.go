@@ -0,0 +1,92 @@
+package yarql
+
+import "reflect"
+
+const defaultDeprecationReason = "No longer supported"
+
+// Deprecator can be implemented by structs to mark fields and arguments of the type as deprecated
+// The keys of the returned map are the graphql names of the fields and the values are the deprecation reasons
+// An empty reason results in the default reason "No longer supported"
+//
+// Example:
+//
+//	func (User) Deprecations() map[string]string {
+//		return map[string]string{
+//			"fullName": "Use name instead",
+//		}
+//	}
+//
+// Struct fields can also be deprecated using the gq struct tag, these take precedence over the deprecations returned by Deprecations
+// The reason can contain commas and continues until the next modifier of the tag like default= or cost=
+//
+//	type User struct {
+//		FullName string `gq:",deprecated=Use name instead"`
+//	}
+type Deprecator interface {
+	Deprecations() map[string]string
+}
+
+var deprecatorType = reflect.TypeOf((*Deprecator)(nil)).Elem()
+
+// getDeprecations returns the deprecations of a struct type that implements Deprecator
+func getDeprecations(t reflect.Type) map[string]string {
+	if t.Kind() != reflect.Struct || !reflect.PtrTo(t).Implements(deprecatorType) {
+		return nil
+	}
+	return reflect.New(t).Interface().(Deprecator).Deprecations()
+}
+
+// deprecationReason returns the deprecation reason of a field or nil if the field is not deprecated
+func deprecationReason(deprecations map[string]string, qlFieldName string) *string {
+	reason, ok := deprecations[qlFieldName]
+	if !ok {
+		return nil
+	}
+	if len(reason) == 0 {
+		reason = defaultDeprecationReason
+	}
+	return &reason
+}
+
+func includeDeprecated(args isDeprecatedArgs) bool {
+	return args.IncludeDeprecated != nil && *args.IncludeDeprecated
+}
+
+func filterDeprecatedFields(fields []qlField, args isDeprecatedArgs) []qlField {
+	if includeDeprecated(args) {
+		return fields
+	}
+	res := make([]qlField, 0, len(fields))
+	for _, field := range fields {
+		if !field.IsDeprecated {
+			res = append(res, field)
+		}
+	}
+	return res
+}
+
+func filterDeprecatedInputValues(values []qlInputValue, args isDeprecatedArgs) []qlInputValue {
+	if includeDeprecated(args) {
+		return values
+	}
+	res := make([]qlInputValue, 0, len(values))
+	for _, value := range values {
+		if !value.IsDeprecated {
+			res = append(res, value)
+		}
+	}
+	return res
+}
+
+func filterDeprecatedEnumValues(values []qlEnumValue, args isDeprecatedArgs) []qlEnumValue {
+	if includeDeprecated(args) {
+		return values
+	}
+	res := make([]qlEnumValue, 0, len(values))
+	for _, value := range values {
+		if !value.IsDeprecated {
+			res = append(res, value)
+		}
+	}
+	return res
+}
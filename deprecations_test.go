@@ -0,0 +1,165 @@
+package yarql
+
+import (
+	"reflect"
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+type TestDeprecationsData struct {
+	Name     string
+	FullName string `gq:",deprecated=Use name instead, it's shorter"`
+	Old      string `gq:",deprecated"`
+	Enum     TestDeprecationsEnum
+}
+
+func (TestDeprecationsData) Deprecations() map[string]string {
+	return map[string]string{
+		"greet": "Use hello instead",
+	}
+}
+
+func (TestDeprecationsData) ResolveGreet(args TestDeprecationsArgs) string {
+	return "hello " + args.Name
+}
+
+type TestDeprecationsArgs struct {
+	Name   string
+	Prefix *string `gq:",deprecated=Unused"`
+}
+
+type TestDeprecationsEnum string
+
+type TestDeprecationsRequiredArgs struct{}
+
+func (TestDeprecationsRequiredArgs) ResolveFoo(args struct {
+	Bar string `gq:",deprecated"`
+}) string {
+	return ""
+}
+
+func TestDeprecationsFail(t *testing.T) {
+	err := NewSchema().Parse(TestDeprecationsRequiredArgs{}, M{}, nil)
+	a.Error(t, err, "required arguments cannot be deprecated")
+
+	_, err = registerEnumCheck(map[string]TestDeprecationsEnum{"A": "a"}, EnumOptions{
+		DeprecatedValues: map[string]string{"C": ""},
+	})
+	a.Error(t, err)
+}
+
+func TestDeprecationsTagModifiers(t *testing.T) {
+	type Tags struct {
+		WithDefault string `gq:"limit,deprecated=old, use size,default=20"`
+		WithCost    string `gq:",deprecated=x,cost=5"`
+		WithID      string `gq:",deprecated,id"`
+	}
+	tagsType := reflect.TypeOf(Tags{})
+
+	field := tagsType.Field(0)
+	tag, err := parseFieldTagGQ(&field)
+	a.NoError(t, err)
+	a.Equal(t, "old, use size", *tag.deprecationReason)
+	a.Equal(t, "20", *tag.defaultValue)
+
+	field = tagsType.Field(1)
+	tag, err = parseFieldTagGQ(&field)
+	a.NoError(t, err)
+	a.Equal(t, "x", *tag.deprecationReason)
+	a.Equal(t, 5, *tag.cost)
+
+	field = tagsType.Field(2)
+	tag, err = parseFieldTagGQ(&field)
+	a.NoError(t, err)
+	a.Equal(t, defaultDeprecationReason, *tag.deprecationReason)
+	a.True(t, tag.isID)
+}
+
+func TestDeprecationsResolve(t *testing.T) {
+	s := NewSchema()
+	_, err := s.RegisterEnum(map[string]TestDeprecationsEnum{"A": "a", "B": "b"}, EnumOptions{
+		DeprecatedValues: map[string]string{"B": ""},
+	})
+	a.NoError(t, err)
+
+	res, errs := bytecodeParse(t, s, `{fullName greet(name: "foo", prefix: "bar")}`, TestDeprecationsData{FullName: "foo"}, M{})
+	for _, err := range errs {
+		panic(err.Error())
+	}
+	a.Equal(t, `{"fullName":"foo","greet":"hello foo"}`, res)
+
+	s = NewSchema()
+	_, err = s.RegisterEnum(map[string]TestDeprecationsEnum{"A": "a", "B": "b"}, EnumOptions{
+		DeprecatedValues: map[string]string{"B": ""},
+	})
+	a.NoError(t, err)
+
+	_, errs = bytecodeParse(t, s, `{name @deprecated}`, TestDeprecationsData{}, M{})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, `Directive "@deprecated" may not be used on FIELD.`, errs[0].Error())
+}
+
+func TestDeprecationsIntrospection(t *testing.T) {
+	query := `{
+		data: __type(name: "TestDeprecationsData") {
+			active: fields {
+				name
+				args {name}
+			}
+			all: fields(includeDeprecated: true) {
+				name
+				isDeprecated
+				deprecationReason
+				args(includeDeprecated: true) {name isDeprecated deprecationReason}
+			}
+		}
+		enum: __type(name: "TestDeprecationsEnum") {
+			active: enumValues {name}
+			all: enumValues(includeDeprecated: true) {name isDeprecated deprecationReason}
+		}
+	}`
+
+	s := NewSchema()
+	_, err := s.RegisterEnum(map[string]TestDeprecationsEnum{"A": "a", "B": "b"}, EnumOptions{
+		DeprecatedValues: map[string]string{"B": ""},
+	})
+	a.NoError(t, err)
+
+	res, errs := bytecodeParse(t, s, query, TestDeprecationsData{}, M{})
+	for _, err := range errs {
+		panic(err.Error())
+	}
+
+	expect := `{` +
+		`"data":{` +
+		`"active":[{"name":"enum","args":[]},{"name":"name","args":[]}],` +
+		`"all":[` +
+		`{"name":"enum","isDeprecated":false,"deprecationReason":null,"args":[]},` +
+		`{"name":"fullName","isDeprecated":true,"deprecationReason":"Use name instead, it's shorter","args":[]},` +
+		`{"name":"greet","isDeprecated":true,"deprecationReason":"Use hello instead","args":[` +
+		`{"name":"name","isDeprecated":false,"deprecationReason":null},` +
+		`{"name":"prefix","isDeprecated":true,"deprecationReason":"Unused"}` +
+		`]},` +
+		`{"name":"name","isDeprecated":false,"deprecationReason":null,"args":[]},` +
+		`{"name":"old","isDeprecated":true,"deprecationReason":"No longer supported","args":[]}` +
+		`]},` +
+		`"enum":{` +
+		`"active":[{"name":"A"}],` +
+		`"all":[{"name":"A","isDeprecated":false,"deprecationReason":null},{"name":"B","isDeprecated":true,"deprecationReason":"No longer supported"}]` +
+		`}` +
+		`}`
+	a.Equal(t, expect, res)
+}
+
+func TestDeprecatedDirectiveIntrospection(t *testing.T) {
+	res, errs := bytecodeParse(t, NewSchema(), `{__schema {directives {name locations args {name}}}}`, TestResolveEmptyQueryDataQ{}, M{})
+	for _, err := range errs {
+		panic(err.Error())
+	}
+	a.Equal(t, `{"__schema":{"directives":[`+
+		`{"name":"deprecated","locations":["FIELD_DEFINITION","ARGUMENT_DEFINITION","INPUT_FIELD_DEFINITION","ENUM_VALUE"],"args":[{"name":"reason"}]},`+
+		`{"name":"include","locations":["FIELD","FRAGMENT_SPREAD","INLINE_FRAGMENT"],"args":[{"name":"if"}]},`+
+		`{"name":"skip","locations":["FIELD","FRAGMENT_SPREAD","INLINE_FRAGMENT"],"args":[{"name":"if"}]}`+
+		`]}}`, res)
+}
@@ -0,0 +1,56 @@
+package yarql
+
+import (
+	"reflect"
+
+	h "github.com/mjarkk/yarql/helpers"
+)
+
+// Describer can be implemented by structs to add descriptions to the type, it's fields and arguments
+// The keys of the returned map are the graphql names of the fields and the empty key ("") describes the type itself
+//
+// Example:
+//
+//	func (User) Describe() map[string]string {
+//		return map[string]string{
+//			"":      "A user of the application",
+//			"name":  "The full name of the user",
+//			"posts": "The posts written by the user",
+//		}
+//	}
+//
+// Descriptions can also be set using the gqdesc struct tag, these take precedence over the descriptions returned by Describe
+//
+//	type User struct {
+//		Name string `gqdesc:"The full name of the user"`
+//	}
+type Describer interface {
+	Describe() map[string]string
+}
+
+var describerType = reflect.TypeOf((*Describer)(nil)).Elem()
+
+// getDescriptions returns the descriptions of a struct type that implements Describer
+func getDescriptions(t reflect.Type) map[string]string {
+	if t.Kind() != reflect.Struct || !reflect.PtrTo(t).Implements(describerType) {
+		return nil
+	}
+	return reflect.New(t).Interface().(Describer).Describe()
+}
+
+// fieldDescription returns the description of a struct field
+func fieldDescription(field *reflect.StructField, qlFieldName string, descriptions map[string]string) string {
+	description, ok := field.Tag.Lookup("gqdesc")
+	if ok {
+		return description
+	}
+	return descriptions[qlFieldName]
+}
+
+// qlDescription converts a description into the value used by the introspection
+func qlDescription(description string) *string {
+	if len(description) == 0 {
+		return h.PtrToEmptyStr
+	}
+	return h.StrPtr(description)
+}
@@ -0,0 +1,93 @@
+package yarql
+
+import (
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+type TestDescriptionsData struct {
+	Name  string `gqdesc:"The name of the data"`
+	Other string
+	Enum  TestDescriptionsEnum
+}
+
+func (TestDescriptionsData) Describe() map[string]string {
+	return map[string]string{
+		"":      "Data with descriptions",
+		"name":  "Overwritten by the struct tag",
+		"other": "Another field",
+		"greet": "Greets someone",
+	}
+}
+
+func (TestDescriptionsData) ResolveGreet(args TestDescriptionsArgs) string {
+	return "hello " + args.Name
+}
+
+type TestDescriptionsArgs struct {
+	Name   string `gqdesc:"The name to greet"`
+	Filter *TestDescriptionsFilter
+}
+
+type TestDescriptionsFilter struct {
+	Limit int `gqdesc:"The max amount of results"`
+}
+
+func (*TestDescriptionsFilter) Describe() map[string]string {
+	return map[string]string{"": "A filter"}
+}
+
+type TestDescriptionsEnum string
+
+func TestRegisterEnumDescriptionsFail(t *testing.T) {
+	_, err := registerEnumCheck(map[string]TestDescriptionsEnum{"A": "a"}, EnumOptions{
+		ValueDescriptions: map[string]string{"C": "Unknown"},
+	})
+	a.Error(t, err)
+}
+
+func TestDescriptions(t *testing.T) {
+	query := `{
+		data: __type(name: "TestDescriptionsData") {
+			description
+			fields {
+				name
+				description
+				args {name description}
+			}
+		}
+		filter: __type(name: "TestDescriptionsFilter") {
+			description
+			inputFields {name description}
+		}
+		enum: __type(name: "TestDescriptionsEnum") {
+			description
+			enumValues {name description}
+		}
+	}`
+
+	s := NewSchema()
+	_, err := s.RegisterEnum(map[string]TestDescriptionsEnum{"A": "a", "B": "b"}, EnumOptions{
+		Description:       "A enum",
+		ValueDescriptions: map[string]string{"A": "The first letter"},
+	})
+	a.NoError(t, err)
+
+	res, errs := bytecodeParse(t, s, query, TestDescriptionsData{}, M{}, ResolveOptions{NoMeta: true})
+	for _, err := range errs {
+		panic(err.Error())
+	}
+
+	expect := `{` +
+		`"data":{"description":"Data with descriptions","fields":[` +
+		`{"name":"enum","description":"","args":[]},` +
+		`{"name":"greet","description":"Greets someone","args":[{"name":"filter","description":""},{"name":"name","description":"The name to greet"}]},` +
+		`{"name":"name","description":"The name of the data","args":[]},` +
+		`{"name":"other","description":"Another field","args":[]}` +
+		`]},` +
+		`"filter":{"description":"A filter","inputFields":[{"name":"limit","description":"The max amount of results"}]},` +
+		`"enum":{"description":"A enum","enumValues":[{"name":"A","description":"The first letter"},{"name":"B","description":""}]}` +
+		`}`
+	a.Equal(t, expect, res)
+}
@@ -15,6 +15,21 @@ const (
 	DirectiveLocationFragment
 	// DirectiveLocationFragmentInline can be called from a inline fragment
 	DirectiveLocationFragmentInline
+	// DirectiveLocationFieldDefinition can be used on a field in the schema
+	// Directives on schema locations are only shown in the introspection, their method is never called
+	DirectiveLocationFieldDefinition
+	// DirectiveLocationArgumentDefinition can be used on a argument in the schema
+	DirectiveLocationArgumentDefinition
+	// DirectiveLocationInputFieldDefinition can be used on a input object field in the schema
+	DirectiveLocationInputFieldDefinition
+	// DirectiveLocationEnumValue can be used on a enum value in the schema
+	DirectiveLocationEnumValue
+	// DirectiveLocationQuery can be called from a query operation
+	DirectiveLocationQuery
+	// DirectiveLocationMutation can be called from a mutation operation
+	DirectiveLocationMutation
+	// DirectiveLocationSubscription can be called from a subscription operation
+	DirectiveLocationSubscription
 )
 
 // String returns the DirectiveLocation as a string
@@ -26,6 +41,20 @@ func (l DirectiveLocation) String() string {
 		return "<DirectiveLocationFragment>"
 	case DirectiveLocationFragmentInline:
 		return "<DirectiveLocationFragmentInline>"
+	case DirectiveLocationFieldDefinition:
+		return "<DirectiveLocationFieldDefinition>"
+	case DirectiveLocationArgumentDefinition:
+		return "<DirectiveLocationArgumentDefinition>"
+	case DirectiveLocationInputFieldDefinition:
+		return "<DirectiveLocationInputFieldDefinition>"
+	case DirectiveLocationEnumValue:
+		return "<DirectiveLocationEnumValue>"
+	case DirectiveLocationQuery:
+		return "<DirectiveLocationQuery>"
+	case DirectiveLocationMutation:
+		return "<DirectiveLocationMutation>"
+	case DirectiveLocationSubscription:
+		return "<DirectiveLocationSubscription>"
 	default:
 		return "<UNKNOWN DIRECTIVE LOCATION>"
 	}
@@ -40,6 +69,20 @@ func (l DirectiveLocation) ToQlDirectiveLocation() __DirectiveLocation {
 		return directiveLocationFragmentSpread
 	case DirectiveLocationFragmentInline:
 		return directiveLocationInlineFragment
+	case DirectiveLocationFieldDefinition:
+		return directiveLocationFieldDefinition
+	case DirectiveLocationArgumentDefinition:
+		return directiveLocationArgumentDefinition
+	case DirectiveLocationInputFieldDefinition:
+		return directiveLocationInputFieldDefinition
+	case DirectiveLocationEnumValue:
+		return directiveLocationEnumValue
+	case DirectiveLocationQuery:
+		return directiveLocationQuery
+	case DirectiveLocationMutation:
+		return directiveLocationMutation
+	case DirectiveLocationSubscription:
+		return directiveLocationSubscription
 	default:
 		return directiveLocationField
 	}
@@ -59,19 +102,25 @@ type Directive struct {
 	Description string
 }
 
-// TODO
-// type ModifyOnWriteContent func(bytes []byte) []byte
+// ModifyOnWriteContent modifies the JSON output of a field
+// value is the go value of the field, for methods this is the value returned by the method
+// Fields that are null or returned a error are not modified
+type ModifyOnWriteContent func(bytes []byte, value interface{}) []byte
 
 // DirectiveModifier defines modifications to the response
 // Nothing is this struct is required and will be ignored if not set
 type DirectiveModifier struct {
-	// Skip field/(inline)fragment
+	// Skip field/(inline)fragment or on a operation directive the whole operation
 	Skip bool
 
-	// TODO make this
+	// Values are set on the ctx before the operation is resolved, see (*Ctx).GetValue
+	// Only used by operation directives
+	Values map[string]interface{}
+
 	// ModifyOnWriteContent allows you to modify field JSON response data before it's written to the result
 	// Note that there is no checking for validation here it's up to you to return valid json
-	// ModifyOnWriteContent ModifyOnWriteContent
+	// Only used by field directives
+	ModifyOnWriteContent ModifyOnWriteContent
 }
 
 // RegisterDirective registers a new directive
@@ -160,3 +209,42 @@ func checkDirective(directive *Directive) error {
 
 	return nil
 }
+
+// resolveModifiedFieldDataValue resolves a field value and passes the written JSON through the content modifiers
+// The field is never deferred or resolved in parallel as the modifiers need the written content
+func (ctx *Ctx) resolveModifiedFieldDataValue(typeObj *obj, info *FieldInfo, dept uint8, hasSubSelection bool, modifiers []ModifyOnWriteContent) bool {
+	valueStart := len(ctx.result)
+	errorsLen := len(ctx.query.Errors)
+
+	wasDeferring := ctx.deferring
+	ctx.deferring = false
+	criticalErr := ctx.resolveFieldValue(typeObj, info, dept, hasSubSelection)
+	ctx.deferring = wasDeferring
+	if criticalErr {
+		return criticalErr
+	}
+	if len(ctx.query.Errors) > errorsLen || string(ctx.result[valueStart:]) == "null" {
+		// The modifiers are not called for fields that are null or errored so they cannot hide the error
+		return false
+	}
+
+	// Methods and pointers replace the current go value with the resolved value,
+	// a method that was not called leaves the bound method behind which is not passed to the modifiers
+	var value interface{}
+	goValue := ctx.getGoValue()
+	if goValue.IsValid() && goValue.CanInterface() && goValue.Kind() != reflect.Func {
+		value = goValue.Interface()
+	}
+
+	ctx.modifyWrittenContent(valueStart, value, modifiers)
+	return false
+}
+
+// modifyWrittenContent replaces the result written since valueStart with the output of the content modifiers
+func (ctx *Ctx) modifyWrittenContent(valueStart int, value interface{}, modifiers []ModifyOnWriteContent) {
+	content := append([]byte{}, ctx.result[valueStart:]...)
+	for _, modify := range modifiers {
+		content = modify(content, value)
+	}
+	ctx.result = append(ctx.result[:valueStart], content...)
+}
@@ -0,0 +1,212 @@
+package yarql
+
+import (
+	"reflect"
+)
+
+// Fields of embedded structs are promoted to the outer struct like go does,
+// methods are already promoted by go itself so these are part of the method set of the outer struct
+//
+// Promoted fields are found breadth first so fields of the outer struct take precedence over fields of embedded structs
+// Like go and encoding/json fields with the same name at the same depth are ambiguous and are not promoted
+
+// structField is a field of a struct or a field promoted from one of it's embedded structs
+type structField struct {
+	reflect.StructField
+	idx          int   // the index of the field within the struct that defines it
+	embeddedPath []int // the field indexes of the embedded structs that lead to the struct that defines this field
+	depth        int   // 0 for fields of the outer struct
+
+	// The descriptions, deprecations and costs of the struct that defines the field merged with these of the outer structs
+	descriptions map[string]string
+	deprecations map[string]string
+	costs        map[string]int
+}
+
+// embeddedStructType returns the struct type of a embedded field that should be promoted, nil if the field is not promoted
+// Inputs don't promote embedded pointers to unexported structs as reflect cannot allocate these
+func embeddedStructType(field *reflect.StructField, isInput bool) reflect.Type {
+	if !field.Anonymous {
+		return nil
+	}
+	tag, err := parseFieldTagGQ(field)
+	if err != nil || tag.ignore || tag.name != nil {
+		// Tagged embedded structs are handled as normal fields
+		return nil
+	}
+
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		if isInput && len(field.PkgPath) > 0 {
+			return nil
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// structFields returns the fields of t including the fields promoted from embedded structs
+func structFields(t reflect.Type, isInput bool) []structField {
+	type embeddedStruct struct {
+		t            reflect.Type
+		embeddedPath []int
+		descriptions map[string]string
+		deprecations map[string]string
+		costs        map[string]int
+	}
+
+	res := []structField{}
+	visited := map[reflect.Type]bool{t: true}
+	queue := []embeddedStruct{{
+		t:            t,
+		descriptions: getDescriptions(t),
+		deprecations: getDeprecations(t),
+		costs:        getCosts(t),
+	}}
+
+	for depth := 0; len(queue) > 0; depth++ {
+		next := []embeddedStruct{}
+		for _, s := range queue {
+			for i := 0; i < s.t.NumField(); i++ {
+				field := s.t.Field(i)
+
+				embeddedType := embeddedStructType(&field, isInput)
+				if embeddedType != nil {
+					if visited[embeddedType] {
+						continue
+					}
+					visited[embeddedType] = true
+
+					next = append(next, embeddedStruct{
+						t:            embeddedType,
+						embeddedPath: append(append([]int{}, s.embeddedPath...), i),
+						descriptions: mergeStringMaps(getDescriptions(embeddedType), s.descriptions),
+						deprecations: mergeStringMaps(getDeprecations(embeddedType), s.deprecations),
+						costs:        mergeCostMaps(getCosts(embeddedType), s.costs),
+					})
+					continue
+				}
+
+				res = append(res, structField{
+					StructField:  field,
+					idx:          i,
+					embeddedPath: s.embeddedPath,
+					depth:        depth,
+					descriptions: s.descriptions,
+					deprecations: s.deprecations,
+					costs:        s.costs,
+				})
+			}
+		}
+		queue = next
+	}
+
+	return withoutAmbiguousFields(res)
+}
+
+// withoutAmbiguousFields removes the promoted fields that share their graphql name with another field at the same depth
+func withoutAmbiguousFields(fields []structField) []structField {
+	type nameUsage struct {
+		depth int // the lowest depth the name is used at
+		count int // the amount of fields using the name at depth
+	}
+
+	usages := map[string]nameUsage{}
+	for _, field := range fields {
+		tag, err := parseFieldTagGQ(&field.StructField)
+		if len(field.PkgPath) > 0 || err != nil || tag.ignore {
+			// These fields are not part of the schema
+			continue
+		}
+
+		name := structFieldQLName(&field.StructField)
+		usage, ok := usages[name]
+		if !ok || field.depth < usage.depth {
+			usages[name] = nameUsage{depth: field.depth, count: 1}
+		} else if field.depth == usage.depth {
+			usage.count++
+			usages[name] = usage
+		}
+	}
+
+	res := fields[:0]
+	for _, field := range fields {
+		if field.depth > 0 && usages[structFieldQLName(&field.StructField)].count > 1 {
+			continue
+		}
+		res = append(res, field)
+	}
+	return res
+}
+
+// mergeStringMaps returns a map with the values of a overwritten by these of b
+func mergeStringMaps(a, b map[string]string) map[string]string {
+	if len(b) == 0 {
+		return a
+	}
+	res := map[string]string{}
+	for key, value := range a {
+		res[key] = value
+	}
+	for key, value := range b {
+		res[key] = value
+	}
+	return res
+}
+
+// mergeCostMaps returns a map with the values of a overwritten by these of b
+func mergeCostMaps(a, b map[string]int) map[string]int {
+	if len(b) == 0 {
+		return a
+	}
+	res := map[string]int{}
+	for key, value := range a {
+		res[key] = value
+	}
+	for key, value := range b {
+		res[key] = value
+	}
+	return res
+}
+
+// embeddedStructValue returns the embedded struct at path within goValue
+// Returns false if one of the embedded structs is a nil pointer
+func embeddedStructValue(goValue reflect.Value, path []int) (reflect.Value, bool) {
+	for _, idx := range path {
+		goValue = goValue.Field(idx)
+		if goValue.Kind() == reflect.Ptr {
+			if goValue.IsNil() {
+				return goValue, false
+			}
+			goValue = goValue.Elem()
+		}
+	}
+	return goValue, true
+}
+
+// embeddedInputField returns the field at idx within the embedded struct at path
+// Embedded struct pointers that are nil are allocated
+func embeddedInputField(goValue reflect.Value, path []int, idx int) reflect.Value {
+	for _, embeddedIdx := range path {
+		goValue = goValue.Field(embeddedIdx)
+		if goValue.Kind() == reflect.Ptr {
+			if goValue.IsNil() {
+				goValue.Set(reflect.New(goValue.Type().Elem()))
+			}
+			goValue = goValue.Elem()
+		}
+	}
+	return goValue.Field(idx)
+}
+
+// structFieldQLName returns the graphql name of a struct field
+func structFieldQLName(field *reflect.StructField) string {
+	tag, _ := parseFieldTagGQ(field)
+	if tag.name != nil {
+		return *tag.name
+	}
+	return formatGoNameToQL(field.Name)
+}
@@ -0,0 +1,211 @@
+package yarql
+
+import (
+	"strings"
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+type TestEmbeddedBase struct {
+	ID        uint `gq:"id,id"`
+	CreatedAt string
+	Name      string
+}
+
+func (b TestEmbeddedBase) ResolveLabel() string {
+	return "base " + b.Name
+}
+
+type TestEmbeddedAudit struct {
+	UpdatedBy string
+}
+
+type TestEmbeddedEntity struct {
+	TestEmbeddedBase
+	*TestEmbeddedAudit
+	Name string
+}
+
+type TestEmbeddedData struct {
+	Entity      TestEmbeddedEntity
+	NilEmbedded *TestEmbeddedEntity
+}
+
+type TestEmbeddedFilter struct {
+	Limit int
+}
+
+type TestEmbeddedMethods struct{}
+
+func (TestEmbeddedMethods) ResolveCreate(args struct {
+	TestEmbeddedFilter
+	Entity TestEmbeddedEntity
+}) TestEmbeddedEntity {
+	args.Entity.CreatedAt = strings.Repeat("x", args.Limit)
+	return args.Entity
+}
+
+func TestEmbeddedOutput(t *testing.T) {
+	data := TestEmbeddedData{
+		Entity: TestEmbeddedEntity{
+			TestEmbeddedBase:  TestEmbeddedBase{ID: 1, CreatedAt: "today", Name: "base"},
+			TestEmbeddedAudit: &TestEmbeddedAudit{UpdatedBy: "foo"},
+			Name:              "entity",
+		},
+	}
+	res := bytecodeParseAndExpectNoErrs(t, `{entity {id createdAt name label updatedBy}}`, data, TestEmbeddedMethods{})
+	a.Equal(t, `{"entity":{"id":"1","createdAt":"today","name":"entity","label":"base base","updatedBy":"foo"}}`, res)
+}
+
+func TestEmbeddedNilPointer(t *testing.T) {
+	data := TestEmbeddedData{NilEmbedded: &TestEmbeddedEntity{}}
+	res, errs := bytecodeParse(t, NewSchema(), `{nilEmbedded {id}}`, data, TestEmbeddedMethods{})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"nilEmbedded":{"id":"0"}}`, res)
+
+	// updatedBy is non-null so the nil embedded struct results in a error
+	res, errs = bytecodeParse(t, NewSchema(), `{nilEmbedded {id updatedBy}}`, data, TestEmbeddedMethods{})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "cannot resolve updatedBy, the embedded struct that contains this field is nil", errs[0].Error())
+	a.Equal(t, `{"nilEmbedded":null}`, res)
+}
+
+func TestEmbeddedInput(t *testing.T) {
+	query := `mutation {create(limit: 2, entity: {id: "2", createdAt: "", name: "entity", updatedBy: "bar"}) {id createdAt name updatedBy}}`
+	res := bytecodeParseAndExpectNoErrs(t, query, TestEmbeddedData{}, TestEmbeddedMethods{})
+	a.Equal(t, `{"create":{"id":"2","createdAt":"xx","name":"entity","updatedBy":"bar"}}`, res)
+
+	query = `mutation($entity: TestEmbeddedEntity__input!) {create(limit: 1, entity: $entity) {id createdAt name updatedBy}}`
+	res = bytecodeParseAndExpectNoErrs(t, query, TestEmbeddedData{}, TestEmbeddedMethods{}, ResolveOptions{
+		NoMeta:    true,
+		Variables: `{"entity": {"id": "3", "createdAt": "", "name": "entity", "updatedBy": "baz"}}`,
+	})
+	a.Equal(t, `{"create":{"id":"3","createdAt":"x","name":"entity","updatedBy":"baz"}}`, res)
+}
+
+type testEmbeddedUnexported struct {
+	Limit int
+}
+
+type TestEmbeddedUnexportedFilter struct {
+	*testEmbeddedUnexported
+}
+
+func TestEmbeddedUnexportedPointer(t *testing.T) {
+	type Query struct {
+		*testEmbeddedUnexported
+	}
+
+	// Promoting the fields of a embedded pointer to a unexported struct works for outputs
+	res := bytecodeParseAndExpectNoErrs(t, `{limit}`, Query{&testEmbeddedUnexported{Limit: 2}}, M{})
+	a.Equal(t, `{"limit":2}`, res)
+
+	// But not for inputs as the struct cannot be allocated
+	err := NewSchema().Parse(Query{}, struct {
+		ResolveA func(struct {
+			*testEmbeddedUnexported
+		}) int
+	}{}, nil)
+	a.Error(t, err)
+	if err != nil {
+		a.True(t, strings.HasPrefix(err.Error(), "embedded pointers to unexported structs cannot be used as input"), err.Error())
+	}
+
+	err = NewSchema().Parse(Query{}, struct {
+		ResolveA func(struct {
+			Filter TestEmbeddedUnexportedFilter
+		}) int
+	}{}, nil)
+	a.Error(t, err)
+}
+
+type TestEmbeddedAmbiguousA struct {
+	Name string
+	A    string
+}
+
+type TestEmbeddedAmbiguousB struct {
+	Name string
+	B    string
+}
+
+type TestEmbeddedAmbiguousInner struct {
+	Name string
+}
+
+type TestEmbeddedAmbiguousC struct {
+	TestEmbeddedAmbiguousInner
+}
+
+type TestEmbeddedAmbiguousMethods struct{}
+
+func (TestEmbeddedAmbiguousMethods) ResolveFoo(args struct {
+	TestEmbeddedAmbiguousA
+	TestEmbeddedAmbiguousB
+}) string {
+	return args.A + args.B
+}
+
+func TestEmbeddedAmbiguous(t *testing.T) {
+	type Query struct {
+		TestEmbeddedAmbiguousA
+		TestEmbeddedAmbiguousB
+		// The name of this struct is shadowed by the ambiguous name fields
+		TestEmbeddedAmbiguousC
+	}
+
+	s := NewSchema()
+	err := s.Parse(Query{
+		TestEmbeddedAmbiguousA: TestEmbeddedAmbiguousA{Name: "a", A: "a"},
+		TestEmbeddedAmbiguousB: TestEmbeddedAmbiguousB{Name: "b", B: "b"},
+	}, TestEmbeddedAmbiguousMethods{}, nil)
+	a.NoError(t, err)
+
+	sdl, err := s.SDL()
+	a.NoError(t, err)
+	a.True(t, strings.Contains(sdl, "type Query {\n  a: String!\n  b: String!\n}\n"), sdl)
+	a.True(t, strings.Contains(sdl, "foo(a: String!, b: String!): String!"), sdl)
+
+	res, errs := bytecodeParse(t, NewSchema(), `{a b}`, Query{
+		TestEmbeddedAmbiguousA: TestEmbeddedAmbiguousA{Name: "a", A: "a"},
+		TestEmbeddedAmbiguousB: TestEmbeddedAmbiguousB{Name: "b", B: "b"},
+	}, TestEmbeddedAmbiguousMethods{}, ResolveOptions{NoMeta: true})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"a":"a","b":"b"}`, res)
+
+	_, errs = bytecodeParse(t, NewSchema(), `{name}`, Query{}, TestEmbeddedAmbiguousMethods{})
+	a.Equal(t, 1, len(errs))
+
+	_, errs = bytecodeParse(t, NewSchema(), `mutation {foo(a: "a", b: "b", name: "c")}`, Query{}, TestEmbeddedAmbiguousMethods{})
+	a.Equal(t, 1, len(errs))
+}
+
+func TestEmbeddedSchema(t *testing.T) {
+	s := NewSchema()
+	err := s.Parse(TestEmbeddedData{}, TestEmbeddedMethods{}, nil)
+	a.NoError(t, err)
+
+	sdl, err := s.SDL()
+	a.NoError(t, err)
+	a.True(t, strings.Contains(sdl, "type TestEmbeddedEntity {\n  createdAt: String!\n  id: ID!\n  label: String!\n  name: String!\n  updatedBy: String!\n}\n"), sdl)
+	a.True(t, strings.Contains(sdl, "input TestEmbeddedEntity__input {\n  createdAt: String!\n  id: ID!\n  name: String!\n  updatedBy: String!\n}\n"), sdl)
+	a.True(t, strings.Contains(sdl, "create(entity: TestEmbeddedEntity__input!, limit: Int!): TestEmbeddedEntity!"), sdl)
+	a.False(t, strings.Contains(sdl, "testEmbedded"), sdl)
+}
+
+func TestEmbeddedTagged(t *testing.T) {
+	type Query struct {
+		TestEmbeddedBase  `gq:"base"`
+		TestEmbeddedAudit `gq:"-"`
+	}
+
+	s := NewSchema()
+	err := s.Parse(Query{TestEmbeddedBase: TestEmbeddedBase{Name: "foo"}}, M{}, nil)
+	a.NoError(t, err)
+
+	sdl, err := s.SDL()
+	a.NoError(t, err)
+	a.True(t, strings.Contains(sdl, "  base: TestEmbeddedBase!\n"), sdl)
+	a.False(t, strings.Contains(sdl, "updatedBy"), sdl)
+}
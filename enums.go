@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
-
-	h "github.com/mjarkk/yarql/helpers"
 )
 
 type enum struct {
@@ -53,13 +51,20 @@ func validEnumType(t reflect.Type) bool {
 	}
 }
 
+// EnumOptions contains optional information about a enum shown in the introspection
+type EnumOptions struct {
+	Description       string
+	ValueDescriptions map[string]string // The descriptions of the enum values, the keys are the enum map keys
+	DeprecatedValues  map[string]string // The deprecated enum values with their deprecation reason, the keys are the enum map keys
+}
+
 // RegisterEnum registers a new enum type
-func (s *Schema) RegisterEnum(enumMap interface{}) (added bool, err error) {
+func (s *Schema) RegisterEnum(enumMap interface{}, options ...EnumOptions) (added bool, err error) {
 	if s.parsed {
 		return false, errors.New("(*yarql.Schema).RegisterEnum() cannot be ran after (*yarql.Schema).Parse()")
 	}
 
-	enum, err := registerEnumCheck(enumMap)
+	enum, err := registerEnumCheck(enumMap, options...)
 	if enum == nil || err != nil {
 		return false, err
 	}
@@ -68,7 +73,7 @@ func (s *Schema) RegisterEnum(enumMap interface{}) (added bool, err error) {
 	return true, nil
 }
 
-func registerEnumCheck(enumMap interface{}) (*enum, error) {
+func registerEnumCheck(enumMap interface{}, options ...EnumOptions) (*enum, error) {
 	mapReflection := reflect.ValueOf(enumMap)
 	invalidTypeMsg := fmt.Errorf("RegisterEnum input must be of type map[string]CustomType(int..|uint..|string) as input, %+v given", enumMap)
 
@@ -101,6 +106,24 @@ func registerEnumCheck(enumMap interface{}) (*enum, error) {
 		return nil, nil
 	}
 
+	var description string
+	var valueDescriptions, deprecatedValues map[string]string
+	if len(options) > 0 {
+		description = options[0].Description
+		valueDescriptions = options[0].ValueDescriptions
+		for key := range valueDescriptions {
+			if !mapReflection.MapIndex(reflect.ValueOf(key).Convert(mapType.Key())).IsValid() {
+				return nil, errors.New("RegisterEnum ValueDescriptions contains a description for the unknown enum value " + key)
+			}
+		}
+		deprecatedValues = options[0].DeprecatedValues
+		for key := range deprecatedValues {
+			if !mapReflection.MapIndex(reflect.ValueOf(key).Convert(mapType.Key())).IsValid() {
+				return nil, errors.New("RegisterEnum DeprecatedValues contains the unknown enum value " + key)
+			}
+		}
+	}
+
 	entries := make([]enumEntry, inputLen)
 	qlTypeEnumValues := make([]qlEnumValue, inputLen)
 
@@ -123,11 +146,12 @@ func registerEnumCheck(enumMap interface{}) (*enum, error) {
 			key:      keyStr,
 			value:    iter.Value(),
 		}
+		reason := deprecationReason(deprecatedValues, keyStr)
 		qlTypeEnumValues[i] = qlEnumValue{
 			Name:              keyStr,
-			Description:       h.PtrToEmptyStr,
-			IsDeprecated:      false,
-			DeprecationReason: nil,
+			Description:       qlDescription(valueDescriptions[keyStr]),
+			IsDeprecated:      reason != nil,
+			DeprecationReason: reason,
 		}
 		i++
 	}
@@ -137,8 +161,8 @@ func registerEnumCheck(enumMap interface{}) (*enum, error) {
 	qlType := qlType{
 		Kind:        typeKindEnum,
 		Name:        &name,
-		Description: h.PtrToEmptyStr,
-		EnumValues:  func(args isDeprecatedArgs) []qlEnumValue { return qlTypeEnumValues },
+		Description: qlDescription(description),
+		EnumValues:  func(args isDeprecatedArgs) []qlEnumValue { return filterDeprecatedEnumValues(qlTypeEnumValues, args) },
 	}
 
 	return &enum{
@@ -4,7 +4,6 @@ import (
 	"io/ioutil"
 	"log"
 	"mime/multipart"
-	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mjarkk/yarql"
@@ -19,9 +18,6 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// The GraphQL is not thread safe so we use this lock to prevent race conditions and other errors
-	var lock sync.Mutex
-
 	r.Any("/graphql", func(c *gin.Context) {
 		var form *multipart.Form
 
@@ -35,9 +31,6 @@ func main() {
 			return form, err
 		}
 
-		lock.Lock()
-		defer lock.Unlock()
-
 		res, _ := schema.HandleRequest(
 			c.Request.Method,
 			c.Query,
@@ -0,0 +1,93 @@
+package yarql
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/mjarkk/yarql/helpers"
+)
+
+// extension is a user defined value written to the extensions of the response
+type extension struct {
+	key   string
+	value interface{}
+}
+
+// SetExtension sets a value in the extensions object of the response
+// The value is encoded using encoding/json, setting the same key twice overwrites the previous value
+// The tracing and complexity keys are reserved for apollo tracing and the query complexity
+func (ctx *Ctx) SetExtension(key string, value interface{}) {
+	for idx, entry := range ctx.extensions {
+		if entry.key == key {
+			ctx.extensions[idx].value = value
+			return
+		}
+	}
+	ctx.extensions = append(ctx.extensions, extension{key: key, value: value})
+}
+
+// setExtensions sets the extensions of the resolve options sorted by key
+func (ctx *Ctx) setExtensions(extensions map[string]interface{}) {
+	if len(extensions) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(extensions))
+	for key := range extensions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ctx.SetExtension(key, extensions[key])
+	}
+}
+
+// hasExtensions returns true if the response has a non empty extensions object
+func (ctx *Ctx) hasExtensions() bool {
+	return ctx.tracingEnabled || ctx.complexity != -1 || len(ctx.extensions) > 0
+}
+
+// writeExtensions writes the contents of the extensions object
+func (ctx *Ctx) writeExtensions() {
+	hasComplexity := ctx.complexity != -1
+
+	isFirst := true
+	writeKey := func(key string) {
+		if !isFirst {
+			ctx.writeByte(',')
+		}
+		isFirst = false
+		helpers.StringToJSON(key, &ctx.result)
+		ctx.writeByte(':')
+	}
+	writeJSON := func(value interface{}) {
+		valueJSON, err := json.Marshal(value)
+		if err == nil {
+			ctx.write(valueJSON)
+		} else {
+			ctx.writeNull()
+		}
+	}
+
+	if ctx.tracingEnabled {
+		writeKey("tracing")
+		ctx.tracing.finish()
+		writeJSON(ctx.tracing)
+	}
+	if hasComplexity {
+		writeKey("complexity")
+		ctx.write([]byte(`{"cost":`))
+		ctx.result = strconv.AppendInt(ctx.result, int64(ctx.complexity), 10)
+		ctx.write([]byte(`,"max":`))
+		ctx.result = strconv.AppendInt(ctx.result, int64(ctx.maxComplexity), 10)
+		ctx.writeByte('}')
+	}
+	for _, entry := range ctx.extensions {
+		if entry.key == "tracing" && ctx.tracingEnabled || entry.key == "complexity" && hasComplexity {
+			continue
+		}
+		writeKey(entry.key)
+		writeJSON(entry.value)
+	}
+}
@@ -0,0 +1,59 @@
+package yarql
+
+import (
+	"strings"
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+type TestExtensionsData struct{}
+
+func (TestExtensionsData) ResolveFoo(ctx *Ctx) string {
+	ctx.SetExtension("rateLimit", map[string]int{"remaining": 99})
+	ctx.SetExtension("requestId", "overwritten")
+	return "foo"
+}
+
+func (TestExtensionsData) ResolveBar(ctx *Ctx) string {
+	ctx.SetExtension("tracing", "reserved")
+	return "bar"
+}
+
+func TestExtensions(t *testing.T) {
+	res, errs := bytecodeParse(t, NewSchema(), `{foo}`, TestExtensionsData{}, M{}, ResolveOptions{
+		Extensions: map[string]interface{}{
+			"requestId": "abc",
+			"b":         []int{1, 2},
+			"a":         nil,
+		},
+	})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"foo":"foo"},"extensions":{"a":null,"b":[1,2],"requestId":"overwritten","rateLimit":{"remaining":99}}}`, res)
+
+	res, errs = bytecodeParse(t, NewSchema(), `{foo}`, TestExtensionsData{}, M{}, ResolveOptions{NoMeta: true})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"foo":"foo"}`, res)
+}
+
+func TestExtensionsWithBuiltInExtensions(t *testing.T) {
+	res, errs := bytecodeParse(t, NewSchema(), `{bar}`, TestExtensionsData{}, M{}, ResolveOptions{
+		MaxComplexity: 10,
+		Extensions:    map[string]interface{}{"complexity": "reserved", "foo": "bar"},
+	})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"bar":"bar"},"extensions":{"complexity":{"cost":1,"max":10},"foo":"bar","tracing":"reserved"}}`, res)
+
+	res, errs = bytecodeParse(t, NewSchema(), `{bar}`, TestExtensionsData{}, M{}, ResolveOptions{Tracing: true})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, 1, strings.Count(res, `"tracing":`))
+	a.False(t, strings.Contains(res, `"reserved"`))
+}
+
+func TestExtensionsParallel(t *testing.T) {
+	res, errs := bytecodeParse(t, NewSchema(), `{foo bar}`, TestExtensionsData{}, M{}, ResolveOptions{
+		Parallel: true,
+	})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"foo":"foo","bar":"bar"},"extensions":{"rateLimit":{"remaining":99},"requestId":"overwritten","tracing":"reserved"}}`, res)
+}
@@ -23,7 +23,7 @@ type qlSchema struct {
 }
 
 type isDeprecatedArgs struct {
-	IncludeDeprecated bool `json:"includeDeprecated"`
+	IncludeDeprecated *bool `json:"includeDeprecated"`
 }
 
 type __TypeKind uint8
@@ -94,7 +94,7 @@ type qlType struct {
 	EnumValues func(isDeprecatedArgs) []qlEnumValue `json:"-"`
 
 	// INPUT_OBJECT only
-	InputFields func() []qlInputValue `json:"-"`
+	InputFields func(isDeprecatedArgs) []qlInputValue `json:"-"`
 
 	// NON_NULL and LIST only
 	OfType *qlType `json:"ofType"`
@@ -111,12 +111,15 @@ type qlType struct {
 var _ = TypeRename(qlField{}, "__Field", true)
 
 type qlField struct {
-	Name              string         `json:"name"`
-	Description       *string        `json:"description"`
-	Args              []qlInputValue `json:"args"`
-	Type              qlType         `json:"type"`
-	IsDeprecated      bool           `json:"isDeprecated"`
-	DeprecationReason *string        `json:"deprecationReason"`
+	Name              string                                `json:"name"`
+	Description       *string                               `json:"description"`
+	Args              func(isDeprecatedArgs) []qlInputValue `json:"-"`
+	Type              qlType                                `json:"type"`
+	IsDeprecated      bool                                  `json:"isDeprecated"`
+	DeprecationReason *string                               `json:"deprecationReason"`
+
+	// For testing perposes
+	JSONArgs []qlInputValue `json:"args" gq:"-"`
 }
 
 var _ = TypeRename(qlEnumValue{}, "__EnumValue", true)
@@ -131,10 +134,12 @@ type qlEnumValue struct {
 var _ = TypeRename(qlInputValue{}, "__InputValue", true)
 
 type qlInputValue struct {
-	Name         string  `json:"name"`
-	Description  *string `json:"description"`
-	Type         qlType  `json:"type"`
-	DefaultValue *string `json:"defaultValue"`
+	Name              string  `json:"name"`
+	Description       *string `json:"description"`
+	Type              qlType  `json:"type"`
+	DefaultValue      *string `json:"defaultValue"`
+	IsDeprecated      bool    `json:"isDeprecated"`
+	DeprecationReason *string `json:"deprecationReason"`
 }
 
 type __DirectiveLocation uint8
@@ -181,14 +186,26 @@ var directiveLocationMap = map[string]__DirectiveLocation{
 	"INPUT_FIELD_DEFINITION": directiveLocationInputFieldDefinition,
 }
 
+func (l __DirectiveLocation) String() string {
+	for key, value := range directiveLocationMap {
+		if value == l {
+			return key
+		}
+	}
+	return ""
+}
+
 var _ = TypeRename(qlDirective{}, "__Directive", true)
 
 type qlDirective struct {
-	Name          string                `json:"name"`
-	Description   *string               `json:"description"`
-	Locations     []__DirectiveLocation `json:"-"`
-	JSONLocations []string              `json:"locations" gq:"-"`
-	Args          []qlInputValue        `json:"args"`
+	Name          string                                `json:"name"`
+	Description   *string                               `json:"description"`
+	Locations     []__DirectiveLocation                 `json:"-"`
+	JSONLocations []string                              `json:"locations" gq:"-"`
+	Args          func(isDeprecatedArgs) []qlInputValue `json:"-"`
+
+	// For testing perposes
+	JSONArgs []qlInputValue `json:"args" gq:"-"`
 }
 
 var (
@@ -229,6 +246,12 @@ var (
 		Description:    h.StrPtr("The Time scalar type references to a ISO 8601 date+time, often used to insert and/or view dates. Expects a string with the ISO 8601 format"),
 		SpecifiedByURL: h.StrPtr("https://en.wikipedia.org/wiki/ISO_8601"),
 	}
+	scalarJSON = qlType{
+		Kind:           typeKindScalar,
+		Name:           h.StrPtr("JSON"),
+		Description:    h.StrPtr("The JSON scalar type represents a JSON value, used for maps"),
+		SpecifiedByURL: h.StrPtr("https://www.rfc-editor.org/rfc/rfc8259"),
+	}
 )
 
 var scalars = map[string]qlType{
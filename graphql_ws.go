@@ -0,0 +1,332 @@
+package yarql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjarkk/yarql/bytecode"
+	"github.com/mjarkk/yarql/helpers"
+	"github.com/valyala/fastjson"
+)
+
+// WebSocketProtocol is the websocket sub protocol spoken by (*Schema).WebSocketHandler
+// https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md
+const WebSocketProtocol = "graphql-transport-ws"
+
+// Close codes of the graphql-transport-ws protocol
+const (
+	wsCloseBadRequest      uint16 = 4400
+	wsCloseUnauthorized    uint16 = 4401
+	wsCloseForbidden       uint16 = 4403
+	wsCloseInitTimeout     uint16 = 4408
+	wsCloseSubscriberExist uint16 = 4409
+	wsCloseTooManyInits    uint16 = 4429
+)
+
+// WebSocketOptions are options for the (*Schema).WebSocketHandler method
+type WebSocketOptions struct {
+	// RequestOptions are used for every operation executed over the connection
+	// The Context option is ignored, operations use the context of the http request
+	RequestOptions RequestOptions
+
+	// OnConnect is called when the client sends the connection_init message with the payload of that message
+	// The returned values are passed to the request context of every operation of this connection
+	// Returning an error rejects the connection
+	OnConnect func(r *http.Request, payload json.RawMessage) (map[string]interface{}, error)
+
+	// ConnectionInitTimeout is the time the client has to send connection_init after the connection is opened
+	// Default 3 seconds
+	ConnectionInitTimeout time.Duration
+
+	// CheckOrigin returns true if the connection is allowed for the Origin header of the request
+	// Connections that are not allowed are rejected with 403 Forbidden
+	// Defaults to only allowing requests without an Origin header or with an Origin equal to the Host header,
+	// this prevents other websites from opening a connection using the cookies of the user
+	CheckOrigin func(r *http.Request) bool
+}
+
+// WebSocketHandler returns a http handler that speaks the graphql-transport-ws protocol
+// Queries and mutations are resolved using (*Schema).HandleRequest, subscriptions are resolved using (*Schema).Subscribe
+func (s *Schema) WebSocketHandler(options *WebSocketOptions) http.Handler {
+	if options == nil {
+		options = &WebSocketOptions{}
+	}
+
+	checkOrigin := options.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = wsSameOrigin
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !checkOrigin(r) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+
+		conn, err := wsUpgrade(w, r, WebSocketProtocol)
+		if err != nil {
+			return
+		}
+
+		session := &wsSession{
+			schema:     s,
+			conn:       conn,
+			request:    r,
+			options:    options,
+			operations: map[string]*wsOperation{},
+		}
+		session.serve()
+	})
+}
+
+// wsSameOrigin returns true if the request has no Origin header or the host of the Origin equals the Host header
+func wsSameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		return true
+	}
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(originURL.Host, r.Host)
+}
+
+// wsSession contains the state of a single graphql-transport-ws connection
+type wsSession struct {
+	schema  *Schema
+	conn    *wsConn
+	request *http.Request
+	options *WebSocketOptions
+
+	context    context.Context
+	lock       sync.Mutex
+	initiated  bool                    // connection_init was received
+	acked      bool                    // connection_ack was send
+	values     map[string]interface{}  // returned by OnConnect
+	operations map[string]*wsOperation // running operations by id
+	wg         sync.WaitGroup
+}
+
+type wsOperation struct {
+	cancel context.CancelFunc
+}
+
+func (c *wsSession) serve() {
+	var cancel context.CancelFunc
+	c.context, cancel = context.WithCancel(c.request.Context())
+	defer func() {
+		cancel()
+		c.wg.Wait()
+		c.conn.close()
+	}()
+
+	initTimeout := c.options.ConnectionInitTimeout
+	if initTimeout == 0 {
+		initTimeout = 3 * time.Second
+	}
+	initTimer := time.AfterFunc(initTimeout, func() {
+		c.lock.Lock()
+		acked := c.acked
+		c.lock.Unlock()
+		if !acked {
+			c.conn.writeClose(wsCloseInitTimeout, "Connection initialisation timeout")
+			c.conn.close()
+		}
+	})
+	defer initTimer.Stop()
+
+	var parser fastjson.Parser
+	for {
+		_, data, err := c.conn.readMessage()
+		if err != nil {
+			return
+		}
+
+		message, err := parser.ParseBytes(data)
+		if err != nil || message.Type() != fastjson.TypeObject {
+			c.conn.writeClose(wsCloseBadRequest, "Invalid message received")
+			return
+		}
+
+		switch string(message.GetStringBytes("type")) {
+		case "connection_init":
+			if c.initiated {
+				c.conn.writeClose(wsCloseTooManyInits, "Too many initialisation requests")
+				return
+			}
+			c.initiated = true
+
+			if c.options.OnConnect != nil {
+				var payload json.RawMessage
+				if payloadValue := message.Get("payload"); payloadValue != nil {
+					payload = payloadValue.MarshalTo(nil)
+				}
+				c.values, err = c.options.OnConnect(c.request, payload)
+				if err != nil {
+					c.conn.writeClose(wsCloseForbidden, "Forbidden")
+					return
+				}
+			}
+
+			c.lock.Lock()
+			c.acked = true
+			c.lock.Unlock()
+			c.conn.writeText([]byte(`{"type":"connection_ack"}`))
+		case "ping":
+			c.conn.writeText([]byte(`{"type":"pong"}`))
+		case "pong":
+			// Nothing to do here
+		case "subscribe":
+			if !c.acked {
+				c.conn.writeClose(wsCloseUnauthorized, "Unauthorized")
+				return
+			}
+
+			id := string(message.GetStringBytes("id"))
+			payload := message.Get("payload")
+			if len(id) == 0 || payload == nil {
+				c.conn.writeClose(wsCloseBadRequest, "Invalid message received")
+				return
+			}
+			req, err := getBodyData(payload)
+			if err != nil {
+				c.conn.writeClose(wsCloseBadRequest, "Invalid message received")
+				return
+			}
+			c.lock.Lock()
+			_, exists := c.operations[id]
+			if exists {
+				c.lock.Unlock()
+				c.conn.writeClose(wsCloseSubscriberExist, "Subscriber for "+id+" already exists")
+				return
+			}
+			operationContext, cancelOperation := context.WithCancel(c.context)
+			operation := &wsOperation{cancel: cancelOperation}
+			c.operations[id] = operation
+			c.lock.Unlock()
+
+			c.wg.Add(1)
+			go c.execute(operationContext, operation, id, req, payload.MarshalTo(nil))
+		case "complete":
+			id := string(message.GetStringBytes("id"))
+
+			c.lock.Lock()
+			operation, ok := c.operations[id]
+			delete(c.operations, id)
+			c.lock.Unlock()
+
+			if ok {
+				operation.cancel()
+			}
+		default:
+			c.conn.writeClose(wsCloseBadRequest, "Invalid message received")
+			return
+		}
+	}
+}
+
+// execute runs a single operation and sends the results to the client
+func (c *wsSession) execute(ctx context.Context, operation *wsOperation, id string, req request, body []byte) {
+	defer c.wg.Done()
+
+	// Errors that prevent the operation from being executed are send as a error message that also completes the operation
+	query, document, errMsg := c.schema.requestQuery(req, &c.options.RequestOptions)
+	var errs []byte
+	if len(errMsg) > 0 {
+		errs = append(errs, `[{"message":`...)
+		helpers.StringToJSON(errMsg, &errs)
+		errs = append(errs, `}]`...)
+	} else {
+		resolveOptions := newResolveOptions(req.variables, req.operationName, &c.options.RequestOptions)
+		resolveOptions.trustedDocument = document
+		errs = c.schema.requestErrors(s2b(query), resolveOptions)
+	}
+	if errs != nil {
+		if c.removeOperation(id, operation) {
+			c.conn.writeText(wsOperationMessage("error", id, errs))
+		}
+		return
+	}
+
+	requestOptions := c.options.RequestOptions
+	requestOptions.Context = ctx
+
+	// Every operation gets it's own values map as operations are executed concurrently
+	requestOptions.Values = make(map[string]interface{}, len(c.options.RequestOptions.Values)+len(c.values))
+	for key, value := range c.options.RequestOptions.Values {
+		requestOptions.Values[key] = value
+	}
+	for key, value := range c.values {
+		requestOptions.Values[key] = value
+	}
+
+	kind, ok := c.schema.operationKind(s2b(query), req.operationName)
+	if ok && kind == bytecode.OperatorSubscription {
+		responses, cancel := c.schema.Subscribe(s2b(query), newResolveOptions(req.variables, req.operationName, &requestOptions))
+		defer cancel()
+
+		for response := range responses {
+			c.sendNext(ctx, id, response)
+		}
+	} else {
+		response, _ := c.schema.HandleRequest(
+			"POST",
+			func(key string) string { return "" },
+			func(key string) (string, error) {
+				return "", errors.New("form fields are not supported over websockets")
+			},
+			func() []byte { return body },
+			"application/json",
+			&requestOptions,
+		)
+		c.sendNext(ctx, id, response)
+	}
+
+	if c.removeOperation(id, operation) {
+		// Only send complete if the operation was not completed by the client
+		c.conn.writeText(wsOperationMessage("complete", id, nil))
+	}
+}
+
+// removeOperation removes a finished operation
+// Returns false if the operation was already completed by the client
+func (c *wsSession) removeOperation(id string, operation *wsOperation) bool {
+	// The client might have completed this operation and re-used the id for a new operation
+	c.lock.Lock()
+	stillRunning := c.operations[id] == operation
+	if stillRunning {
+		delete(c.operations, id)
+	}
+	c.lock.Unlock()
+	operation.cancel()
+	return stillRunning
+}
+
+func (c *wsSession) sendNext(ctx context.Context, id string, response []byte) {
+	if ctx.Err() != nil {
+		// The operation is completed by the client or the connection is closed
+		return
+	}
+	c.conn.writeText(wsOperationMessage("next", id, response))
+}
+
+// wsOperationMessage creates a message for a specific operation
+func wsOperationMessage(kind string, id string, payload []byte) []byte {
+	res := make([]byte, 0, len(payload)+len(id)+40)
+	res = append(res, `{"type":"`...)
+	res = append(res, kind...)
+	res = append(res, `","id":`...)
+	helpers.StringToJSON(id, &res)
+	if payload != nil {
+		res = append(res, `,"payload":`...)
+		res = append(res, payload...)
+	}
+	return append(res, '}')
+}
@@ -0,0 +1,252 @@
+package yarql
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+// dialTestWebSocket opens a websocket connection to the test server
+func dialTestWebSocket(t *testing.T, server *httptest.Server, protocol string, headers ...string) (*wsConn, *http.Response) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	a.NoError(t, err)
+
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\n" +
+		"Host: " + strings.TrimPrefix(server.URL, "http://") + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: " + key + "\r\n" +
+		"Sec-WebSocket-Version: 13\r\n" +
+		"Sec-WebSocket-Protocol: " + protocol + "\r\n" +
+		strings.Join(append(headers, ""), "\r\n") + "\r\n"))
+	a.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	res, err := http.ReadResponse(reader, nil)
+	a.NoError(t, err)
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, res
+	}
+	a.Equal(t, wsAcceptKey(key), res.Header.Get("Sec-WebSocket-Accept"))
+	a.Equal(t, WebSocketProtocol, res.Header.Get("Sec-WebSocket-Protocol"))
+
+	return &wsConn{
+		conn:     conn,
+		reader:   reader,
+		isClient: true,
+	}, res
+}
+
+func wsTestSend(t *testing.T, conn *wsConn, message string) {
+	a.NoError(t, conn.writeText([]byte(message)))
+}
+
+func wsTestRead(t *testing.T, conn *wsConn) string {
+	conn.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, message, err := conn.readMessage()
+	a.NoError(t, err)
+	return string(message)
+}
+
+func wsTestExpectClose(t *testing.T, conn *wsConn, code uint16) {
+	conn.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err := conn.readMessage()
+	closeErr, ok := err.(wsCloseError)
+	a.True(t, ok, err)
+	a.Equal(t, code, closeErr.code)
+}
+
+func newTestWebSocketServer(t *testing.T, options *WebSocketOptions) *httptest.Server {
+	s := newTestSubscribeSchema(t)
+	server := httptest.NewServer(s.WebSocketHandler(options))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func connectTestWebSocket(t *testing.T, server *httptest.Server) *wsConn {
+	conn, _ := dialTestWebSocket(t, server, WebSocketProtocol)
+	a.NotNil(t, conn)
+	t.Cleanup(func() { conn.close() })
+
+	wsTestSend(t, conn, `{"type":"connection_init"}`)
+	a.Equal(t, `{"type":"connection_ack"}`, wsTestRead(t, conn))
+	return conn
+}
+
+func TestWebSocketPing(t *testing.T) {
+	conn := connectTestWebSocket(t, newTestWebSocketServer(t, nil))
+
+	wsTestSend(t, conn, `{"type":"ping"}`)
+	a.Equal(t, `{"type":"pong"}`, wsTestRead(t, conn))
+}
+
+func TestWebSocketQuery(t *testing.T) {
+	conn := connectTestWebSocket(t, newTestWebSocketServer(t, nil))
+
+	wsTestSend(t, conn, `{"id":"1","type":"subscribe","payload":{"query":"{a}"}}`)
+	a.Equal(t, `{"type":"next","id":"1","payload":{"data":{"a":"foo"}}}`, wsTestRead(t, conn))
+	a.Equal(t, `{"type":"complete","id":"1"}`, wsTestRead(t, conn))
+}
+
+func TestWebSocketSubscription(t *testing.T) {
+	conn := connectTestWebSocket(t, newTestWebSocketServer(t, nil))
+
+	wsTestSend(t, conn, `{"id":"a","type":"subscribe","payload":{"query":"subscription ($to: Int!) {counter(to: $to) {nr}}","variables":{"to":2}}}`)
+	a.Equal(t, `{"type":"next","id":"a","payload":{"data":{"counter":{"nr":1}}}}`, wsTestRead(t, conn))
+	a.Equal(t, `{"type":"next","id":"a","payload":{"data":{"counter":{"nr":2}}}}`, wsTestRead(t, conn))
+	a.Equal(t, `{"type":"complete","id":"a"}`, wsTestRead(t, conn))
+}
+
+func TestWebSocketCompleteByClient(t *testing.T) {
+	conn := connectTestWebSocket(t, newTestWebSocketServer(t, nil))
+
+	wsTestSend(t, conn, `{"id":"a","type":"subscribe","payload":{"query":"subscription {untilCancelled}"}}`)
+	a.Equal(t, `{"type":"next","id":"a","payload":{"data":{"untilCancelled":1}}}`, wsTestRead(t, conn))
+	wsTestSend(t, conn, `{"id":"a","type":"complete"}`)
+
+	// After completing the id can be re-used
+	wsTestSend(t, conn, `{"id":"a","type":"subscribe","payload":{"query":"{a}"}}`)
+	a.Equal(t, `{"type":"next","id":"a","payload":{"data":{"a":"foo"}}}`, wsTestRead(t, conn))
+	a.Equal(t, `{"type":"complete","id":"a"}`, wsTestRead(t, conn))
+}
+
+func TestWebSocketSubscriptionError(t *testing.T) {
+	conn := connectTestWebSocket(t, newTestWebSocketServer(t, nil))
+
+	wsTestSend(t, conn, `{"id":"1","type":"subscribe","payload":{"query":"subscription {fails}"}}`)
+	a.Equal(t, `{"type":"next","id":"1","payload":{"data":null,"errors":[{"message":"this subscription fails","locations":[{"line":1,"column":15}],"path":["fails"]}],"extensions":{}}}`, wsTestRead(t, conn))
+	a.Equal(t, `{"type":"complete","id":"1"}`, wsTestRead(t, conn))
+}
+
+func TestWebSocketValidationError(t *testing.T) {
+	conn := connectTestWebSocket(t, newTestWebSocketServer(t, nil))
+
+	wsTestSend(t, conn, `{"id":"1","type":"subscribe","payload":{"query":"{doesNotExist}"}}`)
+	a.Equal(t, `{"type":"error","id":"1","payload":[{"message":"Cannot query field \"doesNotExist\" on type \"TestResolveSimpleQueryData\".","locations":[{"line":1,"column":2}]}]}`, wsTestRead(t, conn))
+
+	wsTestSend(t, conn, `{"id":"2","type":"subscribe","payload":{"query":"{a"}}`)
+	a.Equal(t, `{"type":"error","id":"2","payload":[{"message":"unexpected EOF","locations":[{"line":1,"column":3}]}]}`, wsTestRead(t, conn))
+
+	wsTestSend(t, conn, `{"id":"3","type":"subscribe","payload":{"documentId":"unknown"}}`)
+	a.Equal(t, `{"type":"error","id":"3","payload":[{"message":"unknown document id unknown"}]}`, wsTestRead(t, conn))
+
+	// The id of a operation that failed can be re-used
+	wsTestSend(t, conn, `{"id":"1","type":"subscribe","payload":{"query":"{a}"}}`)
+	a.Equal(t, `{"type":"next","id":"1","payload":{"data":{"a":"foo"}}}`, wsTestRead(t, conn))
+	a.Equal(t, `{"type":"complete","id":"1"}`, wsTestRead(t, conn))
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	server := newTestWebSocketServer(t, nil)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	conn, res := dialTestWebSocket(t, server, WebSocketProtocol, "Origin: http://"+host)
+	a.NotNil(t, conn)
+	a.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	conn.close()
+
+	conn, res = dialTestWebSocket(t, server, WebSocketProtocol, "Origin: https://evil.example.com")
+	a.Nil(t, conn)
+	a.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	server = newTestWebSocketServer(t, &WebSocketOptions{
+		CheckOrigin: func(r *http.Request) bool {
+			return r.Header.Get("Origin") == "https://example.com"
+		},
+	})
+
+	conn, res = dialTestWebSocket(t, server, WebSocketProtocol, "Origin: https://example.com")
+	a.NotNil(t, conn)
+	a.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	conn.close()
+
+	conn, res = dialTestWebSocket(t, server, WebSocketProtocol, "Origin: http://"+host)
+	a.Nil(t, conn)
+	a.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func TestWebSocketProtocolErrors(t *testing.T) {
+	server := newTestWebSocketServer(t, nil)
+
+	conn, res := dialTestWebSocket(t, server, "graphql-ws")
+	a.Nil(t, conn)
+	a.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	conn, _ = dialTestWebSocket(t, server, WebSocketProtocol)
+	wsTestSend(t, conn, `{"id":"1","type":"subscribe","payload":{"query":"{a}"}}`)
+	wsTestExpectClose(t, conn, wsCloseUnauthorized)
+	conn.close()
+
+	conn = connectTestWebSocket(t, server)
+	wsTestSend(t, conn, `{"type":"connection_init"}`)
+	wsTestExpectClose(t, conn, wsCloseTooManyInits)
+
+	conn = connectTestWebSocket(t, server)
+	wsTestSend(t, conn, `this is not json`)
+	wsTestExpectClose(t, conn, wsCloseBadRequest)
+
+	conn = connectTestWebSocket(t, server)
+	wsTestSend(t, conn, `{"id":"1","type":"subscribe","payload":{"query":"subscription {untilCancelled}"}}`)
+	wsTestSend(t, conn, `{"id":"1","type":"subscribe","payload":{"query":"subscription {untilCancelled}"}}`)
+	for {
+		conn.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, _, err := conn.readMessage()
+		if err != nil {
+			closeErr, ok := err.(wsCloseError)
+			a.True(t, ok, err)
+			a.Equal(t, wsCloseSubscriberExist, closeErr.code)
+			break
+		}
+	}
+}
+
+func TestWebSocketInitTimeout(t *testing.T) {
+	server := newTestWebSocketServer(t, &WebSocketOptions{ConnectionInitTimeout: time.Millisecond * 10})
+
+	conn, _ := dialTestWebSocket(t, server, WebSocketProtocol)
+	defer conn.close()
+	wsTestExpectClose(t, conn, wsCloseInitTimeout)
+}
+
+func TestWebSocketOnConnect(t *testing.T) {
+	server := newTestWebSocketServer(t, &WebSocketOptions{
+		OnConnect: func(r *http.Request, payload json.RawMessage) (map[string]interface{}, error) {
+			var data struct{ Token string }
+			err := json.Unmarshal(payload, &data)
+			if err != nil || data.Token != "secret" {
+				return nil, errors.New("invalid token")
+			}
+			return map[string]interface{}{"token": data.Token}, nil
+		},
+	})
+
+	conn, _ := dialTestWebSocket(t, server, WebSocketProtocol)
+	defer conn.close()
+	wsTestSend(t, conn, `{"type":"connection_init","payload":{"token":"wrong"}}`)
+	wsTestExpectClose(t, conn, wsCloseForbidden)
+
+	conn, _ = dialTestWebSocket(t, server, WebSocketProtocol)
+	defer conn.close()
+	wsTestSend(t, conn, `{"type":"connection_init","payload":{"token":"secret"}}`)
+	a.Equal(t, `{"type":"connection_ack"}`, wsTestRead(t, conn))
+}
+
+func TestWebSocketLargeMessage(t *testing.T) {
+	conn := connectTestWebSocket(t, newTestWebSocketServer(t, nil))
+
+	// Messages larger than 65535 bytes use the 64 bit length encoding
+	query := "{a " + strings.Repeat(" ", 70000) + "}"
+	wsTestSend(t, conn, `{"id":"1","type":"subscribe","payload":{"query":"`+query+`"}}`)
+	a.Equal(t, `{"type":"next","id":"1","payload":{"data":{"a":"foo"}}}`, wsTestRead(t, conn))
+	a.Equal(t, `{"type":"complete","id":"1"}`, wsTestRead(t, conn))
+}
@@ -1,7 +1,6 @@
 package yarql
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"mime/multipart"
@@ -17,9 +16,27 @@ type RequestOptions struct {
 	Values      map[string]interface{}                          // Passed directly to the request context
 	GetFormFile func(key string) (*multipart.FileHeader, error) // Get form file to support file uploading
 	Tracing     bool                                            // https://github.com/apollographql/apollo-tracing
+
+	// TrustedDocumentsOnly only allows queries registered using (*Schema).RegisterTrustedDocuments
+	TrustedDocumentsOnly bool
+
+	// Parallel resolves the method fields of a selection set concurrently
+	Parallel bool
+}
+
+// request contains the data of a single graphql request
+type request struct {
+	query              string
+	operationName      string
+	variables          string
+	documentID         string // The id of a trusted document
+	persistedQueryHash string // The sha256 hash of a automatic persisted query
 }
 
 // HandleRequest handles a http request and returns a response
+//
+// HandleRequest is safe to be called from multiple goroutines at once, every request is resolved using (*Schema).ResolveConcurrent
+// The returned response is owned by the caller
 func (s *Schema) HandleRequest(
 	method string, // GET, POST, etc..
 	getQuery func(key string) string, // URL value (needs to be un-escaped before returning)
@@ -31,10 +48,7 @@ func (s *Schema) HandleRequest(
 	method = strings.ToUpper(method)
 
 	errRes := func(errorMsg string) ([]byte, []error) {
-		response := []byte(`{"data":{},"errors":[{"message":`)
-		helpers.StringToJSON(errorMsg, &response)
-		response = append(response, []byte(`}],"extensions":{}}`)...)
-		return response, []error{errors.New(errorMsg)}
+		return appendErrorResponse(nil, errorMsg), []error{errors.New(errorMsg)}
 	}
 
 	if contentType == "application/json" || ((contentType == "text/plain" || contentType == "multipart/form-data") && method != "GET") {
@@ -60,65 +74,83 @@ func (s *Schema) HandleRequest(
 		if v.Type() == fastjson.TypeArray {
 			// Handle batch query
 			responseErrs := []error{}
-			response := bytes.NewBuffer([]byte("["))
+			response := []byte{'['}
 			for _, item := range v.GetArray() {
 				// TODO potential speed improvement by executing all items at once
 				if item == nil {
 					continue
 				}
 
-				if response.Len() > 1 {
-					response.WriteByte(',')
+				if len(response) > 1 {
+					response = append(response, ',')
 				}
 
-				query, operationName, variables, err := getBodyData(item)
+				req, err := getBodyData(item)
 				if err != nil {
 					responseErrs = append(responseErrs, err)
-					res, _ := errRes(err.Error())
-					response.Write(res)
+					response = appendErrorResponse(response, err.Error())
 				} else {
-					errs := s.handleSingleRequest(
-						query,
-						variables,
-						operationName,
-						options,
-					)
+					var errs []error
+					response, errs = s.handleSingleRequest(req, options, response)
 					responseErrs = append(responseErrs, errs...)
-					response.Write(s.Result)
 				}
 			}
-			response.WriteByte(']')
-			return response.Bytes(), responseErrs
+			response = append(response, ']')
+			return response, responseErrs
 		}
 
-		query, operationName, variables, err := getBodyData(v)
+		req, err := getBodyData(v)
 		if err != nil {
 			return errRes(err.Error())
 		}
-		errs := s.handleSingleRequest(
-			query,
-			variables,
-			operationName,
-			options,
-		)
-		return s.Result, errs
-	}
-
-	errs := s.handleSingleRequest(
-		getQuery("query"),
-		getQuery("variables"),
-		getQuery("operationName"),
-		options,
-	)
-	return s.Result, errs
+		return s.handleSingleRequest(req, options, nil)
+	}
+
+	req := request{
+		query:         getQuery("query"),
+		operationName: getQuery("operationName"),
+		variables:     getQuery("variables"),
+		documentID:    getQuery("documentId"),
+	}
+	extensions := getQuery("extensions")
+	if len(extensions) > 0 {
+		var p fastjson.Parser
+		v, err := p.Parse(extensions)
+		if err != nil {
+			return errRes("invalid extensions param, must be valid json")
+		}
+		req.persistedQueryHash, err = getPersistedQueryHash(v)
+		if err != nil {
+			return errRes(err.Error())
+		}
+	}
+	return s.handleSingleRequest(req, options, nil)
+}
+
+// appendErrorResponse appends a response with only a single error to the response
+func appendErrorResponse(response []byte, errorMsg string) []byte {
+	response = append(response, []byte(`{"data":{},"errors":[{"message":`)...)
+	helpers.StringToJSON(errorMsg, &response)
+	return append(response, []byte(`}],"extensions":{}}`)...)
 }
 
-func (s *Schema) handleSingleRequest(
-	query,
-	variables,
-	operationName string,
-	options *RequestOptions,
-) []error {
+// handleSingleRequest resolves a single query and appends the result to the response
+func (s *Schema) handleSingleRequest(req request, options *RequestOptions, response []byte) ([]byte, []error) {
+	query, document, errMsg := s.requestQuery(req, options)
+	if len(errMsg) > 0 {
+		return appendErrorResponse(response, errMsg), []error{errors.New(errMsg)}
+	}
+
+	resolveOptions := newResolveOptions(req.variables, req.operationName, options)
+	resolveOptions.trustedDocument = document
+	res, errs := s.ResolveConcurrent(s2b(query), resolveOptions)
+	response = append(response, res.Result...)
+	res.Release()
+	return response, errs
+}
+
+// newResolveOptions converts request options into resolve options
+func newResolveOptions(variables, operationName string, options *RequestOptions) ResolveOptions {
 	resolveOptions := ResolveOptions{
 		OperatorTarget: operationName,
 		Variables:      variables,
@@ -134,28 +166,50 @@ func (s *Schema) handleSingleRequest(
 			resolveOptions.GetFormFile = options.GetFormFile
 		}
 		resolveOptions.Tracing = options.Tracing
+		resolveOptions.Parallel = options.Parallel
 	}
-
-	return s.Resolve(s2b(query), resolveOptions)
+	return resolveOptions
 }
 
-func getBodyData(body *fastjson.Value) (query, operationName, variables string, err error) {
+func getBodyData(body *fastjson.Value) (req request, err error) {
 	if body.Type() != fastjson.TypeObject {
 		err = errors.New("body should be a object")
 		return
 	}
 
-	jsonQuery := body.Get("query")
-	if jsonQuery == nil {
-		err = errors.New("query should be defined")
-		return
+	jsonExtensions := body.Get("extensions")
+	if jsonExtensions != nil {
+		req.persistedQueryHash, err = getPersistedQueryHash(jsonExtensions)
+		if err != nil {
+			return
+		}
 	}
-	queryBytes, err := jsonQuery.StringBytes()
-	if err != nil {
-		err = errors.New("invalid query param, must be a valid string")
-		return
+
+	jsonDocumentID := body.Get("documentId")
+	if jsonDocumentID != nil && jsonDocumentID.Type() != fastjson.TypeNull {
+		documentIDBytes, errOut := jsonDocumentID.StringBytes()
+		if errOut != nil {
+			err = errors.New("invalid documentId param, must be a valid string")
+			return
+		}
+		req.documentID = string(documentIDBytes)
+	}
+
+	jsonQuery := body.Get("query")
+	if jsonQuery == nil || jsonQuery.Type() == fastjson.TypeNull {
+		// The query can be omitted if the request references a trusted document or persisted query
+		if len(req.documentID) == 0 && len(req.persistedQueryHash) == 0 {
+			err = errors.New("query should be defined")
+			return
+		}
+	} else {
+		queryBytes, errOut := jsonQuery.StringBytes()
+		if errOut != nil {
+			err = errors.New("invalid query param, must be a valid string")
+			return
+		}
+		req.query = string(queryBytes)
 	}
-	query = string(queryBytes)
 
 	jsonOperationName := body.Get("operationName")
 	if jsonOperationName != nil {
@@ -170,7 +224,7 @@ func getBodyData(body *fastjson.Value) (query, operationName, variables string,
 				err = errors.New("invalid operationName param, must be a valid string")
 				return
 			}
-			operationName = string(operationNameBytes)
+			req.operationName = string(operationNameBytes)
 		}
 	}
 
@@ -182,9 +236,44 @@ func getBodyData(body *fastjson.Value) (query, operationName, variables string,
 				err = errors.New("expected variables to be a key value object but got: " + t.String())
 				return
 			}
-			variables = jsonVariables.String()
+			req.variables = jsonVariables.String()
 		}
 	}
 
 	return
 }
+
+// getPersistedQueryHash returns the sha256 hash of a automatic persisted query from the request extensions
+// hash is empty if the extensions don't contain a persisted query
+func getPersistedQueryHash(extensions *fastjson.Value) (hash string, err error) {
+	t := extensions.Type()
+	if t == fastjson.TypeNull {
+		return "", nil
+	}
+	if t != fastjson.TypeObject {
+		return "", errors.New("expected extensions to be a key value object but got: " + t.String())
+	}
+
+	persistedQuery := extensions.Get("persistedQuery")
+	if persistedQuery == nil || persistedQuery.Type() == fastjson.TypeNull {
+		return "", nil
+	}
+	if persistedQuery.Type() != fastjson.TypeObject {
+		return "", errors.New("expected extensions.persistedQuery to be a key value object but got: " + persistedQuery.Type().String())
+	}
+
+	version := persistedQuery.Get("version")
+	if version != nil && (version.Type() != fastjson.TypeNumber || version.GetInt() != 1) {
+		return "", errors.New("unsupported persisted query version")
+	}
+
+	jsonHash := persistedQuery.Get("sha256Hash")
+	if jsonHash == nil {
+		return "", errors.New("extensions.persistedQuery.sha256Hash should be defined")
+	}
+	hashBytes, err := jsonHash.StringBytes()
+	if err != nil || len(hashBytes) == 0 {
+		return "", errors.New("invalid extensions.persistedQuery.sha256Hash, must be a valid string")
+	}
+	return string(hashBytes), nil
+}
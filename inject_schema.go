@@ -45,63 +45,34 @@ func (s *Schema) getQLSchema() qlSchema {
 		QueryType: &qlType{
 			Kind:        typeKindObject,
 			Name:        h.StrPtr(s.rootQuery.typeName),
-			Description: h.PtrToEmptyStr,
-			Fields: func(isDeprecatedArgs) []qlField {
-				fields, ok := s.graphqlObjFields[s.rootQuery.typeName]
-				if ok {
-					return fields
-				}
-
-				res := []qlField{}
-				for _, item := range s.rootQuery.objContents {
-					if item.hidden {
-						continue
-					}
-					res = append(res, qlField{
-						Name: string(item.qlFieldName),
-						Args: s.getObjectArgs(item),
-						Type: *wrapQLTypeInNonNull(s.objToQLType(item)),
-					})
-				}
-				sort.Slice(res, func(a int, b int) bool { return res[a].Name < res[b].Name })
-
-				s.graphqlObjFields[s.rootQuery.typeName] = res
-				return res
+			Description: qlDescription(s.rootQuery.description),
+			Fields: func(args isDeprecatedArgs) []qlField {
+				return filterDeprecatedFields(s.getObjFields(s.rootQuery), args)
 			},
 			Interfaces: []qlType{},
 		},
 		MutationType: &qlType{
 			Kind:        typeKindObject,
 			Name:        h.StrPtr(s.rootMethod.typeName),
-			Description: h.PtrToEmptyStr,
-			Fields: func(isDeprecatedArgs) []qlField {
-				fields, ok := s.graphqlObjFields[s.rootMethod.typeName]
-				if ok {
-					return fields
-				}
-
-				res := []qlField{}
-				for _, item := range s.rootMethod.objContents {
-					if item.hidden {
-						continue
-					}
-					res = append(res, qlField{
-						Name: string(item.qlFieldName),
-						Args: s.getObjectArgs(item),
-						Type: *wrapQLTypeInNonNull(s.objToQLType(item)),
-					})
-				}
-				sort.Slice(res, func(a int, b int) bool { return res[a].Name < res[b].Name })
-
-				s.graphqlObjFields[s.rootQuery.typeName] = res
-				return res
+			Description: qlDescription(s.rootMethod.description),
+			Fields: func(args isDeprecatedArgs) []qlField {
+				return filterDeprecatedFields(s.getObjFields(s.rootMethod), args)
 			},
 			Interfaces: []qlType{},
 		},
 	}
 
-	// TODO: We currently don't support subscriptions
-	res.SubscriptionType = nil
+	if s.rootSubscription != nil {
+		res.SubscriptionType = &qlType{
+			Kind:        typeKindObject,
+			Name:        h.StrPtr(s.rootSubscription.typeName),
+			Description: qlDescription(s.rootSubscription.description),
+			Fields: func(args isDeprecatedArgs) []qlField {
+				return filterDeprecatedFields(s.getObjFields(s.rootSubscription), args)
+			},
+			Interfaces: []qlType{},
+		}
+	}
 
 	return res
 }
@@ -128,11 +99,15 @@ func (s *Schema) getDirectives() []qlDirective {
 					}
 				}
 			}
+			// The directive arguments are parsed after the graphql types are injected so they have to be lazily generated
+			parsedMethod := directive.parsedMethod
 			res = append(res, qlDirective{
 				Name:        directive.Name,
 				Description: h.CheckStrPtr(directive.Description),
 				Locations:   locations,
-				Args:        s.getMethodArgs(directive.parsedMethod.inFields),
+				Args: func(args isDeprecatedArgs) []qlInputValue {
+					return filterDeprecatedInputValues(s.getMethodArgs(parsedMethod.inFields), args)
+				},
 			})
 		}
 	}
@@ -142,14 +117,59 @@ func (s *Schema) getDirectives() []qlDirective {
 	return res
 }
 
+// getObjFields returns the graphql fields of a object or interface
+// The fields are generated once and cached after that
+func (s *Schema) getObjFields(item *obj) []qlField {
+	s.graphqlTypesLock.Lock()
+	defer s.graphqlTypesLock.Unlock()
+
+	fields, ok := s.graphqlObjFields[item.typeName]
+	if ok {
+		return fields
+	}
+
+	fields = []qlField{}
+	for _, innerItem := range item.objContents {
+		if innerItem.hidden {
+			continue
+		}
+		fieldArgs := s.getObjectArgs(innerItem)
+		fields = append(fields, qlField{
+			Name:        string(innerItem.qlFieldName),
+			Description: qlDescription(innerItem.description),
+			Args: func(args isDeprecatedArgs) []qlInputValue {
+				return filterDeprecatedInputValues(fieldArgs, args)
+			},
+			Type:              *wrapQLTypeInNonNull(s.objToQLType(innerItem)),
+			IsDeprecated:      innerItem.deprecationReason != nil,
+			DeprecationReason: innerItem.deprecationReason,
+		})
+	}
+	sort.Slice(fields, func(a int, b int) bool { return fields[a].Name < fields[b].Name })
+
+	s.graphqlObjFields[item.typeName] = fields
+	return fields
+}
+
 func (s *Schema) getAllQLTypes() []qlType {
+	s.graphqlTypesLock.Lock()
+	defer s.graphqlTypesLock.Unlock()
+
+	return s.getAllQLTypesUnlocked()
+}
+
+// getAllQLTypesUnlocked returns all graphql types, expects the caller to hold s.graphqlTypesLock
+func (s *Schema) getAllQLTypesUnlocked() []qlType {
 	if s.graphqlTypesList == nil {
 		// Only generate s.graphqlTypesList once as the content won't change on runtime
 
 		s.graphqlTypesList = make(
 			[]qlType,
-			len(s.types)+len(s.inTypes)+len(s.definedEnums)+len(scalars)+len(s.interfaces),
+			len(s.types)+len(s.inTypes)+len(s.definedEnums)+len(scalars)+len(s.definedScalars)+len(s.interfaces),
 		)
+		if s.usesJSONScalar {
+			s.graphqlTypesList = append(s.graphqlTypesList, scalarJSON)
+		}
 
 		idx := 0
 		for _, qlType := range s.types {
@@ -170,6 +190,10 @@ func (s *Schema) getAllQLTypes() []qlType {
 			s.graphqlTypesList[idx] = scalar
 			idx++
 		}
+		for _, scalar := range s.definedScalars {
+			s.graphqlTypesList[idx] = scalar.qlType
+			idx++
+		}
 		for _, qlInterface := range s.interfaces {
 			obj, _ := s.objToQLType(qlInterface)
 			s.graphqlTypesList[idx] = *obj
@@ -183,10 +207,13 @@ func (s *Schema) getAllQLTypes() []qlType {
 }
 
 func (s *Schema) getTypeByName(name string) *qlType {
+	s.graphqlTypesLock.Lock()
+	defer s.graphqlTypesLock.Unlock()
+
 	if s.graphqlTypesMap == nil {
 		// Build up s.graphqlTypesMap
 		s.graphqlTypesMap = map[string]qlType{}
-		all := s.getAllQLTypes()
+		all := s.getAllQLTypesUnlocked()
 		for _, t := range all {
 			s.graphqlTypesMap[*t.Name] = t
 		}
@@ -221,30 +248,52 @@ func (s *Schema) inputToQLType(in *input) (res *qlType, isNonNull bool) {
 	} else if in.isFile {
 		res = &scalarFile
 		return
+	} else if in.isJSON {
+		res = &scalarJSON
+		return
+	} else if in.isScalar {
+		isNonNull = in.kind != reflect.Ptr
+		scalarType := s.definedScalars[in.scalarIndex].qlType
+		res = &scalarType
+		return
+	} else if in.isEnum {
+		isNonNull = true
+		enumType := s.definedEnums[in.enumTypeIndex].qlType
+		res = &enumType
+		return
 	}
 
 	switch in.kind {
 	case reflect.Struct:
 		isNonNull = true
 
+		description := in.description
+		inType, ok := s.inTypes[in.structName]
+		if ok {
+			description = inType.description
+		}
+
 		res = &qlType{
 			Kind:        typeKindInputObject,
 			Name:        h.StrPtr(in.structName),
-			Description: h.PtrToEmptyStr,
-			InputFields: func() []qlInputValue {
+			Description: qlDescription(description),
+			InputFields: func(args isDeprecatedArgs) []qlInputValue {
 				res := make([]qlInputValue, len(in.structContent))
 				i := 0
 				for key, item := range in.structContent {
 					res[i] = qlInputValue{
 						Name:         key,
-						Description:  h.PtrToEmptyStr,
+						Description:  qlDescription(item.description),
 						Type:         *wrapQLTypeInNonNull(s.inputToQLType(&item)),
-						DefaultValue: nil, // We do not support this atm
+						DefaultValue: item.defaultValue,
+
+						IsDeprecated:      item.deprecationReason != nil,
+						DeprecationReason: item.deprecationReason,
 					}
 					i++
 				}
 				sort.Slice(res, func(a int, b int) bool { return res[a].Name < res[b].Name })
-				return res
+				return filterDeprecatedInputValues(res, args)
 			},
 		}
 	case reflect.Array, reflect.Slice:
@@ -294,9 +343,12 @@ func (s *Schema) getMethodArgs(inputs map[string]referToInput) []qlInputValue {
 	for key, value := range inputs {
 		res = append(res, qlInputValue{
 			Name:         key,
-			Description:  h.PtrToEmptyStr,
+			Description:  qlDescription(value.input.description),
 			Type:         *wrapQLTypeInNonNull(s.inputToQLType(&value.input)),
-			DefaultValue: nil,
+			DefaultValue: value.input.defaultValue,
+
+			IsDeprecated:      value.input.deprecationReason != nil,
+			DeprecationReason: value.input.deprecationReason,
 		})
 	}
 	sort.Slice(res, func(a int, b int) bool { return res[a].Name < res[b].Name })
@@ -323,6 +375,10 @@ func (s *Schema) objToQLType(item *obj) (res *qlType, isNonNull bool) {
 		if len(item.implementations) != 0 {
 			for _, implementation := range item.implementations {
 				interfaceType, _ := s.objToQLType(implementation)
+				if interfaceType.Kind == typeKindUnion {
+					// Unions are not implemented by the object, the object is a member of the union
+					continue
+				}
 				interfaces = append(interfaces, *interfaceType)
 			}
 		}
@@ -330,28 +386,9 @@ func (s *Schema) objToQLType(item *obj) (res *qlType, isNonNull bool) {
 		res = &qlType{
 			Kind:        typeKindObject,
 			Name:        &item.typeName,
-			Description: h.PtrToEmptyStr,
+			Description: qlDescription(item.description),
 			Fields: func(args isDeprecatedArgs) []qlField {
-				fields, ok := s.graphqlObjFields[item.typeName]
-				if ok {
-					return fields
-				}
-
-				res := []qlField{}
-				for _, innerItem := range item.objContents {
-					if innerItem.hidden {
-						continue
-					}
-					res = append(res, qlField{
-						Name: string(innerItem.qlFieldName),
-						Args: s.getObjectArgs(innerItem),
-						Type: *wrapQLTypeInNonNull(s.objToQLType(innerItem)),
-					})
-				}
-				sort.Slice(res, func(a int, b int) bool { return res[a].Name < res[b].Name })
-
-				s.graphqlObjFields[item.typeName] = res
-				return res
+				return filterDeprecatedFields(s.getObjFields(item), args)
 			},
 			Interfaces: interfaces,
 		}
@@ -360,10 +397,18 @@ func (s *Schema) objToQLType(item *obj) (res *qlType, isNonNull bool) {
 		enumType := s.definedEnums[item.enumTypeIndex].qlType
 		res = &enumType
 		return res, true
+	case valueTypeScalar:
+		scalar := s.definedScalars[item.scalarIndex]
+		scalarType := scalar.qlType
+		return &scalarType, scalar.goType.Kind() != reflect.Ptr
 	case valueTypePtr:
 		// This basically sets the isNonNull to false
 		res, _ := s.objToQLType(item.innerContent)
 		return res, false
+	case valueTypeJSON:
+		// Maps can be nil
+		jsonType := scalarJSON
+		return &jsonType, false
 	case valueTypeMethod:
 		res, isNonNull = s.objToQLType(&item.method.outType)
 		if !item.method.isTypeMethod {
@@ -376,40 +421,33 @@ func (s *Schema) objToQLType(item *obj) (res *qlType, isNonNull bool) {
 		// A interface should be non null BUT as a interface in go can be nil we set it to false
 		isNonNull = false
 
-		res = &qlType{
-			Kind:        typeKindInterface,
-			Name:        &item.typeName,
-			Description: h.PtrToEmptyStr,
-			Interfaces:  []qlType{},
-			PossibleTypes: func() []qlType {
-				possibleTypes := make([]qlType, len(item.implementations))
-				for idx, implementation := range item.implementations {
-					item, _ := s.objToQLType(implementation)
-					possibleTypes[idx] = *item
-				}
-				return possibleTypes
-			},
-			Fields: func(args isDeprecatedArgs) []qlField {
-				fields, ok := s.graphqlObjFields[item.typeName]
-				if ok {
-					return fields
-				}
+		possibleTypes := func() []qlType {
+			possibleTypes := make([]qlType, len(item.implementations))
+			for idx, implementation := range item.implementations {
+				item, _ := s.objToQLType(implementation)
+				possibleTypes[idx] = *item
+			}
+			return possibleTypes
+		}
 
-				res := []qlField{}
-				for _, innerItem := range item.objContents {
-					if item.hidden {
-						continue
-					}
-					res = append(res, qlField{
-						Name: string(innerItem.qlFieldName),
-						Args: s.getObjectArgs(innerItem),
-						Type: *wrapQLTypeInNonNull(s.objToQLType(innerItem)),
-					})
-				}
-				sort.Slice(res, func(a int, b int) bool { return res[a].Name < res[b].Name })
+		if item.isUnion {
+			res = &qlType{
+				Kind:          typeKindUnion,
+				Name:          &item.typeName,
+				Description:   qlDescription(item.description),
+				PossibleTypes: possibleTypes,
+			}
+			return
+		}
 
-				s.graphqlObjFields[item.typeName] = res
-				return res
+		res = &qlType{
+			Kind:          typeKindInterface,
+			Name:          &item.typeName,
+			Description:   qlDescription(item.description),
+			Interfaces:    []qlType{},
+			PossibleTypes: possibleTypes,
+			Fields: func(args isDeprecatedArgs) []qlField {
+				return filterDeprecatedFields(s.getObjFields(item), args)
 			},
 		}
 		return
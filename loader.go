@@ -0,0 +1,260 @@
+package yarql
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Loaders batch the loading of data for fields inside lists to prevent the N+1 problem
+//
+// A resolver that uses a loader returns a function instead of the value itself,
+// inside lists these functions are called after all list items are resolved
+// so all keys loaded by the list items can be loaded using a single batch call
+//
+// Example:
+//
+//	var postsLoader = yarql.NewLoader(func(keys []interface{}) ([]interface{}, []error) {
+//		// Load the posts of all users using a single database query
+//	})
+//
+//	func (u User) ResolvePosts(ctx *yarql.Ctx) func() ([]Post, error) {
+//		load := postsLoader.Load(ctx, u.ID)
+//		return func() ([]Post, error) {
+//			posts, err := load()
+//			if err != nil {
+//				return nil, err
+//			}
+//			return posts.([]Post), nil
+//		}
+//	}
+
+// BatchFunc loads the values of multiple keys at once
+// The returned values must have the same length and order as the keys,
+// errs can be nil or must have the same length as the keys
+type BatchFunc func(keys []interface{}) (values []interface{}, errs []error)
+
+// Loader batches and caches the loading of values by their key
+// The loaded values are cached for the duration of a single request
+type Loader struct {
+	batch BatchFunc
+}
+
+// NewLoader creates a new loader, loaders are usually created once and stored in a global variable
+// The state of the loader, like the cached values, is stored per request inside the *yarql.Ctx
+func NewLoader(batch BatchFunc) *Loader {
+	return &Loader{batch: batch}
+}
+
+// loaderState is the state of a loader within a single request
+type loaderState struct {
+	loader  *Loader
+	results map[interface{}]*loaderResult
+	pending []interface{} // keys that are not yet loaded
+}
+
+type loaderResult struct {
+	loaded bool
+	value  interface{}
+	err    error
+}
+
+// Load queues a key to be loaded, the returned function returns the value of the key
+// Calling the returned function loads all queued keys of this loader using a single batch call if the value is not yet loaded
+//
+// Keys must be comparable, see https://golang.org/ref/spec#Comparison_operators
+func (l *Loader) Load(ctx *Ctx, key interface{}) func() (interface{}, error) {
+	state := ctx.loaderState(l)
+
+	result, ok := state.results[key]
+	if !ok {
+		result = &loaderResult{}
+		state.results[key] = result
+		state.pending = append(state.pending, key)
+	}
+
+	return func() (interface{}, error) {
+		if !result.loaded {
+			state.dispatch()
+		}
+		return result.value, result.err
+	}
+}
+
+func (ctx *Ctx) loaderState(l *Loader) *loaderState {
+	if ctx.loaders == nil {
+		ctx.loaders = map[*Loader]*loaderState{}
+	}
+	state, ok := ctx.loaders[l]
+	if !ok {
+		state = &loaderState{
+			loader:  l,
+			results: map[interface{}]*loaderResult{},
+		}
+		ctx.loaders[l] = state
+	}
+	return state
+}
+
+// dispatch loads all pending keys
+func (s *loaderState) dispatch() {
+	keys := s.pending
+	s.pending = nil
+	if len(keys) == 0 {
+		return
+	}
+
+	values, errs := s.loader.batch(keys)
+
+	var err error
+	if len(values) != len(keys) {
+		err = fmt.Errorf("loader returned %d values for %d keys", len(values), len(keys))
+	} else if errs != nil && len(errs) != len(keys) {
+		err = fmt.Errorf("loader returned %d errors for %d keys", len(errs), len(keys))
+	}
+
+	for idx, key := range keys {
+		result := s.results[key]
+		result.loaded = true
+		if err != nil {
+			result.err = err
+			continue
+		}
+		result.value = values[idx]
+		if errs != nil {
+			result.err = errs[idx]
+		}
+	}
+}
+
+// checkThunk checks if a function returned by a resolver can be used as a thunk
+// A thunk takes no arguments and returns a value and optionally an error
+func checkThunk(t reflect.Type) (hasError bool, err error) {
+	if t.NumIn() != 0 {
+		return false, errors.New("returned function cannot have arguments")
+	}
+
+	switch t.NumOut() {
+	case 1:
+		hasError = false
+	case 2:
+		errInterface := reflect.TypeOf((*error)(nil)).Elem()
+		if t.Out(1).Kind() != reflect.Interface || !t.Out(1).Implements(errInterface) {
+			return false, errors.New("the second value of the returned function must be an error")
+		}
+		hasError = true
+	default:
+		return false, errors.New("returned function must return a value and optionally an error")
+	}
+
+	if t.Out(0).Kind() == reflect.Func || t.Out(0).Kind() == reflect.Chan {
+		return false, errors.New("returned function cannot return a function or channel")
+	}
+	return hasError, nil
+}
+
+// deferredField is a field that is resolved after all sibling list items are resolved
+type deferredField struct {
+	resultIdx int // The location in ctx.result where the value should be inserted
+	charNr    int
+	field     int // The Res index of the field, see ctx.currentField
+	nullable  int // The length of the path of the nearest nullable value, see ctx.nullablePathLen
+	dept      uint8
+	path      []byte
+	method    *objMethod
+	thunk     reflect.Value
+}
+
+// deferField stores a field that returned a thunk so it can be resolved after all sibling list items are resolved
+func (ctx *Ctx) deferField(method *objMethod, thunk reflect.Value, dept uint8) {
+	ctx.deferredFields = append(ctx.deferredFields, deferredField{
+		resultIdx: len(ctx.result),
+		charNr:    ctx.charNr,
+		field:     ctx.currentField,
+		nullable:  ctx.nullablePathLen,
+		dept:      dept,
+		path:      append([]byte{}, ctx.path...),
+		method:    method,
+		thunk:     thunk,
+	})
+}
+
+// resolveThunk calls the thunk returned by a method and resolves the returned value
+func (ctx *Ctx) resolveThunk(method *objMethod, thunk reflect.Value, dept uint8) bool {
+	if thunk.IsNil() {
+		ctx.writeNull()
+		return false
+	}
+
+	outs := thunk.Call(nil)
+	if method.thunkHasError && !outs[1].IsNil() {
+		err, ok := outs[1].Interface().(error)
+		if !ok {
+			ctx.writeNull()
+			return ctx.err("returned a invalid kind of error")
+		} else if err != nil {
+			ctx.addErr(err)
+			ctx.writeNull()
+			return false
+		}
+	}
+
+	ctx.setGoValue(outs[0])
+	return ctx.resolveFieldDataValue(&method.outType, dept, ctx.seekInst() != 'e')
+}
+
+// resolveDeferredFields resolves all fields deferred since ctx.deferredFields had the length start
+// The resolved values are inserted into the result at the location the field would normally be written
+func (ctx *Ctx) resolveDeferredFields(start int) bool {
+	if len(ctx.deferredFields) == start {
+		return false
+	}
+
+	originalCharNr := ctx.charNr
+	originalPath := ctx.path
+	originalField := ctx.currentField
+	originalNullablePathLen := ctx.nullablePathLen
+	originalDeferring := ctx.deferring
+	ctx.deferring = false
+	ctx.currentReflectValueIdx++
+
+	inserted := 0
+	var criticalErr bool
+	for i := start; i < len(ctx.deferredFields); i++ {
+		field := ctx.deferredFields[i]
+
+		ctx.charNr = field.charNr
+		ctx.path = field.path
+		ctx.currentField = field.field
+		ctx.nullablePathLen = field.nullable
+
+		valueStart := len(ctx.result)
+		criticalErr = ctx.resolveThunk(field.method, field.thunk, field.dept)
+		if field.method.isTypeMethod && ctx.schema.isNonNullOutput(&field.method.outType) {
+			ctx.checkNonNullValue(valueStart)
+		}
+
+		// Move the value written at the end of the result to the location of the field
+		valueLen := len(ctx.result) - valueStart
+		insertAt := field.resultIdx + inserted
+		if valueLen > 0 && insertAt < valueStart {
+			value := append([]byte{}, ctx.result[valueStart:]...)
+			copy(ctx.result[insertAt+valueLen:], ctx.result[insertAt:valueStart])
+			copy(ctx.result[insertAt:], value)
+		}
+		inserted += valueLen
+
+		if criticalErr {
+			break
+		}
+	}
+
+	ctx.currentReflectValueIdx--
+	ctx.deferredFields = ctx.deferredFields[:start]
+	ctx.deferring = originalDeferring
+	ctx.path = originalPath
+	ctx.currentField = originalField
+	ctx.nullablePathLen = originalNullablePathLen
+	ctx.charNr = originalCharNr
+	return criticalErr
+}
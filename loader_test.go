@@ -0,0 +1,174 @@
+package yarql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+type TestLoaderData struct {
+	Users []TestLoaderUser
+	User  TestLoaderUser
+}
+
+type TestLoaderUser struct {
+	ID   int
+	Name string
+}
+
+type TestLoaderPost struct {
+	Title string
+}
+
+var testLoaderBatches [][]interface{}
+
+var testLoaderPosts = NewLoader(func(keys []interface{}) ([]interface{}, []error) {
+	testLoaderBatches = append(testLoaderBatches, keys)
+
+	values := make([]interface{}, len(keys))
+	errs := make([]error, len(keys))
+	for idx, key := range keys {
+		id := key.(int)
+		if id == 3 {
+			errs[idx] = errors.New("user 3 has no posts")
+			continue
+		}
+		posts := []TestLoaderPost{}
+		for i := 0; i < id; i++ {
+			posts = append(posts, TestLoaderPost{Title: string(rune('a' + i))})
+		}
+		values[idx] = posts
+	}
+	return values, errs
+})
+
+func (u TestLoaderUser) ResolvePosts(ctx *Ctx) func() ([]TestLoaderPost, error) {
+	load := testLoaderPosts.Load(ctx, u.ID)
+	return func() ([]TestLoaderPost, error) {
+		posts, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return posts.([]TestLoaderPost), nil
+	}
+}
+
+func (u TestLoaderUser) ResolvePostsCount(ctx *Ctx) func() int {
+	load := testLoaderPosts.Load(ctx, u.ID)
+	return func() int {
+		posts, _ := load()
+		if posts == nil {
+			return 0
+		}
+		return len(posts.([]TestLoaderPost))
+	}
+}
+
+func TestLoaderBatchesListItems(t *testing.T) {
+	testLoaderBatches = nil
+
+	data := TestLoaderData{
+		Users: []TestLoaderUser{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}, {ID: 0, Name: "baz"}},
+	}
+	res := bytecodeParseAndExpectNoErrs(t, `{users {name posts {title} postsCount}}`, data, M{})
+	a.Equal(t, `{"users":[`+
+		`{"name":"foo","posts":[{"title":"a"}],"postsCount":1},`+
+		`{"name":"bar","posts":[{"title":"a"},{"title":"b"}],"postsCount":2},`+
+		`{"name":"baz","posts":[],"postsCount":0}`+
+		`]}`, res)
+
+	// All posts are loaded using a single batch and the second field uses the cached values
+	a.Equal(t, 1, len(testLoaderBatches))
+	a.Equal(t, []interface{}{1, 2, 0}, testLoaderBatches[0])
+}
+
+func TestLoaderOutsideList(t *testing.T) {
+	testLoaderBatches = nil
+
+	data := TestLoaderData{
+		User: TestLoaderUser{ID: 1, Name: "foo"},
+	}
+	res := bytecodeParseAndExpectNoErrs(t, `{user {posts {title}}}`, data, M{})
+	a.Equal(t, `{"user":{"posts":[{"title":"a"}]}}`, res)
+	a.Equal(t, 1, len(testLoaderBatches))
+}
+
+func TestLoaderCacheIsScopedToRequest(t *testing.T) {
+	testLoaderBatches = nil
+
+	s := NewSchema()
+	err := s.Parse(TestLoaderData{
+		Users: []TestLoaderUser{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}, {ID: 0, Name: "baz"}},
+		User:  TestLoaderUser{ID: 1, Name: "foo"},
+	}, M{}, nil)
+	a.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		errs := s.Resolve([]byte(`{users {postsCount} user {postsCount}}`), ResolveOptions{NoMeta: true})
+		a.Equal(t, 0, len(errs))
+		a.Equal(t, `{"users":[{"postsCount":1},{"postsCount":2},{"postsCount":0}],"user":{"postsCount":1}}`, string(s.Result))
+	}
+	a.Equal(t, 2, len(testLoaderBatches))
+}
+
+func TestLoaderErrors(t *testing.T) {
+	data := TestLoaderData{
+		Users: []TestLoaderUser{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}, {ID: 0, Name: "baz"}, {ID: 3, Name: "qux"}},
+	}
+
+	res, errs := bytecodeParse(t, NewSchema(), `{users {name posts {title}}}`, data, M{}, ResolveOptions{})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "user 3 has no posts", errs[0].Error())
+	a.Equal(t, `{"data":{"users":[`+
+		`{"name":"foo","posts":[{"title":"a"}]},`+
+		`{"name":"bar","posts":[{"title":"a"},{"title":"b"}]},`+
+		`{"name":"baz","posts":[]},`+
+		`{"name":"qux","posts":null}`+
+		`]},"errors":[{"message":"user 3 has no posts","locations":[{"line":1,"column":14}],"path":["users",3,"posts"]}],"extensions":{}}`, res)
+}
+
+type TestLoaderInvalidThunk struct{}
+
+func (TestLoaderInvalidThunk) ResolveFoo() func(a int) string {
+	return nil
+}
+
+type TestLoaderInvalidThunkError struct{}
+
+func (TestLoaderInvalidThunkError) ResolveFoo() func() (string, int) {
+	return nil
+}
+
+func TestLoaderInvalidThunks(t *testing.T) {
+	err := NewSchema().Parse(TestLoaderInvalidThunk{}, M{}, nil)
+	a.Error(t, err)
+
+	err = NewSchema().Parse(TestLoaderInvalidThunkError{}, M{}, nil)
+	a.Error(t, err)
+}
+
+func TestLoaderWithContentModifier(t *testing.T) {
+	s := NewSchema()
+	s.RegisterDirective(Directive{
+		Name:  "uppercase",
+		Where: []DirectiveLocation{DirectiveLocationField},
+		Method: func() DirectiveModifier {
+			return DirectiveModifier{
+				ModifyOnWriteContent: func(bytes []byte, value interface{}) []byte {
+					return []byte(strings.ToUpper(string(bytes)))
+				},
+			}
+		},
+	})
+
+	data := TestLoaderData{
+		Users: []TestLoaderUser{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}, {ID: 0, Name: "baz"}},
+	}
+
+	// Fields with a content modifier are not deferred so the modifier sees the resolved value
+	res, errs := bytecodeParse(t, s, `{users {name posts @uppercase {title}}}`, data, M{}, ResolveOptions{NoMeta: true})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"users":[{"name":"foo","posts":[{"TITLE":"A"}]},{"name":"bar","posts":[{"TITLE":"A"},{"TITLE":"B"}]},{"name":"baz","posts":[]}]}`, res)
+}
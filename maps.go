@@ -0,0 +1,287 @@
+package yarql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mjarkk/yarql/bytecode"
+	"github.com/valyala/fastjson"
+)
+
+// MapStrategy defines how map[string]T types are exposed in the graphql schema, see SchemaOptions.MapStrategy
+type MapStrategy uint8
+
+const (
+	// MapAsKeyValueList exposes maps as a list of {key, value} objects sorted by key
+	// The list type is generated based on the value type, for example map[string]int becomes [KeyValueInt!] and the input [KeyValueIntInput!]
+	MapAsKeyValueList MapStrategy = iota
+	// MapAsJSON exposes maps as the JSON scalar, maps are written and read as is using encoding/json
+	MapAsJSON
+)
+
+// checkMap checks a output map type
+func (c *parseCtx) checkMap(t reflect.Type) (*obj, error) {
+	if t.Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("unsupported map key type %s, only maps with string keys are supported", t.Key().String())
+	}
+
+	if c.mapStrategy == MapAsJSON {
+		err := c.useJSONScalar()
+		if err != nil {
+			return nil, err
+		}
+		return &obj{
+			valueType:     valueTypeJSON,
+			typeName:      "JSON",
+			typeNameBytes: []byte("JSON"),
+			goTypeName:    t.Name(),
+			goPkgPath:     t.PkgPath(),
+		}, nil
+	}
+
+	keyObj, err := c.check(t.Key(), false)
+	if err != nil {
+		return nil, err
+	}
+	valueObj, err := c.check(t.Elem(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	keyTypeName := bytes.NewBuffer(nil)
+	c.schema.objToQlTypeName(keyObj, keyTypeName)
+	valueTypeName := bytes.NewBuffer(nil)
+	c.schema.objToQlTypeName(valueObj, valueTypeName)
+
+	typeName := "KeyValue" + mapValueTypeName(valueTypeName.String())
+	shape := keyTypeName.String() + ":" + valueTypeName.String()
+	entryType := reflect.StructOf([]reflect.StructField{
+		{Name: "Key", Type: t.Key()},
+		{Name: "Value", Type: t.Elem()},
+	})
+	res := obj{
+		valueType:    valueTypeArray,
+		mapEntryType: entryType,
+	}
+
+	existingShape, ok := c.keyValueTypes[typeName]
+	if ok {
+		if existingShape != shape {
+			return nil, fmt.Errorf("the map type %s generates the type %s that already exists with different key or value types", t.String(), typeName)
+		}
+		entryRef := c.schema.types[typeName].getRef()
+		res.innerContent = &entryRef
+		return &res, nil
+	}
+	if _, ok = c.schema.types[typeName]; ok {
+		return nil, fmt.Errorf("the map type %s generates the type %s that is already defined", t.String(), typeName)
+	}
+	if c.keyValueTypes == nil {
+		c.keyValueTypes = map[string]string{}
+	}
+	c.keyValueTypes[typeName] = shape
+
+	keyObj.qlFieldName = []byte("key")
+	keyObj.structFieldIdx = 0
+	valueObj.qlFieldName = []byte("value")
+	valueObj.structFieldIdx = 1
+
+	entryRef := c.schema.types.Add(obj{
+		valueType:     valueTypeObj,
+		typeName:      typeName,
+		typeNameBytes: []byte(typeName),
+		goTypeName:    typeName,
+		description:   "A entry of a map with the value type " + valueTypeName.String(),
+		objContents: map[uint32]*obj{
+			getObjKey(keyObj.qlFieldName):   keyObj,
+			getObjKey(valueObj.qlFieldName): valueObj,
+		},
+	})
+	res.innerContent = &entryRef
+	return &res, nil
+}
+
+// checkMapInput checks a input map type
+func (c *parseCtx) checkMapInput(t reflect.Type) (input, error) {
+	if t.Key().Kind() != reflect.String {
+		return input{}, fmt.Errorf("unsupported map key type %s, only maps with string keys are supported", t.Key().String())
+	}
+
+	if c.mapStrategy == MapAsJSON {
+		err := c.useJSONScalar()
+		if err != nil {
+			return input{}, err
+		}
+		return input{
+			kind:   reflect.Map,
+			isJSON: true,
+		}, nil
+	}
+
+	keyInput, err := c.checkFunctionInput(t.Key(), false)
+	if err != nil {
+		return input{}, err
+	}
+	valueInput, err := c.checkFunctionInput(t.Elem(), false)
+	if err != nil {
+		return input{}, err
+	}
+
+	keyTypeName := bytes.NewBuffer(nil)
+	c.schema.inputToQlTypeName(&keyInput, keyTypeName)
+	valueTypeName := bytes.NewBuffer(nil)
+	c.schema.inputToQlTypeName(&valueInput, valueTypeName)
+
+	structName := "KeyValue" + mapValueTypeName(valueTypeName.String()) + "Input"
+	shape := keyTypeName.String() + ":" + valueTypeName.String()
+	res := input{
+		kind: reflect.Slice,
+		elem: &input{
+			kind:             reflect.Struct,
+			structName:       structName,
+			isStructPointers: true,
+		},
+		mapEntryType: reflect.StructOf([]reflect.StructField{
+			{Name: "Key", Type: t.Key()},
+			{Name: "Value", Type: t.Elem()},
+		}),
+	}
+
+	existingShape, ok := c.keyValueTypes[structName]
+	if ok {
+		if existingShape != shape {
+			return input{}, fmt.Errorf("the map type %s generates the input %s that already exists with different key or value types", t.String(), structName)
+		}
+		return res, nil
+	}
+	if _, ok = c.schema.inTypes[structName]; ok {
+		return input{}, fmt.Errorf("the map type %s generates the input %s that is already defined", t.String(), structName)
+	}
+	if c.keyValueTypes == nil {
+		c.keyValueTypes = map[string]string{}
+	}
+	c.keyValueTypes[structName] = shape
+
+	keyInput.goFieldIdx = 0
+	keyInput.gqFieldName = "key"
+	valueInput.goFieldIdx = 1
+	valueInput.gqFieldName = "value"
+	c.schema.inTypes[structName] = &input{
+		kind:        reflect.Struct,
+		structName:  structName,
+		description: "A entry of a map with the value type " + valueTypeName.String(),
+		structContent: map[string]input{
+			"key":   keyInput,
+			"value": valueInput,
+		},
+	}
+
+	return res, nil
+}
+
+// useJSONScalar adds the JSON scalar to the schema
+func (c *parseCtx) useJSONScalar() error {
+	for _, scalar := range c.schema.definedScalars {
+		if scalar.typeName == "JSON" {
+			return errors.New("maps cannot be exposed as the JSON scalar as a custom scalar named JSON is registered")
+		}
+	}
+	c.schema.usesJSONScalar = true
+	return nil
+}
+
+// mapValueTypeName converts the graphql type of a map value into the name used in the generated key value type
+// For example [Int!]! becomes IntList
+func mapValueTypeName(qlTypeName string) string {
+	name := strings.NewReplacer("!", "", "[", "").Replace(qlTypeName)
+	listsCount := strings.Count(name, "]")
+	return strings.ReplaceAll(name, "]", "") + strings.Repeat("List", listsCount)
+}
+
+// mapToEntries converts a map into a slice of key value entries sorted by key
+func mapToEntries(goValue reflect.Value, entryType reflect.Type) reflect.Value {
+	keys := goValue.MapKeys()
+	sort.Slice(keys, func(a int, b int) bool { return keys[a].String() < keys[b].String() })
+
+	entries := reflect.MakeSlice(reflect.SliceOf(entryType), len(keys), len(keys))
+	for idx, key := range keys {
+		entry := entries.Index(idx)
+		entry.Field(0).Set(key)
+		entry.Field(1).Set(goValue.MapIndex(key))
+	}
+	return entries
+}
+
+// bindMapInput binds a list of key value entries to the map goValue
+// bind binds the input to the entries slice it receives
+func (ctx *Ctx) bindMapInput(goValue *reflect.Value, valueStructure *input, bind func(entries *reflect.Value, entriesStructure *input) (valueSet bool, criticalErr bool)) (valueSet bool, criticalErr bool) {
+	entriesStructure := *valueStructure
+	entriesStructure.mapEntryType = nil
+
+	entries := reflect.New(reflect.SliceOf(valueStructure.mapEntryType)).Elem()
+	valueSet, criticalErr = bind(&entries, &entriesStructure)
+	if criticalErr || !valueSet || entries.IsNil() {
+		return false, criticalErr
+	}
+
+	entriesLen := entries.Len()
+	mapValue := reflect.MakeMapWithSize(goValue.Type(), entriesLen)
+	for i := 0; i < entriesLen; i++ {
+		entry := entries.Index(i)
+		mapValue.SetMapIndex(entry.Field(0), entry.Field(1))
+	}
+	goValue.Set(mapValue)
+	return true, false
+}
+
+// resolveJSONValue writes a go value using encoding/json
+func (ctx *Ctx) resolveJSONValue(goValue reflect.Value) {
+	if goValue.IsNil() {
+		ctx.writeNull()
+		return
+	}
+
+	value, err := json.Marshal(goValue.Interface())
+	if err != nil {
+		ctx.writeNull()
+		ctx.addErr(err)
+		return
+	}
+	ctx.write(value)
+}
+
+// bindJSONScalarValue binds the JSON scalar value in the query to goValue
+// Expects ctx.charNr to be at the start of the value and moves ctx.charNr to the instruction after the value
+func (ctx *Ctx) bindJSONScalarValue(goValue *reflect.Value) (valueSet bool, criticalErr bool) {
+	start := ctx.charNr - 1
+	ctx.charNr = start + 8 + int(ctx.readUint32(start+3))
+
+	if ctx.query.Res[start+2] == bytecode.ValueNull {
+		// keep goValue at it's default
+		return false, false
+	}
+
+	ctx.scalarArena.Reset()
+	return ctx.assignJSONValue(goValue, ctx.bytecodeValueToJSON(start))
+}
+
+// assignJSONValue assigns a value of the JSON scalar to goValue using encoding/json
+func (ctx *Ctx) assignJSONValue(goValue *reflect.Value, jsonData *fastjson.Value) (valueSet bool, criticalErr bool) {
+	if jsonData.Type() == fastjson.TypeNull {
+		// keep goValue at it's default
+		return false, false
+	}
+
+	value := reflect.New(goValue.Type())
+	err := json.Unmarshal(jsonData.MarshalTo(nil), value.Interface())
+	if err != nil {
+		return false, ctx.err(err.Error())
+	}
+	goValue.Set(value.Elem())
+	return true, false
+}
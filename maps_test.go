@@ -0,0 +1,122 @@
+package yarql
+
+import (
+	"strings"
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+type TestMapsData struct {
+	Labels   map[string]string
+	Counts   map[string]int
+	Children map[string]TestMapsChild
+	Nil      map[string]string
+}
+
+type TestMapsChild struct {
+	Name string
+}
+
+type TestMapsMethods struct{}
+
+func (TestMapsMethods) ResolveSetLabels(args struct {
+	Labels map[string]string
+}) map[string]string {
+	return args.Labels
+}
+
+func TestMapsKeyValueOutput(t *testing.T) {
+	data := TestMapsData{
+		Labels:   map[string]string{"b": "2", "a": "1"},
+		Counts:   map[string]int{"x": 1},
+		Children: map[string]TestMapsChild{"child": {Name: "foo"}},
+	}
+	res, errs := bytecodeParse(t, NewSchema(), `{labels {key value} counts {key value} children {key value {name}} nil {key}}`, data, TestMapsMethods{})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"labels":[{"key":"a","value":"1"},{"key":"b","value":"2"}],"counts":[{"key":"x","value":1}],"children":[{"key":"child","value":{"name":"foo"}}],"nil":null}`, res)
+}
+
+func TestMapsKeyValueInput(t *testing.T) {
+	query := `mutation {setLabels(labels: [{key: "b", value: "2"}, {key: "a", value: "1"}]) {key value}}`
+	res, errs := bytecodeParse(t, NewSchema(), query, TestMapsData{}, TestMapsMethods{})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"setLabels":[{"key":"a","value":"1"},{"key":"b","value":"2"}]}`, res)
+
+	query = `mutation($labels: [KeyValueStringInput!]) {setLabels(labels: $labels) {key value}}`
+	res, errs = bytecodeParse(t, NewSchema(), query, TestMapsData{}, TestMapsMethods{}, ResolveOptions{
+		NoMeta:    true,
+		Variables: `{"labels": [{"key": "c", "value": "3"}]}`,
+	})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"setLabels":[{"key":"c","value":"3"}]}`, res)
+}
+
+func TestMapsJSONOutput(t *testing.T) {
+	s := NewSchema()
+	err := s.Parse(TestMapsData{
+		Labels:   map[string]string{"b": "2", "a": "1"},
+		Counts:   map[string]int{"x": 1},
+		Children: map[string]TestMapsChild{"child": {Name: "foo"}},
+	}, TestMapsMethods{}, &SchemaOptions{MapStrategy: MapAsJSON})
+	a.NoError(t, err)
+
+	// Copy so we also test if the map strategy is copied over
+	s = s.Copy()
+	errs := s.Resolve([]byte(`{labels counts children nil}`), ResolveOptions{NoMeta: true})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"labels":{"a":"1","b":"2"},"counts":{"x":1},"children":{"child":{"Name":"foo"}},"nil":null}`, string(s.Result))
+}
+
+func TestMapsJSONInput(t *testing.T) {
+	s := NewSchema()
+	err := s.Parse(TestMapsData{}, TestMapsMethods{}, &SchemaOptions{MapStrategy: MapAsJSON})
+	a.NoError(t, err)
+
+	// Copy so we also test if the map strategy is copied over
+	s = s.Copy()
+	errs := s.Resolve([]byte(`mutation {setLabels(labels: {a: "1", b: "2"})}`), ResolveOptions{NoMeta: true})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"setLabels":{"a":"1","b":"2"}}`, string(s.Result))
+
+	errs = s.Resolve([]byte(`mutation($labels: JSON) {setLabels(labels: $labels)}`), ResolveOptions{
+		NoMeta:    true,
+		Variables: `{"labels": {"c": "3"}}`,
+	})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"setLabels":{"c":"3"}}`, string(s.Result))
+
+	errs = s.Resolve([]byte(`mutation {setLabels(labels: {a: 1})}`), ResolveOptions{NoMeta: true})
+	a.Equal(t, 1, len(errs))
+}
+
+func TestMapsSchema(t *testing.T) {
+	s := NewSchema()
+	err := s.Parse(TestMapsData{}, TestMapsMethods{}, nil)
+	a.NoError(t, err)
+	sdl, err := s.SDL()
+	a.NoError(t, err)
+	a.True(t, strings.Contains(sdl, "  labels: [KeyValueString!]\n"), sdl)
+	a.True(t, strings.Contains(sdl, "type KeyValueString {\n"), sdl)
+	a.True(t, strings.Contains(sdl, "  setLabels(labels: [KeyValueStringInput!]): [KeyValueString!]\n"), sdl)
+	a.True(t, strings.Contains(sdl, "input KeyValueStringInput {\n"), sdl)
+	a.False(t, strings.Contains(sdl, "scalar JSON"), sdl)
+
+	s = NewSchema()
+	err = s.Parse(TestMapsData{}, TestMapsMethods{}, &SchemaOptions{MapStrategy: MapAsJSON})
+	a.NoError(t, err)
+	sdl, err = s.SDL()
+	a.NoError(t, err)
+	a.True(t, strings.Contains(sdl, "  labels: JSON\n"), sdl)
+	a.True(t, strings.Contains(sdl, "  setLabels(labels: JSON): JSON\n"), sdl)
+	a.True(t, strings.Contains(sdl, "scalar JSON"), sdl)
+	a.False(t, strings.Contains(sdl, "KeyValue"), sdl)
+}
+
+func TestMapsUnsupportedKey(t *testing.T) {
+	type Query struct {
+		Values map[int]string
+	}
+	err := NewSchema().Parse(Query{}, M{}, nil)
+	a.Error(t, err)
+}
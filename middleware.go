@@ -0,0 +1,306 @@
+package yarql
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/mjarkk/yarql/bytecode"
+	"github.com/valyala/fastjson"
+)
+
+// Middleware wraps the resolving of every field
+// next resolves the field and returns the go value of the field, for methods this is the value returned by the method
+// A middleware can short-circuit the field by not calling next and returning a value or error itself,
+// the returned value must be assignable to the go type of the field or be nil
+// next can be called multiple times, for example to retry the field, the arguments are bound once before the middlewares are called
+//
+// Example:
+//
+//	s.Use(func(ctx *yarql.Ctx, field yarql.FieldInfo, next func() (interface{}, error)) (interface{}, error) {
+//		if field.ParentType == "Admin" && ctx.GetValue("user") == nil {
+//			return nil, errors.New("unauthorized")
+//		}
+//		return next()
+//	})
+type Middleware func(ctx *Ctx, field FieldInfo, next func() (interface{}, error)) (interface{}, error)
+
+// FieldInfo describes the field a middleware is called for
+type FieldInfo struct {
+	ParentType string
+	Name       string
+	Alias      string
+	// Path is the graphql path to the field json encoded, see (*Ctx).GetPath
+	Path []byte
+	// Arguments contains the arguments of the field with variables replaced by their values
+	// Objects are map[string]interface{}, lists []interface{}, enums strings, ints int and floats float64
+	Arguments map[string]interface{}
+}
+
+// Use adds a middleware that is called for every resolved field
+// Middlewares are called in the order they are added, the first added middleware is the outer most
+func (s *Schema) Use(middleware Middleware) {
+	s.middlewares = append(s.middlewares, middleware)
+}
+
+// fieldInfo returns the info about the current field passed to the middlewares
+// The ctx must be positioned at the arguments of the field or at the start of the field's selection
+func (ctx *Ctx) fieldInfo(typeObj *obj, name []byte, alias []byte) (*FieldInfo, bool) {
+	info := &FieldInfo{
+		ParentType: typeObj.typeName,
+		Name:       string(name),
+		Alias:      string(alias),
+		Path:       ctx.GetPath(),
+	}
+
+	if ctx.seekInst() != bytecode.ActionValue {
+		return info, false
+	}
+
+	arguments, _, criticalErr := ctx.argumentValue(ctx.charNr)
+	if criticalErr {
+		return nil, criticalErr
+	}
+	info.Arguments, _ = arguments.(map[string]interface{})
+	return info, false
+}
+
+// argumentValue converts the value at pos in the query bytecode to a go value without moving the ctx
+// pos must point to the ActionValue of the value, end is the location after the value
+func (ctx *Ctx) argumentValue(pos int) (value interface{}, end int, criticalErr bool) {
+	res := ctx.query.Res
+	kind := res[pos+1]
+	start := pos + 6
+	end = start + int(ctx.readUint32(pos+2))
+	data := b2s(res[start:end])
+
+	switch kind {
+	case bytecode.ValueVariable:
+		value, criticalErr = ctx.variableValue(data)
+		return value, end, criticalErr
+	case bytecode.ValueInt:
+		intValue, err := strconv.Atoi(data)
+		if err != nil {
+			return nil, end, ctx.err(err.Error())
+		}
+		return intValue, end, false
+	case bytecode.ValueFloat:
+		floatValue, err := strconv.ParseFloat(data, 64)
+		if err != nil {
+			return nil, end, ctx.err(err.Error())
+		}
+		return floatValue, end, false
+	case bytecode.ValueString, bytecode.ValueEnum:
+		return string(data), end, false
+	case bytecode.ValueBoolean:
+		return data == "1", end, false
+	case bytecode.ValueList:
+		list := []interface{}{}
+		// Every item is prefixed with a NULL byte and the list ends with 'e'
+		for itemPos := start; res[itemPos+1] != 'e'; {
+			var item interface{}
+			item, itemPos, criticalErr = ctx.argumentValue(itemPos + 1)
+			if criticalErr {
+				return nil, end, criticalErr
+			}
+			list = append(list, item)
+		}
+		return list, end, false
+	case bytecode.ValueObject:
+		object := map[string]interface{}{}
+		// Every field is prefixed with a NULL byte and ActionObjectValueField and the object ends with 'e'
+		for fieldPos := start; res[fieldPos+1] != 'e'; {
+			key, keyEnd := ctx.readNameAt(fieldPos + 2)
+			var fieldValue interface{}
+			fieldValue, fieldPos, criticalErr = ctx.argumentValue(keyEnd + 1)
+			if criticalErr {
+				return nil, end, criticalErr
+			}
+			object[string(key)] = fieldValue
+		}
+		return object, end, false
+	default:
+		return nil, end, false
+	}
+}
+
+// variableValue returns the value of a operation variable or it's default value
+func (ctx *Ctx) variableValue(name string) (interface{}, bool) {
+	hasVariables, criticalErr := ctx.parseVariables()
+	if criticalErr {
+		return nil, criticalErr
+	}
+	if hasVariables {
+		variable := ctx.variables.Get(name)
+		if variable != nil {
+			return jsonToInterface(variable), false
+		}
+	}
+
+	originalCharNr := ctx.charNr
+	defer func() {
+		ctx.charNr = originalCharNr
+	}()
+	if !ctx.findOperatorArgumentDefault(name) {
+		return nil, false
+	}
+	value, _, criticalErr := ctx.argumentValue(ctx.charNr + 1)
+	return value, criticalErr
+}
+
+func jsonToInterface(value *fastjson.Value) interface{} {
+	switch value.Type() {
+	case fastjson.TypeObject:
+		object := map[string]interface{}{}
+		value.GetObject().Visit(func(key []byte, v *fastjson.Value) {
+			object[string(key)] = jsonToInterface(v)
+		})
+		return object
+	case fastjson.TypeArray:
+		items := value.GetArray()
+		list := make([]interface{}, len(items))
+		for idx, item := range items {
+			list[idx] = jsonToInterface(item)
+		}
+		return list
+	case fastjson.TypeString:
+		return string(value.GetStringBytes())
+	case fastjson.TypeNumber:
+		intValue, err := value.Int()
+		if err == nil {
+			return intValue
+		}
+		return value.GetFloat64()
+	case fastjson.TypeTrue:
+		return true
+	case fastjson.TypeFalse:
+		return false
+	default:
+		return nil
+	}
+}
+
+// resolveFieldValue resolves the value of a field, if info is set the resolving is wrapped by the middlewares
+func (ctx *Ctx) resolveFieldValue(field *obj, info *FieldInfo, dept uint8, hasSubSelection bool) bool {
+	if info == nil {
+		return ctx.resolveFieldDataValue(field, dept, hasSubSelection)
+	}
+	return ctx.resolveFieldWithMiddlewares(field, info, dept, hasSubSelection)
+}
+
+func (ctx *Ctx) resolveFieldWithMiddlewares(field *obj, info *FieldInfo, dept uint8, hasSubSelection bool) bool {
+	goValue := ctx.getGoValue()
+	criticalErr := false
+
+	var method *objMethod
+	var next func() (interface{}, error)
+	valueTypeObj := field
+	goType := goValue.Type()
+	if field.valueType == valueTypeMethod {
+		method = field.method
+		valueTypeObj = &method.outType
+		goType = method.goType.Out(method.outNr)
+
+		// The arguments are bound once so next can be called multiple times
+		if ctx.bindQlMethodInputs(method, ctx.seekInst() == bytecode.ActionValue) {
+			ctx.writeNull()
+			return true
+		}
+		inputs := append([]reflect.Value{}, ctx.funcInputs...)
+
+		next = func() (interface{}, error) {
+			if !method.isTypeMethod && goValue.IsNil() {
+				return nil, nil
+			}
+
+			outs := goValue.Call(inputs)
+
+			var err error
+			if method.errorOutNr != nil {
+				errOut := outs[*method.errorOutNr]
+				if !errOut.IsNil() {
+					var ok bool
+					err, ok = errOut.Interface().(error)
+					if !ok {
+						criticalErr = ctx.err("returned a invalid kind of error")
+						return nil, nil
+					}
+				}
+			}
+			return outs[method.outNr].Interface(), err
+		}
+	} else {
+		next = func() (interface{}, error) {
+			return goValue.Interface(), nil
+		}
+	}
+
+	value, err := ctx.callMiddlewares(info, next)
+
+	if criticalErr {
+		ctx.writeNull()
+		return criticalErr
+	}
+	if err != nil {
+		// A field that returns a error is null, the returned value is ignored
+		ctx.addErr(err)
+		ctx.writeNull()
+		return false
+	}
+
+	if ctx.context != nil {
+		err := (*ctx.context).Err()
+		if err != nil {
+			// Context ended
+			ctx.addErr(err)
+			ctx.writeNull()
+			return false
+		}
+	}
+
+	if value == nil {
+		ctx.writeNull()
+		return false
+	}
+
+	reflectValue := reflect.ValueOf(value)
+	if reflectValue.Type() != goType {
+		if !reflectValue.Type().AssignableTo(goType) {
+			ctx.writeNull()
+			ctx.errf("middleware returned a %s for field %s but expected a %s", reflectValue.Type(), info.Name, goType)
+			return false
+		}
+		converted := reflect.New(goType).Elem()
+		converted.Set(reflectValue)
+		reflectValue = converted
+	}
+
+	if method != nil && method.returnsThunk {
+		if ctx.deferring {
+			ctx.deferField(method, reflectValue, dept)
+			return false
+		}
+		return ctx.resolveThunk(method, reflectValue, dept)
+	}
+
+	if method != nil {
+		hasSubSelection = ctx.seekInst() != 'e'
+	}
+	ctx.setGoValue(reflectValue)
+	return ctx.resolveFieldDataValue(valueTypeObj, dept, hasSubSelection)
+}
+
+// callMiddlewares calls the middlewares of the schema with next as the inner most function
+func (ctx *Ctx) callMiddlewares(info *FieldInfo, next func() (interface{}, error)) (interface{}, error) {
+	middlewares := ctx.schema.middlewares
+
+	var call func(idx int) (interface{}, error)
+	call = func(idx int) (interface{}, error) {
+		if idx == len(middlewares) {
+			return next()
+		}
+		return middlewares[idx](ctx, *info, func() (interface{}, error) {
+			return call(idx + 1)
+		})
+	}
+	return call(0)
+}
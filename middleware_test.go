@@ -0,0 +1,157 @@
+package yarql
+
+import (
+	"errors"
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+type TestMiddlewareData struct {
+	Name   string
+	Secret *string
+	Inner  *TestMiddlewareData
+}
+
+type TestMiddlewareFilter struct {
+	Limit int
+}
+
+func (TestMiddlewareData) ResolveGreet(args struct {
+	Name   string
+	Tags   []string
+	Filter *TestMiddlewareFilter
+}) (string, error) {
+	if args.Name == "" {
+		return "", errors.New("name is required")
+	}
+	return "hello " + args.Name, nil
+}
+
+func TestMiddlewareFieldInfo(t *testing.T) {
+	infos := []FieldInfo{}
+	s := NewSchema()
+	s.Use(func(ctx *Ctx, field FieldInfo, next func() (interface{}, error)) (interface{}, error) {
+		infos = append(infos, field)
+		return next()
+	})
+
+	query := `query($name: String!, $limit: Int = 10) {
+		name
+		inner {alias: name}
+		greet(name: $name, tags: ["a", "b"], filter: {limit: $limit})
+	}`
+	data := TestMiddlewareData{Name: "foo", Inner: &TestMiddlewareData{Name: "baz"}}
+	res, errs := bytecodeParse(t, s, query, data, M{}, ResolveOptions{
+		NoMeta:    true,
+		Variables: `{"name": "world"}`,
+	})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"name":"foo","inner":{"alias":"baz"},"greet":"hello world"}`, res)
+
+	a.Equal(t, 4, len(infos))
+	a.Equal(t, FieldInfo{ParentType: "TestMiddlewareData", Name: "name", Alias: "name", Path: []byte(`["name"]`)}, infos[0])
+	a.Equal(t, FieldInfo{ParentType: "TestMiddlewareData", Name: "inner", Alias: "inner", Path: []byte(`["inner"]`)}, infos[1])
+	a.Equal(t, FieldInfo{ParentType: "TestMiddlewareData", Name: "name", Alias: "alias", Path: []byte(`["inner","alias"]`)}, infos[2])
+	a.Equal(t, FieldInfo{
+		ParentType: "TestMiddlewareData",
+		Name:       "greet",
+		Alias:      "greet",
+		Path:       []byte(`["greet"]`),
+		Arguments: map[string]interface{}{
+			"name":   "world",
+			"tags":   []interface{}{"a", "b"},
+			"filter": map[string]interface{}{"limit": 10},
+		},
+	}, infos[3])
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	s := NewSchema()
+	s.Use(func(ctx *Ctx, field FieldInfo, next func() (interface{}, error)) (interface{}, error) {
+		switch field.Name {
+		case "secret":
+			return nil, errors.New("unauthorized")
+		case "greet":
+			return "intercepted", nil
+		}
+		return next()
+	})
+
+	secret := "bar"
+	data := TestMiddlewareData{Name: "foo", Secret: &secret, Inner: &TestMiddlewareData{Name: "baz"}}
+	res, errs := bytecodeParse(t, s, `{name secret greet(name: "world") inner {name}}`, data, M{}, ResolveOptions{})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "unauthorized", errs[0].Error())
+	a.Equal(t, `{"data":{"name":"foo","secret":null,"greet":"intercepted","inner":{"name":"baz"}},"errors":[{"message":"unauthorized","locations":[{"line":1,"column":7}],"path":["secret"]}],"extensions":{}}`, res)
+}
+
+func TestMiddlewareWrapsValuesAndErrors(t *testing.T) {
+	s := NewSchema()
+	s.Use(func(ctx *Ctx, field FieldInfo, next func() (interface{}, error)) (interface{}, error) {
+		value, err := next()
+		if err != nil {
+			return value, errors.New(field.Name + ": " + err.Error())
+		}
+		if name, ok := value.(string); ok {
+			return "<" + name + ">", nil
+		}
+		return value, nil
+	})
+	s.Use(func(ctx *Ctx, field FieldInfo, next func() (interface{}, error)) (interface{}, error) {
+		value, err := next()
+		if name, ok := value.(string); ok {
+			return "(" + name + ")", err
+		}
+		return value, err
+	})
+
+	data := TestMiddlewareData{Name: "foo"}
+	res, errs := bytecodeParse(t, s, `{name greet(name: "")}`, data, M{}, ResolveOptions{NoMeta: true})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "greet: name is required", errs[0].Error())
+	// greet is non-null so the error nulls the data
+	a.Equal(t, `null`, res)
+
+	res, errs = bytecodeParse(t, s, `{name}`, data, M{}, ResolveOptions{NoMeta: true})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"name":"<(foo)>"}`, res)
+}
+
+func TestMiddlewareCallNextTwice(t *testing.T) {
+	s := NewSchema()
+	calls := 0
+	s.Use(func(ctx *Ctx, field FieldInfo, next func() (interface{}, error)) (interface{}, error) {
+		if field.Name != "greet" {
+			return next()
+		}
+		calls++
+		value, err := next()
+		if err != nil {
+			return value, err
+		}
+		// Retry the field
+		return next()
+	})
+
+	res, errs := bytecodeParse(t, s, `{greet(name: "world", tags: ["a"])}`, TestMiddlewareData{}, M{}, ResolveOptions{NoMeta: true})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, 1, calls)
+	a.Equal(t, `{"greet":"hello world"}`, res)
+}
+
+func TestMiddlewareInvalidValue(t *testing.T) {
+	s := NewSchema()
+	s.Use(func(ctx *Ctx, field FieldInfo, next func() (interface{}, error)) (interface{}, error) {
+		if field.Name == "name" {
+			return 1, nil
+		}
+		return next()
+	})
+
+	secret := "bar"
+	res, errs := bytecodeParse(t, s, `{name secret}`, TestMiddlewareData{Name: "foo", Secret: &secret}, M{}, ResolveOptions{NoMeta: true})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "middleware returned a int for field name but expected a string", errs[0].Error())
+	a.Equal(t, `null`, res)
+}
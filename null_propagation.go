@@ -0,0 +1,218 @@
+package yarql
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// Null propagation follows the error propagation of the graphql spec
+// If a value in a non-null position resolves to null (for example because the resolver returned a error)
+// the nearest nullable parent value is replaced with null, if there is no nullable parent the data becomes null
+//
+// While resolving ctx.nullablePathLen contains the length of ctx.path at the nearest nullable value,
+// null values in non-null positions add that path to ctx.nullPaths.
+// As fields might be deferred or resolved in parallel the values are replaced after the whole operation is written
+
+// isNonNullOutput returns true if the output type of item is advertised as non-null, see (*Schema).objToQLType
+func (s *Schema) isNonNullOutput(item *obj) bool {
+	switch item.valueType {
+	case valueTypeUndefined, valueTypeArray, valueTypePtr, valueTypeInterface, valueTypeInterfaceRef, valueTypeJSON:
+		return false
+	case valueTypeScalar:
+		return s.definedScalars[item.scalarIndex].goType.Kind() != reflect.Ptr
+	case valueTypeMethod:
+		return item.method.isTypeMethod && s.isNonNullOutput(&item.method.outType)
+	default:
+		return true
+	}
+}
+
+// checkNonNullValue marks the nearest nullable parent as null if the value written since valueStart is null
+// Should only be called for values in a non-null position
+func (ctx *Ctx) checkNonNullValue(valueStart int) {
+	if !bytes.Equal(ctx.result[valueStart:], nullBytes) {
+		return
+	}
+	ctx.nullPaths = append(ctx.nullPaths, append([]byte{}, ctx.path[:ctx.nullablePathLen]...))
+}
+
+// propagateNulls replaces the values marked by checkNonNullValue with null
+// dataStart is the location in the result where the data of the response starts
+func (ctx *Ctx) propagateNulls(dataStart int) {
+	for _, path := range ctx.nullPaths {
+		if len(path) == 0 {
+			ctx.result = append(ctx.result[:dataStart], nullBytes...)
+			return
+		}
+
+		start, end, ok := findJSONValue(ctx.result[dataStart:], path)
+		if !ok {
+			// One of the parents of the value is already replaced with null
+			continue
+		}
+		start += dataStart
+		end += dataStart
+
+		rest := append([]byte{}, ctx.result[end:]...)
+		ctx.result = append(append(ctx.result[:start], nullBytes...), rest...)
+	}
+}
+
+// findJSONValue returns the location of a value within json data
+// path is formatted like ctx.path, for example: ,"foo",1,"bar"
+func findJSONValue(data []byte, path []byte) (start int, end int, ok bool) {
+	for len(path) > 0 {
+		// Read the next path segment, every segment is prefixed with a comma
+		path = path[1:]
+		segmentEnd := bytes.IndexByte(path, ',')
+		if path[0] == '"' {
+			segmentEnd = bytes.IndexByte(path[1:], '"') + 2
+		}
+		if segmentEnd == -1 {
+			segmentEnd = len(path)
+		}
+		segment := path[:segmentEnd]
+		path = path[segmentEnd:]
+
+		if segment[0] == '"' {
+			start, end, ok = findJSONObjectField(data, start, segment)
+		} else {
+			start, end, ok = findJSONListItem(data, start, segment)
+		}
+		if !ok {
+			return 0, 0, false
+		}
+	}
+
+	return start, end, true
+}
+
+// findJSONObjectField returns the location of the value of key within the object at start
+// key is the quoted field name
+func findJSONObjectField(data []byte, start int, key []byte) (int, int, bool) {
+	if data[start] != '{' {
+		return 0, 0, false
+	}
+
+	i := skipJSONSpace(data, start+1)
+	for i < len(data) && data[i] == '"' {
+		keyEnd := skipJSONValue(data, i)
+		if keyEnd == -1 {
+			return 0, 0, false
+		}
+		matches := bytes.Equal(data[i:keyEnd], key)
+
+		i = skipJSONSpace(data, keyEnd)
+		if i >= len(data) || data[i] != ':' {
+			return 0, 0, false
+		}
+		valueStart := skipJSONSpace(data, i+1)
+		valueEnd := skipJSONValue(data, valueStart)
+		if valueEnd == -1 {
+			return 0, 0, false
+		}
+		if matches {
+			return valueStart, valueEnd, true
+		}
+
+		i = skipJSONSpace(data, valueEnd)
+		if i >= len(data) || data[i] != ',' {
+			return 0, 0, false
+		}
+		i = skipJSONSpace(data, i+1)
+	}
+	return 0, 0, false
+}
+
+// findJSONListItem returns the location of the list item with the index within the list at start
+func findJSONListItem(data []byte, start int, index []byte) (int, int, bool) {
+	if data[start] != '[' {
+		return 0, 0, false
+	}
+
+	idx := 0
+	for _, c := range index {
+		idx = idx*10 + int(c-'0')
+	}
+
+	i := skipJSONSpace(data, start+1)
+	for n := 0; i < len(data) && data[i] != ']'; n++ {
+		valueEnd := skipJSONValue(data, i)
+		if valueEnd == -1 {
+			return 0, 0, false
+		}
+		if n == idx {
+			return i, valueEnd, true
+		}
+
+		i = skipJSONSpace(data, valueEnd)
+		if i >= len(data) || data[i] != ',' {
+			return 0, 0, false
+		}
+		i = skipJSONSpace(data, i+1)
+	}
+	return 0, 0, false
+}
+
+// skipJSONValue returns the location after the json value at i, -1 if the value is invalid
+func skipJSONValue(data []byte, i int) int {
+	if i >= len(data) {
+		return -1
+	}
+
+	switch data[i] {
+	case '"':
+		for i++; i < len(data); i++ {
+			switch data[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+		return -1
+	case '{', '[':
+		dept := 0
+		for i < len(data) {
+			switch data[i] {
+			case '"':
+				i = skipJSONValue(data, i)
+				if i == -1 {
+					return -1
+				}
+				continue
+			case '{', '[':
+				dept++
+			case '}', ']':
+				dept--
+				if dept == 0 {
+					return i + 1
+				}
+			}
+			i++
+		}
+		return -1
+	default:
+		// Numbers, booleans and null
+		for i < len(data) {
+			switch data[i] {
+			case ',', '}', ']', ' ', '\t', '\n', '\r':
+				return i
+			}
+			i++
+		}
+		return i
+	}
+}
+
+func skipJSONSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\n', '\r':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
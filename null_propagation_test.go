@@ -0,0 +1,118 @@
+package yarql
+
+import (
+	"errors"
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+type TestNullPropagationData struct {
+	Inner         *TestNullPropagationInner
+	Items         []TestNullPropagationInner
+	NullableItems []*TestNullPropagationInner
+}
+
+type TestNullPropagationInner struct {
+	Name string
+	Fail bool
+}
+
+func (i TestNullPropagationInner) ResolveRequired() (string, error) {
+	if i.Fail {
+		return "", errors.New(i.Name + " failed")
+	}
+	return i.Name, nil
+}
+
+func (i TestNullPropagationInner) ResolveOptional() (*string, error) {
+	if i.Fail {
+		return nil, errors.New(i.Name + " failed")
+	}
+	return &i.Name, nil
+}
+
+func (i TestNullPropagationInner) ResolveDeferred() func() (string, error) {
+	return i.ResolveRequired
+}
+
+func (TestNullPropagationData) ResolveRoot() (string, error) {
+	return "", errors.New("root failed")
+}
+
+func (TestNullPropagationData) ResolveParallelInner() *TestNullPropagationInner {
+	return &TestNullPropagationInner{Name: "parallel", Fail: true}
+}
+
+func TestNullPropagation(t *testing.T) {
+	items := []TestNullPropagationInner{{Name: "a"}, {Name: "b", Fail: true}}
+	data := TestNullPropagationData{
+		Inner:         &TestNullPropagationInner{Name: "inner", Fail: true},
+		Items:         items,
+		NullableItems: []*TestNullPropagationInner{&items[0], &items[1]},
+	}
+
+	testCases := []struct {
+		name     string
+		query    string
+		expected string
+		errors   int
+	}{
+		{"nullable field", `{inner {name optional}}`, `{"inner":{"name":"inner","optional":null}}`, 1},
+		{"nullable parent", `{inner {name required}}`, `{"inner":null}`, 1},
+		{"non-null list items", `{items {name required}}`, `{"items":null}`, 1},
+		{"nullable list items", `{nullableItems {name required}}`, `{"nullableItems":[{"name":"a","required":"a"},null]}`, 1},
+		{"deferred field", `{nullableItems {name deferred}}`, `{"nullableItems":[{"name":"a","deferred":"a"},null]}`, 1},
+		{"deferred field in non-null list items", `{items {name deferred}}`, `{"items":null}`, 1},
+		{"root", `{inner {name} root}`, `null`, 1},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			res, errs := bytecodeParse(t, NewSchema(), testCase.query, data, M{}, ResolveOptions{NoMeta: true})
+			a.Equal(t, testCase.errors, len(errs))
+			a.Equal(t, testCase.expected, res)
+		})
+	}
+}
+
+func TestNullPropagationResponse(t *testing.T) {
+	res, errs := bytecodeParse(t, NewSchema(), `{root}`, TestNullPropagationData{}, M{}, ResolveOptions{})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, `{"data":null,"errors":[{"message":"root failed","locations":[{"line":1,"column":2}],"path":["root"]}],"extensions":{}}`, res)
+}
+
+func TestNullPropagationParallel(t *testing.T) {
+	query := `{parallelInner {name required} inner {name}}`
+	data := TestNullPropagationData{
+		Inner: &TestNullPropagationInner{Name: "inner", Fail: true},
+	}
+	res, errs := bytecodeParse(t, NewSchema(), query, data, M{}, ResolveOptions{NoMeta: true, Parallel: true})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, `{"parallelInner":null,"inner":{"name":"inner"}}`, res)
+}
+
+func TestFindJSONValue(t *testing.T) {
+	data := []byte(`{"a": "x,\"]}" , "b" :[1, {"c":null}, [true]],"d":{}}`)
+
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{`,"a"`, `"x,\"]}"`},
+		{`,"b"`, `[1, {"c":null}, [true]]`},
+		{`,"b",1,"c"`, `null`},
+		{`,"b",2,0`, `true`},
+		{`,"d"`, `{}`},
+	}
+	for _, testCase := range testCases {
+		start, end, ok := findJSONValue(data, []byte(testCase.path))
+		a.True(t, ok, testCase.path)
+		a.Equal(t, testCase.expected, string(data[start:end]), testCase.path)
+	}
+
+	for _, path := range []string{`,"e"`, `,"b",3`, `,"a",0`, `,"b",1,"c","d"`} {
+		_, _, ok := findJSONValue(data, []byte(path))
+		a.False(t, ok, path)
+	}
+}
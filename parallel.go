@@ -0,0 +1,186 @@
+package yarql
+
+import (
+	"reflect"
+
+	"github.com/mjarkk/yarql/bytecode"
+)
+
+// Parallel execution resolves the method fields of a selection set concurrently
+// Every field is resolved by a forked ctx that writes to it's own result buffer,
+// after all fields of the selection set are resolved the results are inserted in document order
+//
+// Fields are not resolved concurrently if:
+// - they are on the mutation root, the spec requires these to be executed serially
+// - they are inside a list, these fields are resolved serially so loaders can batch their keys
+// - the operation is a subscription
+
+// parallelField is a field that is being resolved by a forked ctx
+type parallelField struct {
+	resultIdx int // The location in ctx.result where the value should be inserted
+	fork      *Ctx
+	done      chan parallelFieldResult
+}
+
+type parallelFieldResult struct {
+	criticalErr bool
+	panicValue  interface{} // Not nil if the resolver panicked
+}
+
+// canResolveInParallel returns true if a field of typeObj can be resolved in parallel to it's siblings
+func (ctx *Ctx) canResolveInParallel(typeObj *obj, field *obj) bool {
+	return ctx.parallel &&
+		!ctx.deferring &&
+		!ctx.subscribing &&
+		field.valueType == valueTypeMethod &&
+		!field.method.returnsChan &&
+		typeObj != ctx.schema.rootMethod
+}
+
+// resolveFieldInParallel starts resolving a field using a forked ctx
+// The field's go value must be the current go value
+func (ctx *Ctx) resolveFieldInParallel(field *obj, info *FieldInfo, traced *TracedField, dept uint8, hasSubSelection bool) {
+	fork := ctx.fork()
+	entry := parallelField{
+		resultIdx: len(ctx.result),
+		fork:      fork,
+		done:      make(chan parallelFieldResult, 1),
+	}
+	ctx.parallelFields = append(ctx.parallelFields, entry)
+
+	go func() {
+		result := parallelFieldResult{}
+		defer func() {
+			result.panicValue = recover()
+			entry.done <- result
+		}()
+		if traced != nil {
+			defer fork.tracer.StartField(fork, *traced).End()
+		}
+		result.criticalErr = fork.resolveFieldValue(field, info, dept, hasSubSelection)
+		if fork.schema.isNonNullOutput(field) {
+			fork.checkNonNullValue(0)
+		}
+	}()
+}
+
+// waitForParallelFields waits for all fields started since ctx.parallelFields had the length start
+// and inserts their results into the result at the location the fields would normally be written
+func (ctx *Ctx) waitForParallelFields(start int) bool {
+	if len(ctx.parallelFields) == start {
+		return false
+	}
+
+	criticalErr := false
+	var panicValue interface{}
+	inserted := 0
+	for i := start; i < len(ctx.parallelFields); i++ {
+		field := ctx.parallelFields[i]
+		result := <-field.done
+		if result.panicValue != nil && panicValue == nil {
+			panicValue = result.panicValue
+		}
+		if result.criticalErr {
+			criticalErr = true
+		}
+
+		fork := field.fork
+		insertAt := field.resultIdx + inserted
+		ctx.result = append(ctx.result, fork.result...)
+		copy(ctx.result[insertAt+len(fork.result):], ctx.result[insertAt:len(ctx.result)-len(fork.result)])
+		copy(ctx.result[insertAt:], fork.result)
+		inserted += len(fork.result)
+
+		ctx.query.Errors = append(ctx.query.Errors, fork.query.Errors...)
+		ctx.nullPaths = append(ctx.nullPaths, fork.nullPaths...)
+		for _, entry := range fork.extensions {
+			ctx.SetExtension(entry.key, entry.value)
+		}
+		if ctx.tracingEnabled {
+			ctx.tracing.Execution.Resolvers = append(ctx.tracing.Execution.Resolvers, fork.tracing.Execution.Resolvers...)
+		}
+		ctx.releaseFork(fork)
+	}
+	ctx.parallelFields = ctx.parallelFields[:start]
+
+	if panicValue != nil {
+		// Re-panic on the goroutine that is resolving the query
+		panic(panicValue)
+	}
+	return criticalErr
+}
+
+// fork returns a copy of the ctx that can resolve a field concurrently
+// The query bytecode is shared with the fork so the fork must be released before the ctx is re-used
+func (ctx *Ctx) fork() *Ctx {
+	fork := ctx.schema.getPooledCtx()
+	fork.ownQuery = fork.query
+
+	tracing := fork.tracing
+	if ctx.tracingEnabled {
+		tracing.reset()
+		tracing.GoStartTime = ctx.tracing.GoStartTime
+	}
+
+	*fork = Ctx{
+		schema: ctx.schema,
+		query: bytecode.ParserCtx{
+			Res:               ctx.query.Res,
+			FragmentLocations: ctx.query.FragmentLocations,
+			Locations:         ctx.query.Locations,
+			Query:             ctx.query.Query,
+			Errors:            fork.ownQuery.Errors[:0],
+			TargetIdx:         ctx.query.TargetIdx,
+		},
+		ownQuery:                 fork.ownQuery,
+		charNr:                   ctx.charNr,
+		context:                  ctx.context,
+		path:                     append(fork.path[:0], ctx.path...),
+		getFormFile:              ctx.getFormFile,
+		result:                   fork.result[:0],
+		operatorHasArguments:     ctx.operatorHasArguments,
+		operatorArgumentsStartAt: ctx.operatorArgumentsStartAt,
+		currentField:             ctx.currentField,
+		nullablePathLen:          ctx.nullablePathLen,
+		tracer:                   ctx.tracer,
+		tracingEnabled:           ctx.tracingEnabled,
+		tracing:                  tracing,
+
+		// The variables are parsed again by the fork as parsed json values are not safe for concurrent use
+		rawVariables:        ctx.rawVariables,
+		variablesJSONParser: fork.variablesJSONParser,
+		variables:           fork.variables,
+
+		reflectValues:          ctx.reflectValues,
+		currentReflectValueIdx: ctx.currentReflectValueIdx,
+		funcInputs:             fork.funcInputs,
+		ctxReflection:          fork.ctxReflection,
+
+		validator:   fork.validator,
+		scalarArena: fork.scalarArena,
+
+		maxComplexity: ctx.maxComplexity,
+		complexity:    ctx.complexity,
+
+		extensions: fork.extensions[:0],
+		nullPaths:  fork.nullPaths[:0],
+
+		deferredFields: fork.deferredFields[:0],
+		parallel:       ctx.parallel,
+		parallelFields: fork.parallelFields[:0],
+
+		values: ctx.values,
+	}
+	return fork
+}
+
+// releaseFork hands the fork back to the schema's ctx pool
+func (ctx *Ctx) releaseFork(fork *Ctx) {
+	fork.ownQuery.Errors = fork.query.Errors[:0]
+	fork.query = fork.ownQuery
+	fork.ownQuery = bytecode.ParserCtx{}
+	fork.reflectValues = [256]reflect.Value{}
+	fork.values = nil
+	fork.context = nil
+	ctx.schema.ctxPool.Put(fork)
+}
@@ -0,0 +1,115 @@
+package yarql
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+type TestParallelData struct {
+	Name string
+}
+
+func (TestParallelData) ResolveA() string {
+	time.Sleep(time.Millisecond * 50)
+	return "a"
+}
+
+func (TestParallelData) ResolveB(ctx *Ctx) TestParallelInner {
+	time.Sleep(time.Millisecond * 50)
+	return TestParallelInner{Path: string(ctx.GetPath())}
+}
+
+func (TestParallelData) ResolveC(args struct{ Value string }) (*string, error) {
+	time.Sleep(time.Millisecond * 50)
+	return nil, errors.New("c failed with " + args.Value)
+}
+
+type TestParallelInner struct {
+	Path string
+}
+
+func (TestParallelInner) ResolveD() []int {
+	time.Sleep(time.Millisecond * 50)
+	return []int{1, 2}
+}
+
+var testParallelMutationsLock sync.Mutex
+var testParallelMutations []string
+
+type TestParallelMethods struct{}
+
+func (TestParallelMethods) ResolveFirst() string {
+	time.Sleep(time.Millisecond * 20)
+	testParallelMutationsLock.Lock()
+	testParallelMutations = append(testParallelMutations, "first")
+	testParallelMutationsLock.Unlock()
+	return "first"
+}
+
+func (TestParallelMethods) ResolveSecond() string {
+	testParallelMutationsLock.Lock()
+	testParallelMutations = append(testParallelMutations, "second")
+	testParallelMutationsLock.Unlock()
+	return "second"
+}
+
+func TestParallel(t *testing.T) {
+	query := `query($value: String!) {
+		name
+		a
+		b {path d}
+		c(value: $value)
+		...frag
+	}
+	fragment frag on TestParallelData {
+		alias: a
+	}`
+
+	start := time.Now()
+	res, errs := bytecodeParse(t, NewSchema(), query, TestParallelData{Name: "foo"}, TestParallelMethods{}, ResolveOptions{
+		Parallel:  true,
+		Variables: `{"value": "bar"}`,
+	})
+	duration := time.Since(start)
+
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "c failed with bar", errs[0].Error())
+	a.Equal(t, `{"data":{"name":"foo","a":"a","b":{"path":"[\"b\"]","d":[1,2]},"c":null,"alias":"a"},"errors":[{"message":"c failed with bar","locations":[{"line":5,"column":3}],"path":["c"]}],"extensions":{}}`, res)
+
+	// Serially resolving the fields would take at least 250ms
+	a.True(t, duration < time.Millisecond*200, duration.String())
+}
+
+func TestParallelMutationsAreSerial(t *testing.T) {
+	testParallelMutations = nil
+
+	res, errs := bytecodeParse(t, NewSchema(), `mutation {first second}`, TestParallelData{}, TestParallelMethods{}, ResolveOptions{
+		NoMeta:   true,
+		Parallel: true,
+	})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"first":"first","second":"second"}`, res)
+	a.Equal(t, []string{"first", "second"}, testParallelMutations)
+}
+
+type TestParallelPanicData struct{}
+
+func (TestParallelPanicData) ResolveFoo() string {
+	panic("foo")
+}
+
+func TestParallelPanic(t *testing.T) {
+	s := NewSchema()
+	err := s.Parse(TestParallelPanicData{}, M{}, nil)
+	a.NoError(t, err)
+
+	defer func() {
+		a.Equal(t, "foo", recover())
+	}()
+	s.Resolve([]byte(`{foo}`), ResolveOptions{Parallel: true})
+	t.Fatal("expected the resolver panic to be re-panicked")
+}
@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -48,17 +49,28 @@ type Schema struct {
 	inTypes    inputMap
 	interfaces types
 
-	rootQuery         *obj
-	rootQueryValue    reflect.Value
-	rootMethod        *obj
-	rootMethodValue   reflect.Value
-	MaxDepth          uint8 // Default 255
-	definedEnums      []enum
-	definedDirectives map[DirectiveLocation][]*Directive
-	ctx               *Ctx
+	rootQuery             *obj
+	rootQueryValue        reflect.Value
+	rootMethod            *obj
+	rootMethodValue       reflect.Value
+	rootSubscription      *obj // nil if no subscriptions are defined
+	rootSubscriptionValue reflect.Value
+	MaxDepth              uint8 // Default 255
+	definedEnums          []enum
+	definedScalars        []scalar
+	definedUnions         []union // Only used while parsing
+	definedDirectives     map[DirectiveLocation][]*Directive
+	ctx                   *Ctx                // Used by (*Schema).Resolve
+	ctxPool               sync.Pool           // Used by (*Schema).ResolveConcurrent
+	persistedQueries      PersistedQueryStore // Used by (*Schema).HandleRequest, nil if persisted queries are disabled
+	trustedDocuments      map[string]*trustedDocument
+	middlewares           []Middleware
+	tracer                Tracer // The default tracer of requests, see SchemaOptions.Tracer
+	usesJSONScalar        bool   // Maps are exposed as the JSON scalar, see MapAsJSON
 
 	// Zero alloc variables
 	Result           []byte
+	graphqlTypesLock sync.Mutex // Guards the graphql types below as they are lazily generated by queries
 	graphqlTypesMap  map[string]qlType
 	graphqlTypesList []qlType
 	graphqlObjFields map[string][]qlField
@@ -78,6 +90,8 @@ const (
 	valueTypeTime
 	valueTypeInterfaceRef
 	valueTypeInterface
+	valueTypeScalar
+	valueTypeJSON
 )
 
 // TODO Maybe add a pointer to the opj if valueType == valueTypeObjRef || valueType == valueTypeInterfaceRef
@@ -92,6 +106,10 @@ type obj struct {
 	qlFieldName   []byte
 	hidden        bool
 	isID          bool
+	description   string // The field description or if valueType == valueTypeObj || valueTypeInterface the type description
+
+	deprecationReason *string // Not nil if this is a deprecated field
+	cost              *int    // The complexity cost of this field, nil if the default cost should be used
 
 	// Value type == valueTypeObj || valueTypeInterface
 	objContents map[uint32]*obj
@@ -101,10 +119,14 @@ type obj struct {
 
 	// Value is inside struct
 	structFieldIdx int
+	embeddedPath   []int // Set if the field is promoted from a embedded struct, see structFields
 
 	// Value type == valueTypeArray || type == valueTypePtr
 	innerContent *obj
 
+	// Value type == valueTypeArray, set if the go value is a map that is resolved as a list of key value entries
+	mapEntryType reflect.Type
+
 	// Value type == valueTypeData
 	dataValueType reflect.Kind
 
@@ -114,8 +136,14 @@ type obj struct {
 	// Value type == valueTypeEnum
 	enumTypeIndex int
 
+	// Value type == valueTypeScalar
+	scalarIndex int
+
 	// Value type == valueTypeInterface || valueTypeObj
 	implementations []*obj
+
+	// Value type == valueTypeInterface
+	isUnion bool // The interface is a union, unions have no fields
 }
 
 func getObjKey(key []byte) uint32 {
@@ -155,13 +183,20 @@ type objMethod struct {
 	goFunctionName string
 	goType         reflect.Type
 
-	ins        []baseInput             // The real function inputs
-	inFields   map[string]referToInput // Contains all the fields of all the ins
-	checkedIns bool                    // are the ins checked yet
+	ins              []baseInput             // The real function inputs
+	inFields         map[string]referToInput // Contains all the fields of all the ins
+	checkedIns       bool                    // are the ins checked yet
+	hasDefaultValues bool                    // One of the inFields has a default value
+
+	outNr       int
+	outType     obj
+	errorOutNr  *int
+	returnsChan bool // the method returns a channel of outType, only allowed on the subscription root
 
-	outNr      int
-	outType    obj
-	errorOutNr *int
+	// The method returns a function that returns outType, see loader.go
+	// The returned function is called after all sibling list items are resolved so loaders can batch their keys
+	returnsThunk  bool
+	thunkHasError bool // the returned function also returns an error
 }
 
 type inputMap map[string]*input
@@ -180,17 +215,31 @@ type input struct {
 	isID          bool
 	isFile        bool
 	isTime        bool
+	isScalar      bool
+	scalarIndex   int
+	isJSON        bool // A map exposed as the JSON scalar
 
-	goFieldIdx  int
-	gqFieldName string
+	goFieldIdx     int
+	goEmbeddedPath []int // Set if the field is promoted from a embedded struct, see structFields
+	gqFieldName    string
+	description    string // The field or argument description or if this is a struct in inputMap the type description
+
+	deprecationReason *string // Not nil if this is a deprecated argument or input field
+
+	defaultValue    *string // Not nil if the argument or input field has a default value, see default_values.go
+	defaultBytecode []byte  // The default value parsed into bytecode
 
 	// kind == Slice, Array or Ptr
 	elem *input
 
+	// kind == Slice, set if the go value is a map that is bound from a list of key value entries
+	mapEntryType reflect.Type
+
 	// kind == struct
 	isStructPointers bool
 	structName       string
 	structContent    map[string]input
+	hasDefaultValues bool // One of the fields in structContent has a default value
 }
 
 type baseInput struct {
@@ -204,6 +253,24 @@ type SchemaOptions struct {
 	noMethodEqualToQueryChecks bool
 
 	SkipGraphqlTypesInjection bool
+
+	// Subscriptions is the root of the subscription type
+	// The Resolve methods of this struct must return a receive only channel (<-chan T)
+	// Every value send over the channel results in a response to the subscriber
+	Subscriptions interface{}
+
+	// PersistedQueries stores the automatic persisted queries used by (*Schema).HandleRequest
+	// Defaults to a in memory store that keeps up to 1000 queries
+	PersistedQueries PersistedQueryStore
+
+	// DisablePersistedQueries disables automatic persisted queries
+	DisablePersistedQueries bool
+
+	// Tracer receives the spans of all requests that do not set ResolveOptions.Tracer
+	Tracer Tracer
+
+	// MapStrategy defines how map[string]T types are exposed, defaults to MapAsKeyValueList
+	MapStrategy MapStrategy
 }
 
 type parseCtx struct {
@@ -211,6 +278,9 @@ type parseCtx struct {
 	unknownTypesCount  int
 	unknownInputsCount int
 	parsedMethods      []*objMethod
+	mapStrategy        MapStrategy
+	keyValueTypes      map[string]string // The generated key value types of maps and the key and value types they are generated for
+	defaultValues      []pendingDefaultValue
 }
 
 // NewSchema creates a new schema wherevia you can define the graphql types and make queries
@@ -278,10 +348,28 @@ func NewSchema() *Schema {
 		panic("INTERNAL ERROR: " + err.Error())
 	}
 
+	err = s.RegisterDirective(Directive{
+		Name: "deprecated",
+		Where: []DirectiveLocation{
+			DirectiveLocationFieldDefinition,
+			DirectiveLocationArgumentDefinition,
+			DirectiveLocationInputFieldDefinition,
+			DirectiveLocationEnumValue,
+		},
+		Method: func(args struct{ Reason *string }) DirectiveModifier {
+			return DirectiveModifier{}
+		},
+		Description: "Marks an element of a GraphQL schema as no longer supported.",
+	})
+	if err != nil {
+		panic("INTERNAL ERROR: " + err.Error())
+	}
+
 	return s
 }
 
 // SetCacheRules sets the cacheing rules
+// Must be called after (*Schema).Parse and before the first query is resolved
 func (s *Schema) SetCacheRules(
 	cacheQueryFromLen *int, // default = 300
 ) {
@@ -298,6 +386,10 @@ func (s *Schema) Parse(queries interface{}, methods interface{}, options *Schema
 	ctx := &parseCtx{
 		schema:        s,
 		parsedMethods: []*objMethod{},
+		keyValueTypes: map[string]string{},
+	}
+	if options != nil {
+		ctx.mapStrategy = options.MapStrategy
 	}
 
 	obj, err := ctx.check(reflect.TypeOf(queries), false)
@@ -326,6 +418,26 @@ func (s *Schema) Parse(queries interface{}, methods interface{}, options *Schema
 		}
 	}
 
+	if options != nil && options.Subscriptions != nil {
+		s.rootSubscriptionValue = reflect.ValueOf(options.Subscriptions)
+		obj, err = ctx.check(reflect.TypeOf(options.Subscriptions), false)
+		if err != nil {
+			return err
+		}
+		if obj.valueType != valueTypeObjRef {
+			return errors.New("input subscriptions must be a struct")
+		}
+		s.rootSubscription = s.types[obj.typeName]
+		if s.rootSubscription == s.rootQuery || s.rootSubscription == s.rootMethod {
+			return errors.New("subscriptions cannot be the same struct as the query or method struct")
+		}
+	}
+
+	err = ctx.checkSubscriptionFields()
+	if err != nil {
+		return err
+	}
+
 	if options == nil || !options.SkipGraphqlTypesInjection {
 		s.injectQLTypes(ctx)
 	}
@@ -350,6 +462,23 @@ func (s *Schema) Parse(queries interface{}, methods interface{}, options *Schema
 		}
 	}
 
+	err = ctx.checkDefaultValues()
+	if err != nil {
+		return err
+	}
+
+	if options == nil || !options.DisablePersistedQueries {
+		if options != nil && options.PersistedQueries != nil {
+			s.persistedQueries = options.PersistedQueries
+		} else {
+			s.persistedQueries = NewMemoryPersistedQueryStore(defaultPersistedQueriesMaxEntries)
+		}
+	}
+
+	if options != nil {
+		s.tracer = options.Tracer
+	}
+
 	s.ctx = newCtx(s)
 	s.parsed = true
 
@@ -363,12 +492,23 @@ func (c *parseCtx) check(t reflect.Type, hasIDTag bool) (*obj, error) {
 		goPkgPath:     t.PkgPath(),
 		goTypeName:    t.Name(),
 	}
+	var descriptions, deprecations map[string]string
+	var costs map[string]int
 
 	if res.goPkgPath == "time" && res.goTypeName == "Time" {
 		res.valueType = valueTypeTime
 		return &res, nil
 	}
 
+	scalarIndex, scalar := c.schema.getScalar(t)
+	if scalar != nil {
+		res.valueType = valueTypeScalar
+		res.scalarIndex = scalarIndex
+		res.typeName = scalar.typeName
+		res.typeNameBytes = []byte(scalar.typeName)
+		return &res, nil
+	}
+
 	switch t.Kind() {
 	case reflect.Struct:
 		if hasIDTag {
@@ -399,6 +539,14 @@ func (c *parseCtx) check(t reflect.Type, hasIDTag bool) (*obj, error) {
 				}
 				res.implementations = append(res.implementations, impl)
 			}
+
+			for _, unionType := range c.schema.unionsWithMember(t) {
+				union, err := c.check(unionType, false)
+				if err != nil {
+					return nil, err
+				}
+				res.implementations = append(res.implementations, union)
+			}
 		} else {
 			c.unknownTypesCount++
 			res.typeName = "__UnknownType" + strconv.Itoa(c.unknownTypesCount)
@@ -408,13 +556,25 @@ func (c *parseCtx) check(t reflect.Type, hasIDTag bool) (*obj, error) {
 		res.valueType = valueTypeObj
 		res.objContents = map[uint32]*obj{}
 
+		descriptions = getDescriptions(t)
+		deprecations = getDeprecations(t)
+		costs = getCosts(t)
+		res.description = descriptions[""]
+
 		typesInner := c.schema.types
 		typesInner[res.typeName] = &res
 		c.schema.types = typesInner
 
-		for i := 0; i < t.NumField(); i++ {
-			field := t.Field(i)
-			customName, obj, err := c.checkStructField(field, i)
+		for _, field := range structFields(t, false) {
+			if field.depth > 0 {
+				_, exists := res.objContents[getObjKey([]byte(structFieldQLName(&field.StructField)))]
+				if exists {
+					// Fields of the outer struct take precedence over promoted fields
+					continue
+				}
+			}
+
+			customName, obj, err := c.checkStructField(field.StructField, field.idx)
 			if err != nil {
 				return nil, err
 			}
@@ -424,6 +584,14 @@ func (c *parseCtx) check(t reflect.Type, hasIDTag bool) (*obj, error) {
 					name = *customName
 				}
 				obj.qlFieldName = []byte(name)
+				obj.embeddedPath = field.embeddedPath
+				obj.description = fieldDescription(&field.StructField, name, field.descriptions)
+				if obj.deprecationReason == nil {
+					obj.deprecationReason = deprecationReason(field.deprecations, name)
+				}
+				if obj.cost == nil {
+					obj.cost = fieldCost(field.costs, name)
+				}
 
 				res.objContents[getObjKey(obj.qlFieldName)] = obj
 			}
@@ -449,10 +617,16 @@ func (c *parseCtx) check(t reflect.Type, hasIDTag bool) (*obj, error) {
 			return nil, errors.New("inline interfaces not allowed")
 		}
 
-		newName, ok := renamedTypes[res.typeName]
-		if ok {
-			res.typeName = newName
-			res.typeNameBytes = []byte(newName)
+		union := c.schema.getUnion(t)
+		if union != nil {
+			res.typeName = union.typeName
+			res.typeNameBytes = []byte(union.typeName)
+		} else {
+			newName, ok := renamedTypes[res.typeName]
+			if ok {
+				res.typeName = newName
+				res.typeNameBytes = []byte(newName)
+			}
 		}
 
 		v, ok := c.schema.interfaces.Get(res.typeName)
@@ -466,6 +640,7 @@ func (c *parseCtx) check(t reflect.Type, hasIDTag bool) (*obj, error) {
 		}
 
 		res.valueType = valueTypeInterface
+		res.isUnion = union != nil
 		res.implementations = []*obj{}
 		res.objContents = map[uint32]*obj{}
 
@@ -479,9 +654,14 @@ func (c *parseCtx) check(t reflect.Type, hasIDTag bool) (*obj, error) {
 			methodPkgName = "inline interface"
 		}
 
-		typesThatImplementInterface, ok := implementationMap[t.Name()]
-		if !ok {
-			return nil, errors.New("cannot register a interface without explicit implementations")
+		var typesThatImplementInterface []reflect.Type
+		if union != nil {
+			typesThatImplementInterface = union.members
+		} else {
+			typesThatImplementInterface, ok = implementationMap[t.Name()]
+			if !ok {
+				return nil, errors.New("cannot register a interface without explicit implementations")
+			}
 		}
 		for _, interfaceType := range typesThatImplementInterface {
 			if interfaceType.Kind() != reflect.Struct {
@@ -501,7 +681,12 @@ func (c *parseCtx) check(t reflect.Type, hasIDTag bool) (*obj, error) {
 
 			res.implementations = append(res.implementations, obj)
 		}
-	case reflect.Func, reflect.Map, reflect.Chan, reflect.Invalid, reflect.Uintptr, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+	case reflect.Map:
+		if hasIDTag {
+			return nil, errors.New("maps cannot have ID attribute")
+		}
+		return c.checkMap(t)
+	case reflect.Func, reflect.Chan, reflect.Invalid, reflect.Uintptr, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
 		return nil, fmt.Errorf("unsupported value type %s", t.Kind().String())
 	default:
 		enumIndex, enum := c.schema.getEnum(t)
@@ -523,7 +708,7 @@ func (c *parseCtx) check(t reflect.Type, hasIDTag bool) (*obj, error) {
 	}
 
 	if res.valueType == valueTypeObj || res.valueType == valueTypeInterface {
-		for i := 0; i < t.NumMethod(); i++ {
+		for i := 0; i < t.NumMethod() && !res.isUnion; i++ {
 			method := t.Method(i)
 			methodObj, name, isID, err := c.checkFunction(method.Name, method.Type, true, false)
 			if err != nil {
@@ -541,6 +726,10 @@ func (c *parseCtx) check(t reflect.Type, hasIDTag bool) (*obj, error) {
 				structFieldIdx: i,
 				method:         methodObj,
 				isID:           isID,
+				description:    descriptions[name],
+
+				deprecationReason: deprecationReason(deprecations, name),
+				cost:              fieldCost(costs, name),
 			}
 		}
 
@@ -556,24 +745,26 @@ func (c *parseCtx) check(t reflect.Type, hasIDTag bool) (*obj, error) {
 }
 
 func (c *parseCtx) checkStructField(field reflect.StructField, idx int) (customName *string, obj *obj, err error) {
-	if field.Anonymous {
-		return nil, nil, nil
-	}
-
-	var ignore, isID bool
-	customName, ignore, isID, err = parseFieldTagGQ(&field)
-	if ignore || err != nil {
+	tag, err := parseFieldTagGQ(&field)
+	if tag.ignore || err != nil {
 		return nil, nil, err
 	}
+	if field.Anonymous && tag.name == nil {
+		// Embedded structs are promoted by structFields, other embedded types are skipped
+		return nil, nil, nil
+	}
+	customName = tag.name
 
 	if field.Type.Kind() == reflect.Func {
-		obj, err = c.checkStructFieldFunc(field.Name, field.Type, isID, idx)
+		obj, err = c.checkStructFieldFunc(field.Name, field.Type, tag.isID, idx)
 	} else {
-		obj, err = c.check(field.Type, isID)
+		obj, err = c.check(field.Type, tag.isID)
 	}
 
 	if obj != nil {
 		obj.structFieldIdx = idx
+		obj.deprecationReason = tag.deprecationReason
+		obj.cost = tag.cost
 	}
 	return
 }
@@ -600,37 +791,53 @@ func isCtx(t reflect.Type) bool {
 	return t.Kind() == reflect.Struct && ctxType.Name() == t.Name() && ctxType.PkgPath() == t.PkgPath()
 }
 
-func (c *parseCtx) checkFunctionInputStruct(field *reflect.StructField, idx int) (res input, skipThisField bool, err error) {
+func (c *parseCtx) checkFunctionInputStruct(field *reflect.StructField, idx int, descriptions map[string]string, deprecations map[string]string) (res input, skipThisField bool, err error) {
 	wrapErr := func(err error) error {
 		return fmt.Errorf("%s, struct field: %s", err.Error(), field.Name)
 	}
 
-	if field.Anonymous {
-		// skip field
-		return res, true, nil
-	}
-
-	newName, ignore, isID, err := parseFieldTagGQ(field)
-	if ignore {
+	tag, err := parseFieldTagGQ(field)
+	if tag.ignore {
 		// skip field
 		return res, true, nil
 	}
 	if err != nil {
 		return res, false, wrapErr(err)
 	}
+	if field.Anonymous && tag.name == nil {
+		if field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct && len(field.PkgPath) > 0 {
+			return res, false, wrapErr(errors.New("embedded pointers to unexported structs cannot be used as input, embed the struct without a pointer"))
+		}
+		// Embedded structs are promoted by structFields, other embedded types are skipped
+		return res, true, nil
+	}
 
 	qlFieldName := formatGoNameToQL(field.Name)
-	if newName != nil {
-		qlFieldName = *newName
+	if tag.name != nil {
+		qlFieldName = *tag.name
 	}
 
-	res, err = c.checkFunctionInput(field.Type, isID)
+	res, err = c.checkFunctionInput(field.Type, tag.isID)
 	if err != nil {
 		return input{}, false, wrapErr(err)
 	}
 
 	res.goFieldIdx = idx
 	res.gqFieldName = qlFieldName
+	res.description = fieldDescription(field, qlFieldName, descriptions)
+	if tag.defaultValue != nil {
+		err = c.parseDefaultValue(*tag.defaultValue, field.Type, &res)
+		if err != nil {
+			return input{}, false, wrapErr(err)
+		}
+	}
+	res.deprecationReason = tag.deprecationReason
+	if res.deprecationReason == nil {
+		res.deprecationReason = deprecationReason(deprecations, qlFieldName)
+	}
+	if res.deprecationReason != nil && inputIsRequired(&res) {
+		return input{}, false, wrapErr(errors.New("required arguments and input fields cannot be deprecated"))
+	}
 
 	return
 }
@@ -641,6 +848,13 @@ func (c *parseCtx) checkFunctionInput(t reflect.Type, hasIDTag bool) (input, err
 		kind: kind,
 	}
 
+	scalarIndex, scalar := c.schema.getScalar(t)
+	if scalar != nil {
+		res.isScalar = true
+		res.scalarIndex = scalarIndex
+		return res, nil
+	}
+
 	switch kind {
 	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
 		enumIndex, enum := c.schema.getEnum(t)
@@ -704,16 +918,28 @@ func (c *parseCtx) checkFunctionInput(t reflect.Type, hasIDTag bool) (input, err
 			c.schema.inTypes[structName] = &res
 
 			res.structName = structName
+			res.description = getDescriptions(t)[""]
 			res.structContent = map[string]input{}
-			for i := 0; i < t.NumField(); i++ {
-				field := t.Field(i)
-				input, skip, err := c.checkFunctionInputStruct(&field, i)
+			for _, field := range structFields(t, true) {
+				if field.depth > 0 {
+					_, exists := res.structContent[structFieldQLName(&field.StructField)]
+					if exists {
+						// Fields of the outer struct take precedence over promoted fields
+						continue
+					}
+				}
+
+				input, skip, err := c.checkFunctionInputStruct(&field.StructField, field.idx, field.descriptions, field.deprecations)
 				if skip {
 					continue
 				}
 				if err != nil {
 					return res, err
 				}
+				input.goEmbeddedPath = field.embeddedPath
+				if input.defaultValue != nil {
+					res.hasDefaultValues = true
+				}
 				res.structContent[input.gqFieldName] = input
 			}
 		}
@@ -723,7 +949,12 @@ func (c *parseCtx) checkFunctionInput(t reflect.Type, hasIDTag bool) (input, err
 			structName:       structName,
 			isStructPointers: true,
 		}, nil
-	case reflect.Map, reflect.Func:
+	case reflect.Map:
+		if hasIDTag {
+			return res, errors.New("maps cannot have ID attribute")
+		}
+		return c.checkMapInput(t)
+	case reflect.Func:
 		// TODO: maybe we can do something with these
 		fallthrough
 	default:
@@ -799,7 +1030,28 @@ func (c *parseCtx) checkFunction(name string, t reflect.Type, isTypeMethod bool,
 		return
 	}
 
-	outTypeObj, err = c.check(t.Out(*outNr), isID)
+	outType := t.Out(*outNr)
+	returnsChan := outType.Kind() == reflect.Chan
+	if returnsChan {
+		if outType.ChanDir()&reflect.RecvDir == 0 {
+			err = fmt.Errorf("%s returns a send only channel", name)
+			return
+		}
+		outType = outType.Elem()
+	}
+
+	returnsThunk := !returnsChan && outType.Kind() == reflect.Func
+	thunkHasError := false
+	if returnsThunk {
+		thunkHasError, err = checkThunk(outType)
+		if err != nil {
+			err = fmt.Errorf("%s %s", name, err.Error())
+			return
+		}
+		outType = outType.Out(0)
+	}
+
+	outTypeObj, err = c.check(outType, isID)
 	if err != nil {
 		return
 	}
@@ -813,11 +1065,36 @@ func (c *parseCtx) checkFunction(name string, t reflect.Type, isTypeMethod bool,
 		outNr:          *outNr,
 		outType:        *outTypeObj,
 		errorOutNr:     hasErrorOut,
+		returnsChan:    returnsChan,
+		returnsThunk:   returnsThunk,
+		thunkHasError:  thunkHasError,
 	}
 	c.parsedMethods = append(c.parsedMethods, res)
 	return res, formatGoNameToQL(trimmedName), isID, nil
 }
 
+// checkSubscriptionFields makes sure channels are only returned by fields on the subscription root
+// and that all fields on the subscription root return a channel
+func (c *parseCtx) checkSubscriptionFields() error {
+	rootFields := map[*objMethod]bool{}
+	if c.schema.rootSubscription != nil {
+		for _, field := range c.schema.rootSubscription.objContents {
+			if field.valueType != valueTypeMethod || !field.method.returnsChan {
+				return fmt.Errorf("subscription field %s must be a method that returns a channel", string(field.qlFieldName))
+			}
+			rootFields[field.method] = true
+		}
+	}
+
+	for _, method := range c.parsedMethods {
+		if method.returnsChan && !rootFields[method] {
+			return fmt.Errorf("%s returns a channel, this is only allowed for fields on the subscription root", method.goFunctionName)
+		}
+	}
+
+	return nil
+}
+
 func (c *parseCtx) checkFunctionIns(method *objMethod) error {
 	totalInputs := method.goType.NumIn()
 	for i := 0; i < totalInputs; i++ {
@@ -839,14 +1116,25 @@ func (c *parseCtx) checkFunctionIns(method *objMethod) error {
 			return fmt.Errorf("%s ctx argument must be a pointer", method.goFunctionName)
 		} else if typeKind == reflect.Struct {
 			input.goType = &goType
-			for i := 0; i < goType.NumField(); i++ {
-				field := goType.Field(i)
-				input, skip, err := c.checkFunctionInputStruct(&field, i)
+			for _, field := range structFields(goType, true) {
+				if field.depth > 0 {
+					_, exists := method.inFields[structFieldQLName(&field.StructField)]
+					if exists {
+						// Fields of the outer struct take precedence over promoted fields
+						continue
+					}
+				}
+
+				input, skip, err := c.checkFunctionInputStruct(&field.StructField, field.idx, field.descriptions, field.deprecations)
 				if skip {
 					continue
 				}
 				if err != nil {
-					return fmt.Errorf("%s, type %s (#%d)", err.Error(), goType.Name(), i)
+					return fmt.Errorf("%s, type %s (#%d)", err.Error(), goType.Name(), field.idx)
+				}
+				input.goEmbeddedPath = field.embeddedPath
+				if input.defaultValue != nil {
+					method.hasDefaultValues = true
 				}
 
 				method.inFields[input.gqFieldName] = referToInput{
@@ -878,7 +1166,17 @@ func formatGoNameToQL(input string) string {
 	return string(bytes.ToLower([]byte{input[0]})) + input[1:]
 }
 
-func parseFieldTagGQ(field *reflect.StructField) (newName *string, ignore bool, isID bool, err error) {
+// fieldTagGQ contains the parsed gq struct tag of a struct field
+type fieldTagGQ struct {
+	name              *string // Not nil if the tag contains a custom field name
+	ignore            bool
+	isID              bool
+	deprecationReason *string // Not nil if the field is deprecated
+	cost              *int    // Not nil if the tag contains a complexity cost
+	defaultValue      *string // Not nil if the tag contains a default value for a argument or input field
+}
+
+func parseFieldTagGQ(field *reflect.StructField) (tag fieldTagGQ, err error) {
 	val, ok := field.Tag.Lookup("gq")
 	if !ok {
 		return
@@ -891,17 +1189,54 @@ func parseFieldTagGQ(field *reflect.StructField) (newName *string, ignore bool,
 	nameArg := strings.TrimSpace(args[0])
 	if nameArg != "" {
 		if nameArg == "-" {
-			ignore = true
+			tag.ignore = true
 			return
 		}
 		err = validGraphQlName([]byte(nameArg))
-		newName = &nameArg
+		tag.name = &nameArg
 	}
 
-	for _, modifier := range args[1:] {
-		switch strings.ToLower(strings.TrimSpace(modifier)) {
+	for i := 1; i < len(args); i++ {
+		modifier := args[i]
+		key, value := modifier, ""
+		equalsIdx := strings.IndexByte(modifier, '=')
+		if equalsIdx != -1 {
+			key, value = modifier[:equalsIdx], modifier[equalsIdx+1:]
+		}
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
 		case "id":
-			isID = true
+			tag.isID = true
+		case "cost":
+			cost, parseErr := strconv.Atoi(strings.TrimSpace(value))
+			if parseErr != nil || cost < 0 {
+				err = fmt.Errorf("invalid field tag gq cost, expected a positive number: %s", modifier)
+				return
+			}
+			tag.cost = &cost
+		case "deprecated":
+			// The reason can contain commas so it continues until the next modifier
+			for i+1 < len(args) && !isFieldTagGQModifier(args[i+1]) {
+				i++
+				value += "," + args[i]
+			}
+			reason := strings.TrimSpace(value)
+			if len(reason) == 0 {
+				reason = defaultDeprecationReason
+			}
+			tag.deprecationReason = &reason
+		case "default":
+			// Lists, objects and strings can contain commas so the value continues until these are closed
+			for !defaultValueComplete(value) && i+1 < len(args) {
+				i++
+				value += "," + args[i]
+			}
+			value = strings.TrimSpace(value)
+			if len(value) == 0 {
+				err = fmt.Errorf("invalid field tag gq default, expected a value: %s", modifier)
+				return
+			}
+			tag.defaultValue = &value
 		default:
 			err = fmt.Errorf("unknown field tag gq argument: %s", modifier)
 			return
@@ -911,6 +1246,22 @@ func parseFieldTagGQ(field *reflect.StructField) (newName *string, ignore bool,
 	return
 }
 
+// isFieldTagGQModifier returns true if arg of a gq field tag starts a modifier like cost=5
+func isFieldTagGQModifier(arg string) bool {
+	key := arg
+	equalsIdx := strings.IndexByte(arg, '=')
+	if equalsIdx != -1 {
+		key = arg[:equalsIdx]
+	}
+
+	switch strings.ToLower(strings.TrimSpace(key)) {
+	case "id", "cost", "deprecated", "default":
+		return true
+	default:
+		return false
+	}
+}
+
 func validGraphQlName(name []byte) error {
 	if len(name) == 0 {
 		return errors.New("invalid graphql name")
@@ -947,17 +1298,23 @@ func checkValidIDKind(kind reflect.Kind) error {
 }
 
 func (s *Schema) objToQlTypeName(item *obj, target *bytes.Buffer) {
-	suffix := []byte{}
+	writeQlTypeName(wrapQLTypeInNonNull(s.objToQLType(item)), target)
+}
+
+func (s *Schema) inputToQlTypeName(in *input, target *bytes.Buffer) {
+	writeQlTypeName(wrapQLTypeInNonNull(s.inputToQLType(in)), target)
+}
 
-	qlType := wrapQLTypeInNonNull(s.objToQLType(item))
+func writeQlTypeName(qlType *qlType, target *bytes.Buffer) {
+	suffix := []byte{}
 
 	for {
 		switch qlType.Kind {
 		case typeKindList:
 			target.WriteByte('[')
-			suffix = append(suffix, ']')
+			suffix = append([]byte{']'}, suffix...)
 		case typeKindNonNull:
-			suffix = append(suffix, '!')
+			suffix = append([]byte{'!'}, suffix...)
 		default:
 			if qlType.Name != nil {
 				target.WriteString(*qlType.Name)
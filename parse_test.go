@@ -258,3 +258,35 @@ func TestReferenceLoop3(t *testing.T) {
 	_, err := newParseCtx().check(reflect.TypeOf(ReferToSelf3{}), false)
 	a.Nil(t, err)
 }
+
+type TestParseSubscriptionsData struct{}
+
+func (TestParseSubscriptionsData) ResolveCounter(args struct{ From int }) <-chan int {
+	return nil
+}
+
+type TestParseSubscriptionsInvalidFieldData struct {
+	Foo string
+}
+
+type TestParseSubscriptionsChanOutsideRootData struct {
+	Counter func() <-chan int
+}
+
+func TestParseSubscriptions(t *testing.T) {
+	s := NewSchema()
+	err := s.Parse(TestResolveEmptyQueryDataQ{}, M{}, &SchemaOptions{Subscriptions: TestParseSubscriptionsData{}})
+	a.NoError(t, err)
+	a.NotNil(t, s.rootSubscription)
+
+	field, ok := s.rootSubscription.objContents[getObjKey([]byte("counter"))]
+	a.True(t, ok)
+	a.True(t, field.method.returnsChan)
+	a.Equal(t, valueTypeData, field.method.outType.valueType)
+
+	err = NewSchema().Parse(TestResolveEmptyQueryDataQ{}, M{}, &SchemaOptions{Subscriptions: TestParseSubscriptionsInvalidFieldData{}})
+	a.Error(t, err)
+
+	err = NewSchema().Parse(TestParseSubscriptionsChanOutsideRootData{}, M{}, nil)
+	a.Error(t, err)
+}
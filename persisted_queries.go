@@ -0,0 +1,88 @@
+package yarql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// Automatic persisted queries, see https://www.apollographql.com/docs/apollo-server/performance/apq/
+//
+// Clients first send only the sha256 hash of a query, if the query is not known by the server
+// a PersistedQueryNotFound error is returned after which the client re-sends the hash together with the full query
+
+const (
+	persistedQueryNotFound     = "PersistedQueryNotFound"
+	persistedQueryNotSupported = "PersistedQueryNotSupported"
+
+	defaultPersistedQueriesMaxEntries = 1000
+)
+
+// PersistedQueryStore stores the queries of automatic persisted queries by their sha256 hash
+// The methods of a store are called from multiple goroutines at once so they must be safe for concurrent use
+type PersistedQueryStore interface {
+	// Get returns the query of a hash, found is false if the hash is not known
+	Get(hash string) (query string, found bool)
+	// Set stores a query, the hash is already verified to match the query
+	Set(hash string, query string)
+}
+
+// MemoryPersistedQueryStore is a PersistedQueryStore that keeps the queries in memory
+type MemoryPersistedQueryStore struct {
+	lock       sync.RWMutex
+	maxEntries int
+	queries    map[string]string
+}
+
+// NewMemoryPersistedQueryStore creates a new in memory persisted query store
+// If the store contains maxEntries queries a random query is dropped before a new one is stored
+func NewMemoryPersistedQueryStore(maxEntries int) *MemoryPersistedQueryStore {
+	return &MemoryPersistedQueryStore{
+		maxEntries: maxEntries,
+		queries:    map[string]string{},
+	}
+}
+
+// Get implements PersistedQueryStore
+func (s *MemoryPersistedQueryStore) Get(hash string) (string, bool) {
+	s.lock.RLock()
+	query, ok := s.queries[hash]
+	s.lock.RUnlock()
+	return query, ok
+}
+
+// Set implements PersistedQueryStore
+func (s *MemoryPersistedQueryStore) Set(hash string, query string) {
+	s.lock.Lock()
+	if _, ok := s.queries[hash]; !ok && s.maxEntries > 0 && len(s.queries) >= s.maxEntries {
+		for key := range s.queries {
+			delete(s.queries, key)
+			break
+		}
+	}
+	s.queries[hash] = query
+	s.lock.Unlock()
+}
+
+// persistedQuery resolves the query of a request that contains a persisted query hash
+// errMsg is set if the query cannot be resolved
+func (s *Schema) persistedQuery(query string, hash string) (resolvedQuery string, errMsg string) {
+	if s.persistedQueries == nil {
+		return "", persistedQueryNotSupported
+	}
+
+	if len(query) == 0 {
+		query, ok := s.persistedQueries.Get(hash)
+		if !ok {
+			return "", persistedQueryNotFound
+		}
+		return query, ""
+	}
+
+	sum := sha256.Sum256(s2b(query))
+	if hex.EncodeToString(sum[:]) != hash {
+		return "", "provided sha does not match query"
+	}
+	s.persistedQueries.Set(hash, query)
+	return query, ""
+}
@@ -0,0 +1,110 @@
+package yarql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+func persistedQueryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+func handlePersistedQueryRequest(s *Schema, body string) (string, []error) {
+	res, errs := s.HandleRequest(
+		"POST",
+		func(key string) string { return "" },
+		func(key string) (string, error) { return "", errors.New("this should not be called") },
+		func() []byte { return []byte(body) },
+		"application/json",
+		&RequestOptions{},
+	)
+	return string(res), errs
+}
+
+func TestPersistedQueries(t *testing.T) {
+	s := NewSchema()
+	err := s.Parse(TestResolveSchemaRequestWithFieldsData{A: TestResolveSchemaRequestWithFieldsDataInnerStruct{Bar: "baz"}}, M{}, nil)
+	a.NoError(t, err)
+
+	query := `{a {bar}}`
+	extensions := `"extensions":{"persistedQuery":{"version":1,"sha256Hash":"` + persistedQueryHash(query) + `"}}`
+
+	// The query is not yet known by the server
+	res, errs := handlePersistedQueryRequest(s, `{`+extensions+`}`)
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, `{"data":{},"errors":[{"message":"PersistedQueryNotFound"}],"extensions":{}}`, res)
+
+	// The client re-sends the query together with the hash
+	res, errs = handlePersistedQueryRequest(s, `{"query":"`+query+`",`+extensions+`}`)
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"a":{"bar":"baz"}}}`, res)
+
+	// Now only the hash is required
+	res, errs = handlePersistedQueryRequest(s, `{"query":null,`+extensions+`}`)
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"a":{"bar":"baz"}}}`, res)
+
+	// GET requests
+	getRes, errs := s.HandleRequest(
+		"GET",
+		func(key string) string {
+			if key == "extensions" {
+				return `{"persistedQuery":{"version":1,"sha256Hash":"` + persistedQueryHash(query) + `"}}`
+			}
+			return ""
+		},
+		func(key string) (string, error) { return "", errors.New("this should not be called") },
+		func() []byte { return nil },
+		"",
+		&RequestOptions{},
+	)
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"a":{"bar":"baz"}}}`, string(getRes))
+}
+
+func TestPersistedQueriesHashMismatch(t *testing.T) {
+	s := NewSchema()
+	err := s.Parse(TestResolveSchemaRequestWithFieldsData{A: TestResolveSchemaRequestWithFieldsDataInnerStruct{Bar: "baz"}}, M{}, nil)
+	a.NoError(t, err)
+
+	res, errs := handlePersistedQueryRequest(s, `{"query":"{a {bar}}","extensions":{"persistedQuery":{"version":1,"sha256Hash":"`+persistedQueryHash("{a {foo}}")+`"}}}`)
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, `{"data":{},"errors":[{"message":"provided sha does not match query"}],"extensions":{}}`, res)
+
+	_, errs = handlePersistedQueryRequest(s, `{"extensions":{"persistedQuery":{"version":2,"sha256Hash":"abc"}}}`)
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "unsupported persisted query version", errs[0].Error())
+}
+
+func TestPersistedQueriesDisabled(t *testing.T) {
+	s := NewSchema()
+	err := s.Parse(TestResolveSchemaRequestWithFieldsData{A: TestResolveSchemaRequestWithFieldsDataInnerStruct{Bar: "baz"}}, M{}, &SchemaOptions{DisablePersistedQueries: true})
+	a.NoError(t, err)
+
+	res, errs := handlePersistedQueryRequest(s, `{"extensions":{"persistedQuery":{"version":1,"sha256Hash":"abc"}}}`)
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, `{"data":{},"errors":[{"message":"PersistedQueryNotSupported"}],"extensions":{}}`, res)
+}
+
+func TestPersistedQueriesCustomStore(t *testing.T) {
+	query := `{a {bar}}`
+	store := NewMemoryPersistedQueryStore(1)
+	store.Set(persistedQueryHash(query), query)
+
+	s := NewSchema()
+	err := s.Parse(TestResolveSchemaRequestWithFieldsData{A: TestResolveSchemaRequestWithFieldsDataInnerStruct{Bar: "baz"}}, M{}, &SchemaOptions{PersistedQueries: store})
+	a.NoError(t, err)
+	res, errs := handlePersistedQueryRequest(s, `{"extensions":{"persistedQuery":{"version":1,"sha256Hash":"`+persistedQueryHash(query)+`"}}}`)
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"a":{"bar":"baz"}}}`, res)
+
+	// The store is full so the previous query is dropped
+	store.Set("foo", "{a {foo}}")
+	_, ok := store.Get(persistedQueryHash(query))
+	a.False(t, ok)
+}
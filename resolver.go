@@ -26,11 +26,12 @@ type Ctx struct {
 	context                  *context.Context
 	path                     []byte
 	getFormFile              func(key string) (*multipart.FileHeader, error) // Get form file to support file uploading
+	result                   []byte                                          // The response json is written to this buffer
 	operatorHasArguments     bool
 	operatorArgumentsStartAt int
-	tracingEnabled           bool
-	tracing                  *tracer
-	prefRecordingStartTime   time.Time
+	tracer                   Tracer  // nil if nothing is traced
+	tracingEnabled           bool    // apollo tracing is enabled
+	tracing                  *tracer // apollo tracing data
 
 	rawVariables        string
 	variablesParsed     bool             // the rawVariables are parsed into variables
@@ -43,6 +44,41 @@ type Ctx struct {
 	funcInputs             []reflect.Value
 	ctxReflection          reflect.Value // ptr to the value
 
+	// Subscription state, only used by (*Schema).Subscribe
+	subscribing        bool          // subscription operations are allowed
+	subscriptionSource reflect.Value // the channel returned by the subscription field, invalid until the field is resolved
+	subscriptionValue  reflect.Value // the value received from the source channel that is currently being resolved
+
+	// Validation state, re-used between requests
+	validator validator
+
+	// Used to convert query values into JSON for custom scalars
+	scalarArena fastjson.Arena
+
+	// Query complexity
+	maxComplexity int // 0 if the complexity should not be calculated
+	complexity    int // The complexity of the executed operation, -1 if not calculated
+
+	// Loaders, see loader.go
+	loaders        map[*Loader]*loaderState // The state of the loaders used within this request
+	deferring      bool                     // Fields that return a thunk are deferred until all list items are resolved
+	deferredFields []deferredField
+
+	// The Res index of the field that is being resolved, used for the location of errors, -1 if not resolving a field
+	currentField int
+
+	// User defined response extensions, see (*Ctx).SetExtension
+	extensions []extension
+
+	// Null propagation, see null_propagation.go
+	nullablePathLen int      // The length of the path of the nearest nullable value
+	nullPaths       [][]byte // The paths of values that should be replaced with null
+
+	// Parallel execution, see parallel.go
+	parallel       bool
+	parallelFields []parallelField
+	ownQuery       bytecode.ParserCtx // The query buffers of a forked ctx, the query of a forked ctx is shared with the ctx it's forked from
+
 	// public / kinda public fields
 	values *map[string]interface{} // API User values, user can put all their shitty things in here like poems or tax papers
 }
@@ -56,11 +92,25 @@ func newCtx(s *Schema) *Ctx {
 		currentReflectValueIdx: 0,
 		variablesJSONParser:    &fastjson.Parser{},
 		tracing:                newTracer(),
+		result:                 make([]byte, 0, 16384),
+	}
+	if s.ctx != nil {
+		ctx.query.CacheableQueryMinLen = s.ctx.query.CacheableQueryMinLen
 	}
 	ctx.ctxReflection = reflect.ValueOf(ctx)
 	return ctx
 }
 
+// getPooledCtx returns a context from the schema's context pool
+// The context should be returned to the pool using (*Schema).ctxPool.Put after it's no longer used
+func (s *Schema) getPooledCtx() *Ctx {
+	ctx, ok := s.ctxPool.Get().(*Ctx)
+	if !ok {
+		ctx = newCtx(s)
+	}
+	return ctx
+}
+
 func (ctx *Ctx) getGoValue() reflect.Value {
 	return ctx.reflectValues[ctx.currentReflectValueIdx]
 }
@@ -130,11 +180,11 @@ func (ctx *Ctx) GetPath() json.RawMessage {
 }
 
 func (ctx *Ctx) write(b []byte) {
-	ctx.schema.Result = append(ctx.schema.Result, b...)
+	ctx.result = append(ctx.result, b...)
 }
 
 func (ctx *Ctx) writeByte(b byte) {
-	ctx.schema.Result = append(ctx.schema.Result, b)
+	ctx.result = append(ctx.result, b)
 }
 
 func (ctx *Ctx) writeQuoted(b []byte) {
@@ -158,96 +208,202 @@ type ResolveOptions struct {
 	GetFormFile    func(key string) (*multipart.FileHeader, error) // Get form file to support file uploading
 	Variables      string                                          // Expects valid JSON or empty string
 	Tracing        bool                                            // https://github.com/apollographql/apollo-tracing
+	Tracer         Tracer                                          // Receives spans of the request, defaults to SchemaOptions.Tracer
+	MaxComplexity  int                                             // Reject queries with a higher complexity, 0 means no limit
+	Extensions     map[string]interface{}                          // Added to the extensions of the response, see (*Ctx).SetExtension
+	Parallel       bool                                            // Resolve the method fields of a selection set concurrently, mutation root fields are always resolved serially
+
+	trustedDocument *trustedDocument // The already parsed query, set by (*Schema).HandleRequest
+	validateOnly    bool             // Only parse and validate the query, set by (*Schema).requestErrors
 }
 
 // Resolve resolves a query and returns errors if any
 // The result json is written to (*Schema).Result
+//
+// Resolve is not safe to be used from multiple goroutines at once, use (*Schema).ResolveConcurrent for that
 func (s *Schema) Resolve(query []byte, opts ResolveOptions) []error {
 	if !s.parsed {
 		fmt.Println("CALL (*yarql.Schema).Parse() before resolve")
 		return []error{errors.New("invalid setup")}
 	}
 
-	s.Result = s.Result[:0]
-
 	ctx := s.ctx
+	ctx.result = s.Result[:0]
+	errs := ctx.resolve(query, opts)
+	s.Result = ctx.result
+	return errs
+}
+
+// Response contains the result of (*Schema).ResolveConcurrent
+type Response struct {
+	// Result contains the response json
+	// Note that this value is only valid until (*Response).Release is called
+	Result []byte
+
+	ctx *Ctx
+}
+
+// Release hands the resources used by this response back to the schema so they can be reused by other requests
+// After calling Release the Result cannot be used anymore
+func (r *Response) Release() {
+	ctx := r.ctx
+	if ctx == nil {
+		return
+	}
+	r.ctx = nil
+	r.Result = nil
+	ctx.schema.ctxPool.Put(ctx)
+}
+
+// ResolveConcurrent resolves a query just like (*Schema).Resolve but can safely be used from multiple goroutines at once
+// The request context, query parser and result buffer are taken from a pool that is shared by all requests on this schema
+//
+// The returned response should be released after the result is used
+func (s *Schema) ResolveConcurrent(query []byte, opts ResolveOptions) (*Response, []error) {
+	if !s.parsed {
+		fmt.Println("CALL (*yarql.Schema).Parse() before resolve")
+		return &Response{Result: []byte(`{"data":{},"errors":[{"message":"invalid setup"}],"extensions":{}}`)}, []error{errors.New("invalid setup")}
+	}
+
+	ctx := s.getPooledCtx()
+	ctx.result = ctx.result[:0]
+	errs := ctx.resolve(query, opts)
+	if len(errs) > 0 {
+		// The errors slice is re-used by the next request that uses this ctx
+		errs = append([]error(nil), errs...)
+	}
+
+	// The response is not part of the pooled ctx so a stale or second Release cannot release the ctx while it's used by another request
+	return &Response{
+		Result: ctx.result,
+		ctx:    ctx,
+	}, errs
+}
+
+func (ctx *Ctx) resolve(query []byte, opts ResolveOptions) []error {
 	*ctx = Ctx{
-		schema:                 ctx.schema,
-		query:                  ctx.query,
-		charNr:                 0,
-		context:                nil,
-		path:                   ctx.path[:0],
-		getFormFile:            opts.GetFormFile,
-		rawVariables:           opts.Variables,
-		variablesParsed:        false,
-		variablesJSONParser:    ctx.variablesJSONParser,
-		variables:              ctx.variables,
-		tracingEnabled:         opts.Tracing,
-		tracing:                ctx.tracing,
-		prefRecordingStartTime: ctx.prefRecordingStartTime,
-		ctxReflection:          ctx.ctxReflection,
+		schema:              ctx.schema,
+		query:               ctx.query,
+		charNr:              0,
+		context:             nil,
+		path:                ctx.path[:0],
+		getFormFile:         opts.GetFormFile,
+		result:              ctx.result,
+		rawVariables:        opts.Variables,
+		variablesParsed:     false,
+		variablesJSONParser: ctx.variablesJSONParser,
+		variables:           ctx.variables,
+		tracer:              ctx.schema.requestTracer(&opts),
+		tracingEnabled:      opts.Tracing,
+		tracing:             ctx.tracing,
+		ctxReflection:       ctx.ctxReflection,
 
 		reflectValues:          ctx.reflectValues,
 		currentReflectValueIdx: 0,
 		funcInputs:             ctx.funcInputs,
 
 		values: opts.Values,
-	}
-	if opts.Tracing {
-		ctx.tracing.reset()
+
+		subscribing: ctx.subscribing,
+
+		validator:   ctx.validator,
+		scalarArena: ctx.scalarArena,
+
+		maxComplexity: opts.MaxComplexity,
+		complexity:    -1,
+
+		currentField: -1,
+		extensions:   ctx.extensions[:0],
+		nullPaths:    ctx.nullPaths[:0],
+
+		deferredFields: ctx.deferredFields[:0],
+		parallel:       opts.Parallel,
+		parallelFields: ctx.parallelFields[:0],
 	}
 	if opts.Context != nil {
 		ctx.context = &opts.Context
 	}
-	ctx.startTrace()
+	ctx.setExtensions(opts.Extensions)
 
-	ctx.query.Query = append(ctx.query.Query[:0], query...)
+	var operationSpan, parsingSpan Span
+	if ctx.tracer != nil {
+		operationSpan = ctx.tracer.StartOperation(ctx, query, opts.OperatorTarget)
+		parsingSpan = ctx.tracer.StartParsing(ctx)
+	}
 
-	if len(opts.OperatorTarget) > 0 {
-		ctx.query.ParseQueryToBytecode(&opts.OperatorTarget)
+	if opts.trustedDocument != nil {
+		opts.trustedDocument.load(ctx, opts.OperatorTarget)
 	} else {
-		ctx.query.ParseQueryToBytecode(nil)
+		ctx.query.Query = append(ctx.query.Query[:0], query...)
+
+		if len(opts.OperatorTarget) > 0 {
+			ctx.query.ParseQueryToBytecode(&opts.OperatorTarget)
+		} else {
+			ctx.query.ParseQueryToBytecode(nil)
+		}
 	}
 
-	if ctx.tracingEnabled {
-		// finish parsing trace
-		ctx.finishTrace(func(offset, duration int64) {
-			ctx.tracing.Parsing.StartOffset = offset
-			ctx.tracing.Parsing.Duration = duration
-		})
+	if parsingSpan != nil {
+		parsingSpan.End()
+	}
+
+	if len(ctx.query.Errors) == 0 {
+		var validationSpan Span
+		if ctx.tracer != nil {
+			validationSpan = ctx.tracer.StartValidation(ctx)
+		}
+		ctx.validate()
+		if validationSpan != nil {
+			validationSpan.End()
+		}
+	}
+
+	if len(ctx.query.Errors) == 0 && ctx.maxComplexity > 0 {
+		ctx.checkComplexity()
+	}
 
-		// Set the validation prop
-		ctx.tracing.Validation.StartOffset = time.Now().Sub(ctx.prefRecordingStartTime).Nanoseconds()
+	if opts.validateOnly {
+		if len(ctx.query.Errors) == 0 && ctx.query.TargetIdx == -1 {
+			ctx.operatorNotFoundErr(opts.OperatorTarget)
+		}
+		return ctx.query.Errors
+	}
+
+	ctx.execute(opts)
+	if operationSpan != nil {
+		operationSpan.End()
 	}
+	return ctx.query.Errors
+}
 
+// execute resolves the parsed query and writes the response to ctx.result
+func (ctx *Ctx) execute(opts ResolveOptions) {
 	if !opts.NoMeta {
 		ctx.write([]byte(`{"data":`))
 	}
+	dataStart := len(ctx.result)
 
 	if len(ctx.query.Errors) == 0 {
 		ctx.charNr = ctx.query.TargetIdx
 		if ctx.charNr == -1 {
 			ctx.write([]byte("{}"))
-			if len(opts.OperatorTarget) > 0 {
-				ctx.err("no operator with name " + opts.OperatorTarget + " found")
-			} else {
-				ctx.err("no operator found")
-			}
+			ctx.operatorNotFoundErr(opts.OperatorTarget)
 		} else {
 			ctx.writeByte('{')
 			ctx.resolveOperation()
 			ctx.writeByte('}')
+			if len(ctx.nullPaths) > 0 {
+				ctx.propagateNulls(dataStart)
+			}
 		}
 	} else {
 		ctx.write([]byte("{}"))
 	}
 
 	if !opts.NoMeta {
-		// TODO support custom extensions
-
 		// Add errors to output
 		errsLen := len(ctx.query.Errors)
-		if errsLen == 0 && !ctx.tracingEnabled {
+		if errsLen == 0 && !ctx.hasExtensions() {
 			ctx.write([]byte(`}`))
 		} else {
 			if errsLen != 0 {
@@ -256,45 +412,199 @@ func (s *Schema) Resolve(query []byte, opts ResolveOptions) []error {
 					if i > 0 {
 						ctx.writeByte(',')
 					}
-					ctx.write([]byte(`{"message":`))
-					helpers.StringToJSON(err.Error(), &ctx.schema.Result)
-
-					errWPath, isErrWPath := err.(ErrorWPath)
-					if isErrWPath && len(errWPath.path) > 0 {
-						ctx.write([]byte(`,"path":[`))
-						ctx.write(errWPath.path)
-						ctx.writeByte(']')
-					}
-					errWLocation, isErrWLocation := err.(bytecode.ErrorWLocation)
-					if isErrWLocation {
-						ctx.write([]byte(`,"locations":[{"line":`))
-						ctx.schema.Result = strconv.AppendUint(ctx.schema.Result, uint64(errWLocation.Line), 10)
-						ctx.write([]byte(`,"column":`))
-						ctx.schema.Result = strconv.AppendUint(ctx.schema.Result, uint64(errWLocation.Column), 10)
-						ctx.write([]byte{'}', ']'})
-					}
-					ctx.writeByte('}')
+					ctx.writeError(err)
 				}
 				ctx.writeByte(']')
 			}
 
-			if ctx.tracingEnabled {
-				ctx.write([]byte(`,"extensions":{"tracing":`))
-				ctx.tracing.finish()
-				tracingJSON, err := json.Marshal(ctx.tracing)
-				if err == nil {
-					ctx.write(tracingJSON)
-				} else {
-					ctx.writeNull()
-				}
-				ctx.write([]byte{'}', '}'})
-			} else {
-				ctx.write([]byte(`,"extensions":{}}`))
+			ctx.write([]byte(`,"extensions":{`))
+			ctx.writeExtensions()
+			ctx.write([]byte{'}', '}'})
+		}
+	}
+}
+
+// writeError writes a error of the response
+func (ctx *Ctx) writeError(err error) {
+	ctx.write([]byte(`{"message":`))
+	helpers.StringToJSON(err.Error(), &ctx.result)
+
+	writeLocation := func(line, column uint) {
+		ctx.write([]byte(`,"locations":[{"line":`))
+		ctx.result = strconv.AppendUint(ctx.result, uint64(line), 10)
+		ctx.write([]byte(`,"column":`))
+		// Columns are stored 0 based while graphql columns start at 1
+		ctx.result = strconv.AppendUint(ctx.result, uint64(column)+1, 10)
+		ctx.write([]byte{'}', ']'})
+	}
+
+	switch typedErr := err.(type) {
+	case bytecode.ErrorWLocation:
+		writeLocation(typedErr.Line, typedErr.Column)
+	case ErrorWPath:
+		if typedErr.hasLocation {
+			writeLocation(typedErr.line, typedErr.column)
+		}
+		if len(typedErr.path) > 0 {
+			ctx.write([]byte(`,"path":[`))
+			ctx.write(typedErr.path)
+			ctx.writeByte(']')
+		}
+	}
+
+	var errWithExtensions ErrorWithExtensions
+	if errors.As(err, &errWithExtensions) {
+		extensions := errWithExtensions.Extensions()
+		if len(extensions) > 0 {
+			extensionsJSON, err := json.Marshal(extensions)
+			if err == nil {
+				ctx.write([]byte(`,"extensions":`))
+				ctx.write(extensionsJSON)
 			}
 		}
 	}
+	ctx.writeByte('}')
+}
 
-	return ctx.query.Errors
+// Subscribe resolves a subscription operation
+// Every value send over the channel returned by the subscription field results in a response json on the returned channel
+// The returned channel is closed when the source channel is closed, opts.Context is done or cancel is called
+// Queries, mutations and invalid queries are resolved once after which the returned channel is closed
+//
+// The go context of the subscription is cancelled when the subscription ends,
+// resolvers can use (*Ctx).GetContext().Done() to stop sending values
+func (s *Schema) Subscribe(query []byte, opts ResolveOptions) (responses <-chan []byte, cancel func()) {
+	res := make(chan []byte, 1)
+	if !s.parsed {
+		fmt.Println("CALL (*yarql.Schema).Parse() before subscribe")
+		res <- []byte(`{"data":{},"errors":[{"message":"invalid setup"}],"extensions":{}}`)
+		close(res)
+		return res, func() {}
+	}
+
+	goContext := opts.Context
+	if goContext == nil {
+		goContext = context.Background()
+	}
+	goContext, cancel = context.WithCancel(goContext)
+	opts.Context = goContext
+
+	// Subscriptions live longer than a single request and resolvers might hold on to the ctx so it's not taken from the pool
+	ctx := newCtx(s)
+	ctx.subscribing = true
+	ctx.resolve(query, opts)
+	if !ctx.subscriptionSource.IsValid() || len(ctx.query.Errors) > 0 {
+		// This is not a subscription or the subscription could not be started
+		res <- ctx.result
+		close(res)
+		cancel()
+		return res, cancel
+	}
+
+	go func() {
+		defer close(res)
+		defer cancel()
+
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: ctx.subscriptionSource},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(goContext.Done())},
+		}
+		for {
+			chosen, value, ok := reflect.Select(cases)
+			if chosen != 0 || !ok {
+				// The context is done or the source channel is closed
+				return
+			}
+
+			ctx.resolveSubscriptionValue(value, opts)
+			result := make([]byte, len(ctx.result))
+			copy(result, ctx.result)
+
+			select {
+			case res <- result:
+			case <-goContext.Done():
+				return
+			}
+		}
+	}()
+
+	return res, cancel
+}
+
+// operationKind returns the kind of operation (query, mutation or subscription) the query would execute
+// ok is false if the query contains errors or the operation cannot be found
+func (s *Schema) operationKind(query []byte, operationName string) (kind bytecode.OperatorKind, ok bool) {
+	ctx := s.getPooledCtx()
+	defer s.ctxPool.Put(ctx)
+
+	ctx.query.Query = append(ctx.query.Query[:0], query...)
+	if len(operationName) > 0 {
+		ctx.query.ParseQueryToBytecode(&operationName)
+	} else {
+		ctx.query.ParseQueryToBytecode(nil)
+	}
+	if len(ctx.query.Errors) > 0 || ctx.query.TargetIdx == -1 {
+		return 0, false
+	}
+
+	// The operation starts with: 0 [ActionOperator] [kind]
+	return ctx.query.Res[ctx.query.TargetIdx+2], true
+}
+
+// operatorNotFoundErr adds the error for a query without the targeted operator
+func (ctx *Ctx) operatorNotFoundErr(target string) {
+	if len(target) > 0 {
+		ctx.err("no operator with name " + target + " found")
+	} else {
+		ctx.err("no operator found")
+	}
+}
+
+// requestErrors parses and validates the query without executing it
+// Returns the errors as a json array or nil if the query can be executed
+func (s *Schema) requestErrors(query []byte, opts ResolveOptions) []byte {
+	ctx := s.getPooledCtx()
+	defer s.ctxPool.Put(ctx)
+
+	opts.validateOnly = true
+	opts.Tracing = false
+	ctx.result = ctx.result[:0]
+	errs := ctx.resolve(query, opts)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	ctx.writeByte('[')
+	for i, err := range errs {
+		if i > 0 {
+			ctx.writeByte(',')
+		}
+		ctx.writeError(err)
+	}
+	ctx.writeByte(']')
+	return append([]byte{}, ctx.result...)
+}
+
+// resolveSubscriptionValue resolves the already parsed subscription query using a value received from the source channel
+func (ctx *Ctx) resolveSubscriptionValue(value reflect.Value, opts ResolveOptions) {
+	ctx.result = ctx.result[:0]
+	ctx.path = ctx.path[:0]
+	ctx.currentReflectValueIdx = 0
+	ctx.query.Errors = ctx.query.Errors[:0]
+	ctx.subscriptionValue = value
+	ctx.loaders = nil
+	ctx.extensions = ctx.extensions[:0]
+	ctx.setExtensions(opts.Extensions)
+	ctx.nullPaths = ctx.nullPaths[:0]
+
+	var operationSpan Span
+	if ctx.tracer != nil {
+		operationSpan = ctx.tracer.StartOperation(ctx, ctx.query.Query, opts.OperatorTarget)
+	}
+	ctx.execute(opts)
+	if operationSpan != nil {
+		operationSpan.End()
+	}
 }
 
 // readInst reads the current instruction and increments the charNr
@@ -316,29 +626,84 @@ func (ctx *Ctx) lastInst() byte {
 	return ctx.query.Res[ctx.charNr-1]
 }
 
+// ErrorWithExtensions can be implemented by errors returned from resolvers to add extensions to the error in the response
+// The extensions are also found if the error is wrapped using fmt.Errorf("...: %w", err)
+//
+// Example:
+//
+//	type AuthError struct{}
+//
+//	func (AuthError) Error() string { return "not logged in" }
+//	func (AuthError) Extensions() map[string]interface{} {
+//		return map[string]interface{}{"code": "UNAUTHENTICATED"}
+//	}
+type ErrorWithExtensions interface {
+	error
+	Extensions() map[string]interface{}
+}
+
 // ErrorWPath is an error mesage with a graphql path to the field that created the error
 type ErrorWPath struct {
 	err  error
 	path []byte // a json representation of the path without the [] around it
+
+	// The location of the field in the query, only set if hasLocation is true
+	hasLocation bool
+	line        uint
+	column      uint
 }
 
 func (e ErrorWPath) Error() string {
 	return e.err.Error()
 }
 
+// Unwrap returns the original error
+func (e ErrorWPath) Unwrap() error {
+	return e.err
+}
+
+// Path returns the graphql path to the field json encoded
+func (e ErrorWPath) Path() json.RawMessage {
+	return append(append([]byte{'['}, e.path...), ']')
+}
+
+// Location returns the line and column of the field in the query, ok is false if the location is not known
+// Like bytecode.ErrorWLocation the column starts at 0, in the response columns start at 1
+func (e ErrorWPath) Location() (line uint, column uint, ok bool) {
+	return e.line, e.column, e.hasLocation
+}
+
+// Extensions returns the extensions of the original error if it or one of the errors it wraps implements ErrorWithExtensions
+func (e ErrorWPath) Extensions() map[string]interface{} {
+	var errWithExtensions ErrorWithExtensions
+	if !errors.As(e.err, &errWithExtensions) {
+		return nil
+	}
+	return errWithExtensions.Extensions()
+}
+
 func (ctx *Ctx) err(msg string) bool {
-	err := errors.New(msg)
+	return ctx.addErr(errors.New(msg))
+}
+
+// addErr adds a error with the path and location of the field that is being resolved
+func (ctx *Ctx) addErr(err error) bool {
 	if len(ctx.path) == 0 {
 		ctx.query.Errors = append(ctx.query.Errors, err)
-	} else {
-		copiedPath := make([]byte, len(ctx.path)-1)
-		copy(copiedPath, ctx.path[1:])
+		return true
+	}
 
-		ctx.query.Errors = append(ctx.query.Errors, ErrorWPath{
-			err:  err,
-			path: copiedPath,
-		})
+	copiedPath := make([]byte, len(ctx.path)-1)
+	copy(copiedPath, ctx.path[1:])
+
+	errWPath := ErrorWPath{
+		err:  err,
+		path: copiedPath,
+	}
+	if ctx.currentField != -1 {
+		errWPath.line, errWPath.column, errWPath.hasLocation = ctx.query.LocationOf(ctx.currentField)
 	}
+	ctx.query.Errors = append(ctx.query.Errors, errWPath)
 	return true
 }
 
@@ -364,15 +729,17 @@ func (ctx *Ctx) resolveOperation() bool {
 	case bytecode.OperatorMutation:
 		ctx.reflectValues[0] = ctx.schema.rootMethodValue
 	case bytecode.OperatorSubscription:
-		return ctx.err("subscriptions are not supported")
+		if ctx.schema.rootSubscription == nil {
+			return ctx.err("subscriptions are not supported")
+		}
+		if !ctx.subscribing {
+			return ctx.err("subscriptions can only be resolved using (*Schema).Subscribe")
+		}
+		ctx.reflectValues[0] = ctx.schema.rootSubscriptionValue
 	}
 
 	ctx.operatorHasArguments = ctx.readInst() == 't'
 	directivesCount := ctx.readInst()
-	if directivesCount > 0 {
-		// TODO
-		return ctx.err("operation directives unsupported")
-	}
 
 	for {
 		// Read name
@@ -389,14 +756,51 @@ func (ctx *Ctx) resolveOperation() bool {
 		ctx.skipInst(int(argumentsLen) + 5)
 	}
 
+	if directivesCount > 0 {
+		location := DirectiveLocationQuery
+		switch kind {
+		case bytecode.OperatorMutation:
+			location = DirectiveLocationMutation
+		case bytecode.OperatorSubscription:
+			location = DirectiveLocationSubscription
+		}
+
+		for i := uint8(0); i < directivesCount; i++ {
+			modifier, criticalErr := ctx.resolveDirective(location)
+			if criticalErr || modifier.Skip {
+				return criticalErr
+			}
+			for key, value := range modifier.Values {
+				ctx.SetValue(key, value)
+			}
+		}
+	}
+
 	firstField := true
-	if kind == bytecode.OperatorMutation {
+	switch kind {
+	case bytecode.OperatorMutation:
 		return ctx.resolveSelectionSet(ctx.schema.rootMethod, 0, &firstField)
+	case bytecode.OperatorSubscription:
+		criticalErr := ctx.resolveSelectionSet(ctx.schema.rootSubscription, 0, &firstField)
+		if !criticalErr && !ctx.subscriptionSource.IsValid() {
+			return ctx.err("subscription operations must select exactly one top level field")
+		}
+		return criticalErr
+	default:
+		return ctx.resolveSelectionSet(ctx.schema.rootQuery, 0, &firstField)
 	}
-	return ctx.resolveSelectionSet(ctx.schema.rootQuery, 0, &firstField)
 }
 
 func (ctx *Ctx) resolveSelectionSet(typeObj *obj, dept uint8, firstField *bool) bool {
+	parallelFieldsStart := len(ctx.parallelFields)
+	criticalErr := ctx.resolveSelectionSetFields(typeObj, dept, firstField)
+	if ctx.waitForParallelFields(parallelFieldsStart) {
+		criticalErr = true
+	}
+	return criticalErr
+}
+
+func (ctx *Ctx) resolveSelectionSetFields(typeObj *obj, dept uint8, firstField *bool) bool {
 	for {
 		switch ctx.readInst() {
 		case bytecode.ActionEnd:
@@ -422,6 +826,20 @@ func (ctx *Ctx) resolveSelectionSet(typeObj *obj, dept uint8, firstField *bool)
 	}
 }
 
+// typeConditionMatches returns true if a fragment with the type condition typeName applies to typeObj
+// This is the case if typeName is the name of typeObj or the name of a interface typeObj implements
+func typeConditionMatches(typeObj *obj, typeName []byte) bool {
+	if bytes.Equal(typeObj.typeNameBytes, typeName) {
+		return true
+	}
+	for _, implementation := range typeObj.implementations {
+		if implementation.typeName == b2s(typeName) {
+			return true
+		}
+	}
+	return false
+}
+
 func (ctx *Ctx) resolveSpread(typeObj *obj, dept uint8, firstField *bool) bool {
 	isInline := ctx.readInst() == 't'
 	directivesCount := ctx.readInst()
@@ -457,7 +875,7 @@ func (ctx *Ctx) resolveSpread(typeObj *obj, dept uint8, firstField *bool) bool {
 	}
 
 	if isInline {
-		if !bytes.Equal(typeObj.typeNameBytes, name) {
+		if !typeConditionMatches(typeObj, name) {
 			ctx.charNr = nameStart + int(lenOfDirective) + 1
 			return false
 		}
@@ -489,7 +907,7 @@ func (ctx *Ctx) resolveSpread(typeObj *obj, dept uint8, firstField *bool) bool {
 				}
 			}
 
-			if !bytes.Equal(typeObj.typeNameBytes, ctx.query.Res[typeNameStart:typeNameEnd]) {
+			if !typeConditionMatches(typeObj, ctx.query.Res[typeNameStart:typeNameEnd]) {
 				ctx.charNr = nameStart + int(lenOfDirective) + 1
 				return false
 			}
@@ -504,7 +922,9 @@ func (ctx *Ctx) resolveSpread(typeObj *obj, dept uint8, firstField *bool) bool {
 }
 
 func (ctx *Ctx) resolveField(typeObj *obj, dept uint8, addCommaBefore bool) (skipped bool, criticalErr bool) {
-	ctx.startTrace()
+	// The field instruction starts 2 bytes back: 0 [ActionField]
+	prevField := ctx.currentField
+	ctx.currentField = ctx.charNr - 2
 
 	directivesCount := ctx.readInst()
 
@@ -539,6 +959,7 @@ func (ctx *Ctx) resolveField(typeObj *obj, dept uint8, addCommaBefore bool) (ski
 	}
 	ctx.skipInst(1)
 
+	var contentModifiers []ModifyOnWriteContent
 	if directivesCount != 0 {
 		for i := uint8(0); i < directivesCount; i++ {
 			modifier, criticalErr := ctx.resolveDirective(DirectiveLocationField)
@@ -546,15 +967,15 @@ func (ctx *Ctx) resolveField(typeObj *obj, dept uint8, addCommaBefore bool) (ski
 			if criticalErr || modifier.Skip {
 				// Restore the path
 				ctx.path = ctx.path[:prefPathLen]
+				ctx.currentField = prevField
 				ctx.charNr = endOfField + 1
 
 				return true, criticalErr
 			}
 
-			// TODO
-			// if modifier.ModifyOnWriteContent != nil {
-			// 	contentModifiers = append(contentModifiers, modifier.ModifyOnWriteContent)
-			// }
+			if modifier.ModifyOnWriteContent != nil {
+				contentModifiers = append(contentModifiers, modifier.ModifyOnWriteContent)
+			}
 		}
 	}
 
@@ -574,7 +995,11 @@ func (ctx *Ctx) resolveField(typeObj *obj, dept uint8, addCommaBefore bool) (ski
 			if fieldHasSelection {
 				criticalErr = ctx.err("cannot have a selection set on this field")
 			} else {
+				valueStart := len(ctx.result)
 				ctx.writeQuoted(typeObj.typeNameBytes)
+				if len(contentModifiers) > 0 {
+					ctx.modifyWrittenContent(valueStart, typeObj.typeName, contentModifiers)
+				}
 			}
 		} else {
 			ctx.writeNull()
@@ -582,38 +1007,78 @@ func (ctx *Ctx) resolveField(typeObj *obj, dept uint8, addCommaBefore bool) (ski
 		}
 	} else {
 		goValue := ctx.getGoValue()
+		embeddedIsNil := false
 		if typeObjField.customObjValue != nil {
 			ctx.setNextGoValue(*typeObjField.customObjValue)
 		} else if typeObjField.valueType == valueTypeMethod && typeObjField.method.isTypeMethod {
 			ctx.setNextGoValue(goValue.Method(typeObjField.structFieldIdx))
+		} else if len(typeObjField.embeddedPath) > 0 {
+			embeddedValue, ok := embeddedStructValue(goValue, typeObjField.embeddedPath)
+			if ok {
+				ctx.setNextGoValue(embeddedValue.Field(typeObjField.structFieldIdx))
+			} else {
+				// The field is promoted from a nil embedded struct pointer
+				embeddedIsNil = true
+				ctx.setNextGoValue(embeddedValue)
+			}
 		} else {
 			ctx.setNextGoValue(goValue.Field(typeObjField.structFieldIdx))
 		}
 
-		criticalErr = ctx.resolveFieldDataValue(typeObjField, dept, fieldHasSelection)
+		var traced *TracedField
+		if ctx.tracer != nil {
+			traced = ctx.tracedField(typeObj, typeObjField, ctx.query.Res[startOfName:endOfName], alias)
+		}
+
+		var info *FieldInfo
+		if len(ctx.schema.middlewares) > 0 && !(typeObjField.valueType == valueTypeMethod && typeObjField.method.returnsChan) {
+			info, criticalErr = ctx.fieldInfo(typeObj, ctx.query.Res[startOfName:endOfName], alias)
+		}
+
+		isNonNull := ctx.schema.isNonNullOutput(typeObjField)
+		prevNullablePathLen := ctx.nullablePathLen
+		if !isNonNull {
+			ctx.nullablePathLen = len(ctx.path)
+		}
+		valueStart := len(ctx.result)
+
+		if criticalErr {
+			ctx.writeNull()
+		} else if embeddedIsNil {
+			ctx.writeNull()
+			if isNonNull {
+				ctx.errf("cannot resolve %s, the embedded struct that contains this field is nil", typeObjField.qlFieldName)
+			}
+		} else if ctx.canResolveInParallel(typeObj, typeObjField) && len(contentModifiers) == 0 {
+			// The span of the field is started by the fork
+			ctx.resolveFieldInParallel(typeObjField, info, traced, dept, fieldHasSelection)
+		} else {
+			var span Span
+			if traced != nil {
+				span = ctx.tracer.StartField(ctx, *traced)
+			}
+			if len(contentModifiers) > 0 {
+				criticalErr = ctx.resolveModifiedFieldDataValue(typeObjField, info, dept, fieldHasSelection, contentModifiers)
+			} else {
+				criticalErr = ctx.resolveFieldValue(typeObjField, info, dept, fieldHasSelection)
+			}
+			if span != nil {
+				span.End()
+			}
+		}
 		ctx.currentReflectValueIdx--
 
-		if ctx.tracingEnabled {
-			name := b2s(ctx.query.Res[startOfName:endOfName])
-
-			ctx.finishTrace(func(offset, duration int64) {
-				returnType := bytes.NewBuffer(nil)
-				ctx.schema.objToQlTypeName(typeObjField, returnType)
-
-				ctx.tracing.Execution.Resolvers = append(ctx.tracing.Execution.Resolvers, tracerResolver{
-					Path:        ctx.GetPath(),
-					ParentType:  typeObj.typeName,
-					FieldName:   name,
-					ReturnType:  returnType.String(),
-					StartOffset: offset,
-					Duration:    duration,
-				})
-			})
+		// Parallel and deferred fields are checked after they are resolved
+		if isNonNull {
+			ctx.checkNonNullValue(valueStart)
 		}
+		ctx.nullablePathLen = prevNullablePathLen
+
 	}
 
 	// Restore the path
 	ctx.path = ctx.path[:prefPathLen]
+	ctx.currentField = prevField
 
 	ctx.charNr = endOfField + 1
 
@@ -621,6 +1086,16 @@ func (ctx *Ctx) resolveField(typeObj *obj, dept uint8, addCommaBefore bool) (ski
 }
 
 func (ctx *Ctx) callQlMethod(method *objMethod, goValue *reflect.Value, parseArguments bool) ([]reflect.Value, bool) {
+	if ctx.bindQlMethodInputs(method, parseArguments) {
+		return nil, true
+	}
+
+	outs := goValue.Call(ctx.funcInputs)
+	return outs, false
+}
+
+// bindQlMethodInputs reads the arguments of method from the query and binds them to ctx.funcInputs
+func (ctx *Ctx) bindQlMethodInputs(method *objMethod, parseArguments bool) bool {
 	ctx.funcInputs = ctx.funcInputs[:0]
 	for _, in := range method.ins {
 		if in.isCtx {
@@ -630,6 +1105,7 @@ func (ctx *Ctx) callQlMethod(method *objMethod, goValue *reflect.Value, parseArg
 		}
 	}
 
+	var provided [][]byte
 	if parseArguments {
 		criticalErr := ctx.walkInputObject(
 			func(key []byte) bool {
@@ -638,18 +1114,32 @@ func (ctx *Ctx) callQlMethod(method *objMethod, goValue *reflect.Value, parseArg
 				if !ok {
 					return ctx.err("undefined input: " + keyStr)
 				}
-				goField := ctx.funcInputs[inField.inputIdx].Field(inField.input.goFieldIdx)
+				if method.hasDefaultValues && !ctx.isOmittedVariable() {
+					provided = append(provided, key)
+				}
+				goField := embeddedInputField(ctx.funcInputs[inField.inputIdx], inField.input.goEmbeddedPath, inField.input.goFieldIdx)
 				_, criticalErr := ctx.bindInputToGoValue(&goField, &inField.input, true)
 				return criticalErr
 			},
 		)
 		if criticalErr {
-			return nil, criticalErr
+			return criticalErr
 		}
 	}
 
-	outs := goValue.Call(ctx.funcInputs)
-	return outs, false
+	if method.hasDefaultValues {
+		for name, inField := range method.inFields {
+			if inField.input.defaultValue == nil || containsName(provided, name) {
+				continue
+			}
+			goField := embeddedInputField(ctx.funcInputs[inField.inputIdx], inField.input.goEmbeddedPath, inField.input.goFieldIdx)
+			if ctx.bindDefaultValue(&goField, &inField.input) {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 func (ctx *Ctx) resolveDirective(location DirectiveLocation) (modifer DirectiveModifier, criticalErr bool) {
@@ -718,13 +1208,28 @@ func (ctx *Ctx) resolveFieldDataValue(typeObj *obj, dept uint8, hasSubSelection
 			ctx.writeNull()
 			return false
 		}
+		if typeObj.mapEntryType != nil {
+			// Maps are resolved as a list of key value entries
+			if goValue.IsNil() {
+				ctx.writeNull()
+				return false
+			}
+			goValue = mapToEntries(goValue, typeObj.mapEntryType)
+		}
 
 		typeObj = typeObj.innerContent
+		itemIsNonNull := ctx.schema.isNonNullOutput(typeObj)
+		prevNullablePathLen := ctx.nullablePathLen
 
 		ctx.writeByte('[')
 		ctx.currentReflectValueIdx++
 		goValueLen := goValue.Len()
 
+		// Fields that return a thunk are resolved after all list items so loaders can batch the keys of all items
+		deferredFieldsStart := len(ctx.deferredFields)
+		wasDeferring := ctx.deferring
+		ctx.deferring = true
+
 		startCharNr := ctx.charNr
 		for i := 0; i < goValueLen; i++ {
 			ctx.charNr = startCharNr
@@ -735,15 +1240,25 @@ func (ctx *Ctx) resolveFieldDataValue(typeObj *obj, dept uint8, hasSubSelection
 
 			ctx.setGoValue(goValue.Index(i))
 
+			if !itemIsNonNull {
+				ctx.nullablePathLen = len(ctx.path)
+			}
+			valueStart := len(ctx.result)
 			ctx.resolveFieldDataValue(typeObj, dept, hasSubSelection)
+			if itemIsNonNull {
+				ctx.checkNonNullValue(valueStart)
+			}
 			if i != goValueLen-1 {
 				ctx.writeByte(',')
 			}
 
 			ctx.path = ctx.path[:prefPathLen]
 		}
+		ctx.nullablePathLen = prevNullablePathLen
+		ctx.deferring = wasDeferring
 		ctx.currentReflectValueIdx--
 		ctx.writeByte(']')
+		return ctx.resolveDeferredFields(deferredFieldsStart)
 	case valueTypeObj, valueTypeObjRef:
 		if !hasSubSelection {
 			ctx.writeNull()
@@ -799,6 +1314,10 @@ func (ctx *Ctx) resolveFieldDataValue(typeObj *obj, dept uint8, hasSubSelection
 			return false
 		}
 
+		if method.returnsChan {
+			return ctx.resolveSubscriptionField(method, goValue, dept)
+		}
+
 		outs, criticalErr := ctx.callQlMethod(method, &goValue, ctx.seekInst() == 'v')
 		if criticalErr {
 			return criticalErr
@@ -813,7 +1332,10 @@ func (ctx *Ctx) resolveFieldDataValue(typeObj *obj, dept uint8, hasSubSelection
 					ctx.writeNull()
 					return ctx.err("returned a invalid kind of error")
 				} else if err != nil {
-					ctx.err(err.Error())
+					// A field that returns a error is null, the returned value is ignored
+					ctx.addErr(err)
+					ctx.writeNull()
+					return false
 				}
 			}
 		}
@@ -822,12 +1344,20 @@ func (ctx *Ctx) resolveFieldDataValue(typeObj *obj, dept uint8, hasSubSelection
 			err := (*ctx.context).Err()
 			if err != nil {
 				// Context ended
-				ctx.err(err.Error())
+				ctx.addErr(err)
 				ctx.writeNull()
 				return false
 			}
 		}
 
+		if method.returnsThunk {
+			if ctx.deferring {
+				ctx.deferField(method, outs[method.outNr], dept)
+				return false
+			}
+			return ctx.resolveThunk(method, outs[method.outNr], dept)
+		}
+
 		ctx.setGoValue(outs[method.outNr])
 		criticalErr = ctx.resolveFieldDataValue(&method.outType, dept, hasSubSelection)
 		return criticalErr
@@ -860,11 +1390,19 @@ func (ctx *Ctx) resolveFieldDataValue(typeObj *obj, dept uint8, hasSubSelection
 			}
 		}
 		ctx.writeNull()
+	case valueTypeScalar:
+		ctx.resolveScalarValue(goValue, typeObj.scalarIndex)
+	case valueTypeJSON:
+		if hasSubSelection {
+			ctx.writeNull()
+			return ctx.err("cannot have a selection set on this field")
+		}
+		ctx.resolveJSONValue(goValue)
 	case valueTypeTime:
 		timeValue, ok := goValue.Interface().(time.Time)
 		if ok {
 			ctx.writeByte('"')
-			helpers.TimeToIso8601String(&ctx.schema.Result, timeValue)
+			helpers.TimeToIso8601String(&ctx.result, timeValue)
 			ctx.writeByte('"')
 		} else {
 			ctx.writeNull()
@@ -914,6 +1452,50 @@ func (ctx *Ctx) resolveFieldDataValue(typeObj *obj, dept uint8, hasSubSelection
 	return false
 }
 
+// resolveSubscriptionField resolves a field on the subscription root
+// When subscribing the field's method is called to obtain the source channel,
+// after that the field resolves the values received from that channel
+func (ctx *Ctx) resolveSubscriptionField(method *objMethod, goValue reflect.Value, dept uint8) bool {
+	if ctx.subscriptionValue.IsValid() {
+		if ctx.seekInst() == bytecode.ActionValue {
+			// The arguments are already used to obtain the source channel
+			ctx.skipInputObject()
+		}
+
+		ctx.setGoValue(ctx.subscriptionValue)
+		return ctx.resolveFieldDataValue(&method.outType, dept, ctx.seekInst() != 'e')
+	}
+
+	if ctx.subscriptionSource.IsValid() {
+		ctx.writeNull()
+		return ctx.err("subscription operations must select exactly one top level field")
+	}
+
+	outs, criticalErr := ctx.callQlMethod(method, &goValue, ctx.seekInst() == 'v')
+	if criticalErr {
+		return criticalErr
+	}
+
+	ctx.writeNull()
+	if method.errorOutNr != nil {
+		errOut := outs[*method.errorOutNr]
+		if !errOut.IsNil() {
+			err, ok := errOut.Interface().(error)
+			if !ok {
+				return ctx.err("returned a invalid kind of error")
+			}
+			return ctx.addErr(err)
+		}
+	}
+
+	source := outs[method.outNr]
+	if source.IsNil() {
+		return ctx.err("subscription returned a nil channel")
+	}
+	ctx.subscriptionSource = source
+	return false
+}
+
 func (ctx *Ctx) findOperatorArgument(nameToFind string) (foundArgument bool) {
 	if !ctx.operatorHasArguments {
 		return false
@@ -947,9 +1529,28 @@ func (ctx *Ctx) findOperatorArgument(nameToFind string) (foundArgument bool) {
 	}
 }
 
+// findOperatorArgumentDefault moves the ctx to the default value of a operator argument
+// Returns false if the argument is not found or has no default value
+func (ctx *Ctx) findOperatorArgumentDefault(nameToFind string) bool {
+	if !ctx.findOperatorArgument(nameToFind) {
+		return false
+	}
+
+	// Skip over the variable type
+	for {
+		c := ctx.readInst()
+		if c != 'L' && c != 'l' {
+			break
+		}
+	}
+	for ctx.readInst() != 0 {
+	}
+
+	return ctx.readInst() == 't'
+}
+
 func (ctx *Ctx) bindOperatorArgumentTo(goValue *reflect.Value, valueStructure *input, argumentName string) (valueSet bool, criticalErr bool) {
-	// TODO Check for the required flag (L & N)
-	// These flags are to identify if the argument is required or not
+	// The required flags (L & N) are checked by the validation, a missing value is treated as null here
 
 	// TODO the error messages in this function are garbage
 
@@ -996,6 +1597,15 @@ func (ctx *Ctx) bindOperatorArgumentTo(goValue *reflect.Value, valueStructure *i
 			if typeName != "Time" && typeName != "String" {
 				return false, ctx.err("expected variable type Time but got " + typeName)
 			}
+		} else if resolvedValueStructure.isJSON {
+			if typeName != "JSON" {
+				return false, ctx.err("expected variable type JSON but got " + typeName)
+			}
+		} else if resolvedValueStructure.isScalar {
+			scalar := ctx.schema.definedScalars[resolvedValueStructure.scalarIndex]
+			if typeName != scalar.typeName {
+				return false, ctx.err("expected variable type " + scalar.typeName + " but got " + typeName)
+			}
 		} else {
 			switch resolvedValueStructure.kind {
 			case reflect.Bool:
@@ -1037,27 +1647,41 @@ func (ctx *Ctx) bindOperatorArgumentTo(goValue *reflect.Value, valueStructure *i
 	}
 
 	if !hasDefaultValue {
-		return false, ctx.err("variable has no value nor default")
+		// keep goValue at it's default
+		return false, false
 	}
 
 	return ctx.bindInputToGoValue(goValue, valueStructure, false)
 }
 
-func (ctx *Ctx) bindExternalVariableValue(goValue *reflect.Value, valueStructure *input, argumentName string) (valueSet bool, found bool, criticalErr bool) {
-	if !ctx.variablesParsed {
-		if len(ctx.rawVariables) == 0 {
-			return false, false, false
-		}
+// parseVariables parses the raw variables of the request if that's not yet done
+// hasVariables is false if there are no variables or if they could not be parsed
+func (ctx *Ctx) parseVariables() (hasVariables bool, criticalErr bool) {
+	if ctx.variablesParsed {
+		return ctx.variables != nil, false
+	}
+	if len(ctx.rawVariables) == 0 {
+		return false, false
+	}
 
-		ctx.variablesParsed = true
-		var err error
-		ctx.variables, err = ctx.variablesJSONParser.Parse(ctx.rawVariables)
-		if err != nil {
-			return false, false, ctx.err(err.Error())
-		}
-		if ctx.variables.Type() != fastjson.TypeObject {
-			return false, false, ctx.err("variables provided must be of type object")
-		}
+	ctx.variablesParsed = true
+	var err error
+	ctx.variables, err = ctx.variablesJSONParser.Parse(ctx.rawVariables)
+	if err != nil {
+		ctx.variables = nil
+		return false, ctx.err(err.Error())
+	}
+	if ctx.variables.Type() != fastjson.TypeObject {
+		ctx.variables = nil
+		return false, ctx.err("variables provided must be of type object")
+	}
+	return true, false
+}
+
+func (ctx *Ctx) bindExternalVariableValue(goValue *reflect.Value, valueStructure *input, argumentName string) (valueSet bool, found bool, criticalErr bool) {
+	hasVariables, criticalErr := ctx.parseVariables()
+	if !hasVariables {
+		return false, false, criticalErr
 	}
 
 	variable := ctx.variables.Get(argumentName)
@@ -1070,6 +1694,12 @@ func (ctx *Ctx) bindExternalVariableValue(goValue *reflect.Value, valueStructure
 }
 
 func (ctx *Ctx) bindJSONToValue(goValue *reflect.Value, valueStructure *input, jsonData *fastjson.Value) (valueSet bool, criticalErr bool) {
+	if valueStructure.mapEntryType != nil {
+		return ctx.bindMapInput(goValue, valueStructure, func(entries *reflect.Value, entriesStructure *input) (valueSet bool, criticalErr bool) {
+			return ctx.bindJSONToValue(entries, entriesStructure, jsonData)
+		})
+	}
+
 	var isPtr bool
 	isPtr, valueSet, criticalErr = ctx.checkInputIsPtr(goValue, valueStructure, func(goValue *reflect.Value, input *input) (valueSet bool, criticalErr bool) {
 		return ctx.bindJSONToValue(goValue, input, jsonData)
@@ -1078,7 +1708,20 @@ func (ctx *Ctx) bindJSONToValue(goValue *reflect.Value, valueStructure *input, j
 		return
 	}
 
+	if valueStructure.isScalar {
+		return ctx.assignScalarValue(goValue, valueStructure, jsonData)
+	}
+	if valueStructure.isJSON {
+		return ctx.assignJSONValue(goValue, jsonData)
+	}
+
 	jsonDataType := jsonData.Type()
+	if valueStructure.kind == reflect.Slice && goValue.Kind() == reflect.Slice && jsonDataType != fastjson.TypeArray && jsonDataType != fastjson.TypeNull {
+		// A single value is coerced into a list with one item
+		return bindSingleItemList(goValue, func(item *reflect.Value) (bool, bool) {
+			return ctx.bindJSONToValue(item, valueStructure.elem, jsonData)
+		})
+	}
 	if valueStructure.isEnum || valueStructure.isID || valueStructure.isFile || valueStructure.isTime {
 		if jsonDataType != fastjson.TypeString {
 			if valueStructure.isEnum {
@@ -1190,6 +1833,7 @@ func (ctx *Ctx) bindJSONToValue(goValue *reflect.Value, valueStructure *input, j
 		valueSet = true
 		jsonObj := jsonData.GetObject()
 		criticalErr := false
+		var provided [][]byte
 		jsonObj.Visit(func(key []byte, v *fastjson.Value) {
 			if criticalErr {
 				return
@@ -1200,13 +1844,22 @@ func (ctx *Ctx) bindJSONToValue(goValue *reflect.Value, valueStructure *input, j
 				criticalErr = ctx.err("undefined property " + b2s(key))
 				return
 			}
+			if valueStructure.hasDefaultValues {
+				provided = append(provided, key)
+			}
 
-			goValueField := goValue.Field(structItemMeta.goFieldIdx)
+			goValueField := embeddedInputField(*goValue, structItemMeta.goEmbeddedPath, structItemMeta.goFieldIdx)
 			_, criticalErr = ctx.bindJSONToValue(&goValueField, &structItemMeta, v)
 		})
 		if criticalErr {
 			return valueSet, criticalErr
 		}
+		if valueStructure.hasDefaultValues {
+			criticalErr = ctx.bindOmittedDefaultValues(goValue, valueStructure, provided)
+			if criticalErr {
+				return valueSet, criticalErr
+			}
+		}
 	case fastjson.TypeArray:
 		if goValue.Kind() != reflect.Slice {
 			return valueSet, ctx.err("cannot assign slice to " + goValue.String())
@@ -1379,7 +2032,7 @@ func (ctx *Ctx) assignStringToValue(goValue *reflect.Value, valueStructure *inpu
 }
 
 func (ctx *Ctx) checkInputIsPtr(goValue *reflect.Value, input *input, whenPtr func(goValue *reflect.Value, input *input) (valueSet bool, criticalErr bool)) (isPtr bool, valueSet bool, criticalErr bool) {
-	if input.kind != reflect.Ptr || input.isFile {
+	if input.kind != reflect.Ptr || input.isFile || input.isScalar {
 		return false, false, false
 	}
 
@@ -1396,9 +2049,27 @@ func (ctx *Ctx) checkInputIsPtr(goValue *reflect.Value, input *input, whenPtr fu
 	return true, valueSet, false
 }
 
+// bindSingleItemList sets the slice goValue to a list with one item that is bound by bind
+func bindSingleItemList(goValue *reflect.Value, bind func(item *reflect.Value) (valueSet bool, criticalErr bool)) (valueSet bool, criticalErr bool) {
+	list := reflect.MakeSlice(goValue.Type(), 1, 1)
+	item := list.Index(0)
+	_, criticalErr = bind(&item)
+	if criticalErr {
+		return false, criticalErr
+	}
+	goValue.Set(list)
+	return true, false
+}
+
 func (ctx *Ctx) bindInputToGoValue(goValue *reflect.Value, valueStructure *input, variablesAllowed bool) (valueSet bool, criticalErr bool) {
 	// TODO convert to go value kind to graphql value kind in errors
 
+	if valueStructure.mapEntryType != nil {
+		return ctx.bindMapInput(goValue, valueStructure, func(entries *reflect.Value, entriesStructure *input) (valueSet bool, criticalErr bool) {
+			return ctx.bindInputToGoValue(entries, entriesStructure, variablesAllowed)
+		})
+	}
+
 	var isPtr bool
 	isPtr, valueSet, criticalErr = ctx.checkInputIsPtr(goValue, valueStructure, func(goValue *reflect.Value, input *input) (valueSet bool, criticalErr bool) {
 		if ctx.query.Res[ctx.charNr+1] == bytecode.ValueNull {
@@ -1412,6 +2083,23 @@ func (ctx *Ctx) bindInputToGoValue(goValue *reflect.Value, valueStructure *input
 		return valueSet, criticalErr
 	}
 
+	if valueStructure.isScalar && ctx.query.Res[ctx.charNr+1] != bytecode.ValueVariable {
+		return ctx.bindScalarValue(goValue, valueStructure)
+	}
+	if valueStructure.isJSON && ctx.query.Res[ctx.charNr+1] != bytecode.ValueVariable {
+		return ctx.bindJSONScalarValue(goValue)
+	}
+	if valueStructure.kind == reflect.Slice && goValue.Kind() == reflect.Slice {
+		switch ctx.query.Res[ctx.charNr+1] {
+		case bytecode.ValueList, bytecode.ValueNull, bytecode.ValueVariable:
+		default:
+			// A single value is coerced into a list with one item
+			return bindSingleItemList(goValue, func(item *reflect.Value) (bool, bool) {
+				return ctx.bindInputToGoValue(item, valueStructure.elem, variablesAllowed)
+			})
+		}
+	}
+
 	getValue := func() (start int, end int) {
 		start = ctx.charNr
 		for {
@@ -1511,6 +2199,11 @@ func (ctx *Ctx) bindInputToGoValue(goValue *reflect.Value, valueStructure *input
 			}
 
 			goValue.SetBool(value > 0)
+		case reflect.String:
+			if !valueStructure.isID {
+				return false, ctx.err("cannot assign int to " + goValue.String())
+			}
+			goValue.SetString(intValue)
 		default:
 			return false, ctx.err("cannot assign int to " + goValue.String())
 		}
@@ -1593,6 +2286,7 @@ func (ctx *Ctx) bindInputToGoValue(goValue *reflect.Value, valueStructure *input
 			}
 			arr = reflect.Append(arr, arrayEntry)
 		}
+		ctx.skipInst(2) // read 'e' and the NULL byte of the next instruction
 
 		goValue.Set(arr)
 	case bytecode.ValueObject:
@@ -1607,19 +2301,29 @@ func (ctx *Ctx) bindInputToGoValue(goValue *reflect.Value, valueStructure *input
 		// walkInputObject expects to start at ActionValue while we just read over it
 		ctx.skipInst(-6)
 
+		var provided [][]byte
 		criticalErr := ctx.walkInputObject(func(key []byte) bool {
 			structFieldValueStructure, ok := valueStructure.structContent[b2s(key)]
 			if !ok {
 				return ctx.err("undefined property " + b2s(key))
 			}
+			if valueStructure.hasDefaultValues && !ctx.isOmittedVariable() {
+				provided = append(provided, key)
+			}
 
-			field := goValue.Field(structFieldValueStructure.goFieldIdx)
-			valueSet, criticalErr = ctx.bindInputToGoValue(&field, &structFieldValueStructure, variablesAllowed)
+			field := embeddedInputField(*goValue, structFieldValueStructure.goEmbeddedPath, structFieldValueStructure.goFieldIdx)
+			_, criticalErr := ctx.bindInputToGoValue(&field, &structFieldValueStructure, variablesAllowed)
 			return criticalErr
 		})
 		if criticalErr {
 			return valueSet, criticalErr
 		}
+		if valueStructure.hasDefaultValues {
+			criticalErr = ctx.bindOmittedDefaultValues(goValue, valueStructure, provided)
+			if criticalErr {
+				return valueSet, criticalErr
+			}
+		}
 	}
 	return valueSet, false
 }
@@ -1655,10 +2359,19 @@ func (ctx *Ctx) walkInputObject(onValueOfKey func(key []byte) bool) bool {
 	}
 }
 
+// skipInputObject skips over a input object without reading it's contents
+func (ctx *Ctx) skipInputObject() {
+	// Read ActionValue and ValueObject
+	objLen := ctx.readUint32(ctx.charNr + 2)
+
+	// Skip ActionValue, ValueObject, the object length, the contents and the NULL byte of the next instruction
+	ctx.skipInst(6 + int(objLen) + 1)
+}
+
 func (ctx *Ctx) valueToJSON(in reflect.Value, kind reflect.Kind) {
 	switch kind {
 	case reflect.String:
-		helpers.StringToJSON(in.String(), &ctx.schema.Result)
+		helpers.StringToJSON(in.String(), &ctx.result)
 	case reflect.Bool:
 		if in.Bool() {
 			ctx.write([]byte("true"))
@@ -1666,13 +2379,13 @@ func (ctx *Ctx) valueToJSON(in reflect.Value, kind reflect.Kind) {
 			ctx.write([]byte("false"))
 		}
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		ctx.schema.Result = strconv.AppendInt(ctx.schema.Result, in.Int(), 10)
+		ctx.result = strconv.AppendInt(ctx.result, in.Int(), 10)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		ctx.schema.Result = strconv.AppendUint(ctx.schema.Result, in.Uint(), 10)
+		ctx.result = strconv.AppendUint(ctx.result, in.Uint(), 10)
 	case reflect.Float32:
-		helpers.FloatToJSON(32, in.Float(), &ctx.schema.Result)
+		helpers.FloatToJSON(32, in.Float(), &ctx.result)
 	case reflect.Float64:
-		helpers.FloatToJSON(64, in.Float(), &ctx.schema.Result)
+		helpers.FloatToJSON(64, in.Float(), &ctx.result)
 	case reflect.Ptr:
 		if in.IsNil() {
 			ctx.writeNull()
@@ -1685,19 +2398,6 @@ func (ctx *Ctx) valueToJSON(in reflect.Value, kind reflect.Kind) {
 	}
 }
 
-func (ctx *Ctx) startTrace() {
-	if ctx.tracingEnabled {
-		ctx.prefRecordingStartTime = time.Now()
-	}
-}
-
-func (ctx *Ctx) finishTrace(report func(offset, duration int64)) {
-	f := ctx.prefRecordingStartTime
-	offset := f.Sub(ctx.tracing.GoStartTime).Nanoseconds()
-	duration := time.Since(f).Nanoseconds()
-	report(offset, duration)
-}
-
 // b2s converts a byte array into a string without allocating new memory
 // Note that any changes to a will result in a different string
 func b2s(a []byte) string {
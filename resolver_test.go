@@ -5,10 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"mime/multipart"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -349,6 +352,8 @@ func TestBytecodeResolveMethodListInput(t *testing.T) {
 
 	res = bytecodeParseAndExpectNoErrs(t, `{bar(a: ["foo", "baz"])}`, TestBytecodeResolveMethodListInputData{}, M{})
 	a.Equal(t, `{"bar":["foo","baz"]}`, res)
+	res = bytecodeParseAndExpectNoErrs(t, `{bar(a: ["foo", "baz"]) baz: bar(a: [])}`, TestBytecodeResolveMethodListInputData{}, M{})
+	a.Equal(t, `{"bar":["foo","baz"],"baz":[]}`, res)
 }
 
 type TestResolveStructTypeMethodWithStructArgData struct{}
@@ -413,16 +418,22 @@ func TestBytecodeResolveCorrectMetaWithError(t *testing.T) {
 	if !json.Valid([]byte(res)) {
 		panic("invalid json: " + res)
 	}
-	a.Equal(t, `{"data":{"a":{"foo":null}},"errors":[{"message":"field arguments not allowed","path":["a","foo"]}],"extensions":{}}`, res)
+	a.Equal(t, `{"data":{},"errors":[{"message":"Unknown argument \"a\" on field \"TestResolveSchemaRequestWithFieldsDataInnerStruct.foo\".","locations":[{"line":3,"column":8}]}],"extensions":{}}`, res)
 }
 
 func TestBytecodeResolveWithArgs(t *testing.T) {
-	query := `query A($a: Int) {}`
+	query := `query A($a: Boolean = true) {__typename @skip(if: $a)}`
 	schema := TestResolveEmptyQueryDataQ{}
 	res := bytecodeParseAndExpectNoErrs(t, query, schema, M{})
 	a.Equal(t, `{}`, res)
 }
 
+func TestBytecodeResolveVariableWithoutValue(t *testing.T) {
+	query := `query A($baz: String) {bar(a: $baz)}`
+	res := bytecodeParseAndExpectNoErrs(t, query, TestResolveStructTypeMethodWithPtrArgData{}, M{})
+	a.Equal(t, `{"bar":null}`, res)
+}
+
 func TestBytecodeResolveVariableInputWithDefault(t *testing.T) {
 	query := `query A($baz: String = "foo") {bar(a: $baz)}`
 	res := bytecodeParseAndExpectNoErrs(t, query, TestResolveStructTypeMethodWithPtrArgData{}, M{})
@@ -605,25 +616,25 @@ func TestBytecodeResolveMultipleArguments(t *testing.T) {
 			"variables",
 			func(t *testing.T) string {
 				query := `query a(
-					$string: String,
-					$int: Int,
-					$int8: Int,
-					$int16: Int,
-					$int32: Int,
-					$int64: Int,
-					$uint: Int,
-					$uint8: Int,
-					$uint16: Int,
-					$uint32: Int,
-					$uint64: Int,
-					$bool: Boolean,
-					$time: Time,
-					$uintId: ID,
-					$stringId: ID,
-					$enum: __TypeKind,
+					$string: String!,
+					$int: Int!,
+					$int8: Int!,
+					$int16: Int!,
+					$int32: Int!,
+					$int64: Int!,
+					$uint: Int!,
+					$uint8: Int!,
+					$uint16: Int!,
+					$uint32: Int!,
+					$uint64: Int!,
+					$bool: Boolean!,
+					$time: Time!,
+					$uintId: ID!,
+					$stringId: ID!,
+					$enum: __TypeKind!,
 					$intPtr: Int,
 					$intPtrWData: Int,
-					$struct: __UnknownInput1,
+					$struct: __UnknownInput1!,
 				) {
 					foo(
 						string: $string,
@@ -731,6 +742,19 @@ func TestBytecodeResolveMultipleArguments(t *testing.T) {
 	}
 }
 
+type TestBytecodeResolveIntAsStringIDData struct{}
+
+func (TestBytecodeResolveIntAsStringIDData) ResolveFoo(args struct {
+	Id string `gq:",id"`
+}) string {
+	return args.Id
+}
+
+func TestBytecodeResolveIntAsStringID(t *testing.T) {
+	res := bytecodeParseAndExpectNoErrs(t, `{foo(id: 123)}`, TestBytecodeResolveIntAsStringIDData{}, M{})
+	a.Equal(t, `{"foo":"123"}`, res)
+}
+
 type TestBytecodeResolveJSONArrayVariableData struct{}
 
 func (TestBytecodeResolveJSONArrayVariableData) ResolveFoo(args struct{ Data []string }) []string {
@@ -738,7 +762,7 @@ func (TestBytecodeResolveJSONArrayVariableData) ResolveFoo(args struct{ Data []s
 }
 
 func TestBytecodeResolveJSONArrayVariable(t *testing.T) {
-	query := `query foo($data: [String]) {
+	query := `query foo($data: [String!]) {
 		foo(data: $data)
 	}`
 	schema := TestBytecodeResolveJSONArrayVariableData{}
@@ -764,7 +788,7 @@ func (TestBytecodeResolveJSONObjectVariableData) ResolveFoo(args struct{ Data Da
 }
 
 func TestBytecodeResolveJSONObjectVariable(t *testing.T) {
-	query := `query foo($data: DataObj__input) {
+	query := `query foo($data: DataObj__input!) {
 		foo(data: $data) {
 			a
 			c
@@ -1131,7 +1155,7 @@ func TestBytecodeResolveTracing(t *testing.T) {
 	a.NotEqual(t, int64(0), parsing.Duration)
 
 	validation := tracer.Validation
-	a.Equal(t, int64(0), validation.Duration)
+	a.NotEqual(t, int64(0), validation.Duration)
 	a.NotEqual(t, int64(0), validation.StartOffset)
 
 	for _, resolver := range tracer.Execution.Resolvers {
@@ -1274,6 +1298,158 @@ func TestBytecodeResolveDirective(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("operation directives", func(t *testing.T) {
+		s := NewSchema()
+		s.RegisterDirective(Directive{
+			Name:  "cached",
+			Where: []DirectiveLocation{DirectiveLocationQuery},
+			Method: func(args struct{ Seconds int }) DirectiveModifier {
+				return DirectiveModifier{
+					Values: map[string]interface{}{"ttl": args.Seconds},
+				}
+			},
+		})
+		s.RegisterDirective(Directive{
+			Name:  "disabled",
+			Where: []DirectiveLocation{DirectiveLocationQuery, DirectiveLocationMutation},
+			Method: func() DirectiveModifier {
+				return DirectiveModifier{Skip: true}
+			},
+		})
+
+		res, errs := bytecodeParse(t, s, `query($ttl: Int!) @cached(seconds: $ttl) {ttl}`, TestResolveOperationDirectivesData{}, M{}, ResolveOptions{
+			NoMeta:    true,
+			Variables: `{"ttl": 60}`,
+		})
+		a.Equal(t, 0, len(errs))
+		a.Equal(t, `{"ttl":60}`, res)
+
+		res, errs = bytecodeParse(t, NewSchema(), `{ttl}`, TestResolveOperationDirectivesData{}, M{}, ResolveOptions{NoMeta: true})
+		a.Equal(t, 0, len(errs))
+		a.Equal(t, `{"ttl":0}`, res)
+
+		res, errs = bytecodeParse(t, s, `query @disabled {ttl}`, TestResolveOperationDirectivesData{}, M{}, ResolveOptions{NoMeta: true})
+		a.Equal(t, 0, len(errs))
+		a.Equal(t, `{}`, res)
+
+		_, errs = bytecodeParse(t, s, `query @cached(seconds: 1) @disabled {ttl}`, TestResolveOperationDirectivesData{}, M{}, ResolveOptions{NoMeta: true})
+		a.Equal(t, 0, len(errs))
+
+		// Directives that are not allowed on the operation kind are rejected by the validator
+		_, errs = bytecodeParse(t, s, `query @skip(if: true) {ttl}`, TestResolveOperationDirectivesData{}, M{}, ResolveOptions{NoMeta: true})
+		a.Equal(t, 1, len(errs))
+		a.Equal(t, `Directive "@skip" may not be used on QUERY.`, errs[0].Error())
+	})
+
+	t.Run("content modifying directives", func(t *testing.T) {
+		s := NewSchema()
+		s.RegisterDirective(Directive{
+			Name:  "uppercase",
+			Where: []DirectiveLocation{DirectiveLocationField},
+			Method: func() DirectiveModifier {
+				return DirectiveModifier{
+					ModifyOnWriteContent: func(bytes []byte, value interface{}) []byte {
+						return []byte(strings.ToUpper(string(bytes)))
+					},
+				}
+			},
+		})
+		s.RegisterDirective(Directive{
+			Name:  "dateFormat",
+			Where: []DirectiveLocation{DirectiveLocationField},
+			Method: func(args struct{ Layout string }) DirectiveModifier {
+				return DirectiveModifier{
+					ModifyOnWriteContent: func(bytes []byte, value interface{}) []byte {
+						date, ok := value.(time.Time)
+						if !ok {
+							return bytes
+						}
+						return []byte(strconv.Quote(date.Format(args.Layout)))
+					},
+				}
+			},
+		})
+		s.RegisterDirective(Directive{
+			Name:  "mask",
+			Where: []DirectiveLocation{DirectiveLocationField},
+			Method: func() DirectiveModifier {
+				return DirectiveModifier{
+					ModifyOnWriteContent: func(bytes []byte, value interface{}) []byte {
+						if value == nil {
+							return bytes
+						}
+						return []byte(`"***"`)
+					},
+				}
+			},
+		})
+
+		s.RegisterDirective(Directive{
+			Name:  "strictUppercase",
+			Where: []DirectiveLocation{DirectiveLocationField},
+			Method: func() DirectiveModifier {
+				return DirectiveModifier{
+					ModifyOnWriteContent: func(bytes []byte, value interface{}) []byte {
+						return []byte(strconv.Quote(strings.ToUpper(value.(string))))
+					},
+				}
+			},
+		})
+
+		data := TestResolveContentModifiersData{
+			Name:    "foo",
+			Created: time.Date(2021, 10, 5, 12, 0, 0, 0, time.UTC),
+		}
+		query := `{
+			name @uppercase
+			created @dateFormat(layout: "2006-01-02")
+			secret @mask
+			tags @uppercase
+			__typename @uppercase
+			inner @uppercase {name}
+		}`
+		res, errs := bytecodeParse(t, s, query, data, M{}, ResolveOptions{NoMeta: true})
+		for _, err := range errs {
+			panic(err.Error())
+		}
+		a.Equal(t, `{"name":"FOO","created":"2021-10-05","secret":"***","tags":["A","B"],"__typename":"TESTRESOLVECONTENTMODIFIERSDATA","inner":{"NAME":"FOO"}}`, res)
+
+		// The modifiers are not called for fields that errored
+		res, errs = bytecodeParse(t, s, `{name @strictUppercase failing @strictUppercase}`, data, M{}, ResolveOptions{NoMeta: true})
+		a.Equal(t, 1, len(errs))
+		a.Equal(t, "failing", errs[0].Error())
+		a.Equal(t, `{"name":"FOO","failing":null}`, res)
+	})
+}
+
+type TestResolveContentModifiersData struct {
+	Name    string
+	Created time.Time
+}
+
+func (TestResolveContentModifiersData) ResolveSecret() *string {
+	secret := "bar"
+	return &secret
+}
+
+func (TestResolveContentModifiersData) ResolveTags() []string {
+	return []string{"a", "b"}
+}
+
+func (d TestResolveContentModifiersData) ResolveInner() TestResolveContentModifiersData {
+	return d
+}
+
+func (TestResolveContentModifiersData) ResolveFailing() (*string, error) {
+	return nil, errors.New("failing")
+}
+
+type TestResolveOperationDirectivesData struct{}
+
+func (TestResolveOperationDirectivesData) ResolveTtl(ctx *Ctx) int {
+	ttl, _ := ctx.GetValue("ttl").(int)
+	return ttl
 }
 
 func TestValueToJson(t *testing.T) {
@@ -1369,10 +1545,10 @@ func TestValueToJson(t *testing.T) {
 		{complex64(1), "null"},
 	}
 	for _, option := range options {
-		c := &Ctx{schema: &Schema{Result: []byte{}}}
+		c := &Ctx{result: []byte{}}
 		v := reflect.ValueOf(option.value)
 		c.valueToJSON(v, v.Kind())
-		a.Equal(t, option.expect, string(c.schema.Result))
+		a.Equal(t, option.expect, string(c.result))
 	}
 }
 
@@ -1452,7 +1628,73 @@ func TestExecMaxDept(t *testing.T) {
 	s.MaxDepth = 3
 	out, errs := bytecodeParse(t, s, `{foo{bar{baz{fooBar{barBaz{bazFoo}}}}}}`, TestResolveMaxDeptData{}, M{}, ResolveOptions{})
 	a.Greater(t, len(errs), 0)
-	a.Equal(t, `{"data":{"foo":{"bar":{"baz":null}}},"errors":[{"message":"reached max dept","path":["foo","bar","baz"]}],"extensions":{}}`, out)
+	a.Equal(t, `{"data":null,"errors":[{"message":"reached max dept","locations":[{"line":1,"column":10}],"path":["foo","bar","baz"]}],"extensions":{}}`, out)
+}
+
+type TestResolveErrorExtensionsError struct{}
+
+func (TestResolveErrorExtensionsError) Error() string {
+	return "not logged in"
+}
+
+func (TestResolveErrorExtensionsError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": "UNAUTHENTICATED"}
+}
+
+type TestResolveErrorExtensionsData struct{}
+
+func (TestResolveErrorExtensionsData) ResolveMe() (*string, error) {
+	return nil, TestResolveErrorExtensionsError{}
+}
+
+func (TestResolveErrorExtensionsData) ResolveInner() TestResolveErrorExtensionsData {
+	return TestResolveErrorExtensionsData{}
+}
+
+func (TestResolveErrorExtensionsData) ResolveFails() (*string, error) {
+	return nil, errors.New("fails")
+}
+
+func (TestResolveErrorExtensionsData) ResolveWrapped() (*string, error) {
+	return nil, fmt.Errorf("load user: %w", TestResolveErrorExtensionsError{})
+}
+
+func TestExecErrorExtensionsAndLocations(t *testing.T) {
+	query := `{
+		me
+		inner {
+			alias: fails
+		}
+	}`
+	out, errs := bytecodeParse(t, NewSchema(), query, TestResolveErrorExtensionsData{}, M{}, ResolveOptions{})
+	a.Equal(t, 2, len(errs))
+	a.Equal(t, `{"data":{"me":null,"inner":{"alias":null}},"errors":[`+
+		`{"message":"not logged in","locations":[{"line":2,"column":3}],"path":["me"],"extensions":{"code":"UNAUTHENTICATED"}},`+
+		`{"message":"fails","locations":[{"line":4,"column":4}],"path":["inner","alias"]}`+
+		`],"extensions":{}}`, out)
+
+	// The original error can be obtained from the returned errors
+	var extensionsErr TestResolveErrorExtensionsError
+	a.True(t, errors.As(errs[0], &extensionsErr))
+
+	errWPath, ok := errs[1].(ErrorWPath)
+	a.True(t, ok)
+	a.Equal(t, `["inner","alias"]`, string(errWPath.Path()))
+	line, column, ok := errWPath.Location()
+	a.True(t, ok)
+	a.Equal(t, uint(4), line)
+	a.Equal(t, uint(3), column)
+	a.Nil(t, errWPath.Extensions())
+}
+
+func TestExecErrorExtensionsWrapped(t *testing.T) {
+	out, errs := bytecodeParse(t, NewSchema(), `{wrapped}`, TestResolveErrorExtensionsData{}, M{}, ResolveOptions{})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, `{"data":{"wrapped":null},"errors":[{"message":"load user: not logged in","locations":[{"line":1,"column":2}],"path":["wrapped"],"extensions":{"code":"UNAUTHENTICATED"}}],"extensions":{}}`, out)
+
+	errWPath, ok := errs[0].(ErrorWPath)
+	a.True(t, ok)
+	a.Equal(t, map[string]interface{}{"code": "UNAUTHENTICATED"}, errWPath.Extensions())
 }
 
 type TestResolveStructTypeMethodWithCtxData struct{}
@@ -1548,7 +1790,7 @@ func TestPathStaysCorrect(t *testing.T) {
 				path
 				bar {
 					path
-					foo
+					foo { path }
 				}
 			}
 		}
@@ -1556,7 +1798,7 @@ func TestPathStaysCorrect(t *testing.T) {
 			path
 			bar {
 				path
-				foo
+				foo { path }
 			}
 		}
 		baz {
@@ -1565,7 +1807,7 @@ func TestPathStaysCorrect(t *testing.T) {
 				path
 				bar {
 					path
-					foo
+					foo { path }
 				}
 			}
 		}
@@ -1573,9 +1815,9 @@ func TestPathStaysCorrect(t *testing.T) {
 			path
 			foo {
 				path
-				foo {
+				bar {
 					path
-					bar
+					foo { path }
 				}
 			}
 		}
@@ -1866,7 +2108,7 @@ func TestBytecodeResolveContext(t *testing.T) {
 	opts := ResolveOptions{NoMeta: true, Context: context}
 	out, errs := bytecodeParseAndExpectErrs(t, `{foo}`, TestBytecodeResolveContextData{}, M{}, opts)
 	a.Equal(t, 1, len(errs))
-	a.Equal(t, `{"foo":null}`, out)
+	a.Equal(t, `null`, out)
 }
 
 func TestBytecodeResolveQueryCache(t *testing.T) {
@@ -1928,3 +2170,187 @@ func TestBytecodeResolveID(t *testing.T) {
 	out := bytecodeParseAndExpectNoErrs(t, query, schema, M{})
 	a.Equal(t, `{"directId":"2","methodId":"3"}`, out)
 }
+
+func TestResolveConcurrent(t *testing.T) {
+	s := NewSchema()
+	err := s.Parse(TestResolveSchemaRequestWithFieldsData{A: TestResolveSchemaRequestWithFieldsDataInnerStruct{Bar: "baz"}}, M{}, nil)
+	a.NoError(t, err)
+
+	queries := []struct {
+		query  string
+		expect string
+	}{
+		{`{a {bar}}`, `{"a":{"bar":"baz"}}`},
+		{`{__type(name: "TestResolveSchemaRequestWithFieldsDataInnerStruct") {name}}`, `{"__type":{"name":"TestResolveSchemaRequestWithFieldsDataInnerStruct"}}`},
+		{`{b {baz}}`, `{"b":{"baz":""}}`},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		for _, entry := range queries {
+			wg.Add(1)
+			go func(query, expect string) {
+				defer wg.Done()
+
+				res, errs := s.ResolveConcurrent([]byte(query), ResolveOptions{NoMeta: true})
+				for _, err := range errs {
+					a.NoError(t, err, query)
+				}
+				a.Equal(t, expect, string(res.Result))
+				res.Release()
+			}(entry.query, entry.expect)
+		}
+	}
+	wg.Wait()
+}
+
+func TestResolveConcurrentDoubleRelease(t *testing.T) {
+	s := NewSchema()
+	err := s.Parse(TestResolveSchemaRequestWithFieldsData{A: TestResolveSchemaRequestWithFieldsDataInnerStruct{Bar: "baz"}}, M{}, nil)
+	a.NoError(t, err)
+
+	first, errs := s.ResolveConcurrent([]byte(`{a {bar}}`), ResolveOptions{NoMeta: true})
+	a.Equal(t, 0, len(errs))
+	first.Release()
+
+	// The second request might re-use the ctx of the first one, releasing the first response again must not affect it
+	second, errs := s.ResolveConcurrent([]byte(`{b {baz}}`), ResolveOptions{NoMeta: true})
+	a.Equal(t, 0, len(errs))
+	first.Release()
+	a.Nil(t, first.Result)
+	a.NotNil(t, second.ctx)
+	a.Equal(t, `{"b":{"baz":""}}`, string(second.Result))
+	second.Release()
+}
+
+type TestSubscribeData struct{}
+
+type TestSubscribeDataValue struct {
+	Nr  int
+	Foo string
+}
+
+func (TestSubscribeData) ResolveCounter(ctx *Ctx, args struct{ To int }) <-chan TestSubscribeDataValue {
+	res := make(chan TestSubscribeDataValue)
+	go func() {
+		defer close(res)
+		for i := 1; i <= args.To; i++ {
+			select {
+			case res <- TestSubscribeDataValue{Nr: i, Foo: "bar"}:
+			case <-ctx.GetContext().Done():
+				return
+			}
+		}
+	}()
+	return res
+}
+
+func (TestSubscribeData) ResolveUntilCancelled(ctx *Ctx) <-chan int {
+	res := make(chan int, 1)
+	res <- 1
+	go func() {
+		<-ctx.GetContext().Done()
+		close(res)
+	}()
+	return res
+}
+
+func (TestSubscribeData) ResolveFails() (<-chan int, error) {
+	return nil, errors.New("this subscription fails")
+}
+
+func (TestSubscribeData) ResolveUnauthorized() (<-chan int, error) {
+	return nil, TestResolveErrorExtensionsError{}
+}
+
+func newTestSubscribeSchema(t *testing.T) *Schema {
+	s := NewSchema()
+	err := s.Parse(TestResolveSimpleQueryData{A: "foo"}, M{}, &SchemaOptions{Subscriptions: TestSubscribeData{}})
+	a.NoError(t, err)
+	return s
+}
+
+func TestSubscribe(t *testing.T) {
+	s := newTestSubscribeSchema(t)
+
+	responses, cancel := s.Subscribe([]byte(`subscription {counter(to: 3) {nr}}`), ResolveOptions{NoMeta: true})
+	defer cancel()
+
+	results := []string{}
+	for response := range responses {
+		results = append(results, string(response))
+	}
+	a.Equal(t, []string{`{"counter":{"nr":1}}`, `{"counter":{"nr":2}}`, `{"counter":{"nr":3}}`}, results)
+}
+
+func TestSubscribeCancel(t *testing.T) {
+	s := newTestSubscribeSchema(t)
+
+	responses, cancel := s.Subscribe([]byte(`subscription {counter(to: 100) {nr foo}}`), ResolveOptions{NoMeta: true})
+	a.Equal(t, `{"counter":{"nr":1,"foo":"bar"}}`, string(<-responses))
+	cancel()
+
+	for range responses {
+		// Wait for the channel to be closed
+	}
+}
+
+func TestSubscribeQuery(t *testing.T) {
+	s := newTestSubscribeSchema(t)
+
+	responses, cancel := s.Subscribe([]byte(`{a}`), ResolveOptions{NoMeta: true})
+	defer cancel()
+	a.Equal(t, `{"a":"foo"}`, string(<-responses))
+	_, ok := <-responses
+	a.False(t, ok)
+}
+
+func TestSubscribeErrors(t *testing.T) {
+	s := newTestSubscribeSchema(t)
+
+	queries := []string{
+		`subscription {fails}`,
+		`subscription {counter(to: 2) {nr} fails}`,
+		`subscription {__typename}`,
+		`subscription {doesNotExist}`,
+	}
+	for _, query := range queries {
+		responses, cancel := s.Subscribe([]byte(query), ResolveOptions{})
+		response := <-responses
+		a.True(t, strings.Contains(string(response), `"errors":[`), query)
+		_, ok := <-responses
+		a.False(t, ok, query)
+		cancel()
+	}
+}
+
+func TestSubscribeErrorExtensions(t *testing.T) {
+	s := newTestSubscribeSchema(t)
+
+	responses, cancel := s.Subscribe([]byte(`subscription {unauthorized}`), ResolveOptions{})
+	defer cancel()
+	a.Equal(t, `{"data":null,"errors":[{"message":"not logged in","locations":[{"line":1,"column":15}],"path":["unauthorized"],"extensions":{"code":"UNAUTHENTICATED"}}],"extensions":{}}`, string(<-responses))
+}
+
+func TestResolveSubscription(t *testing.T) {
+	s := newTestSubscribeSchema(t)
+
+	errs := s.Resolve([]byte(`subscription {counter(to: 3) {nr}}`), ResolveOptions{NoMeta: true})
+	a.Equal(t, 1, len(errs))
+
+	_, errs = bytecodeParseAndExpectErrs(t, `subscription {a}`, TestResolveSimpleQueryData{}, M{})
+	a.Equal(t, 1, len(errs))
+}
+
+func TestSubscriptionIntrospection(t *testing.T) {
+	s := newTestSubscribeSchema(t)
+
+	errs := s.Resolve([]byte(`{__schema {subscriptionType {name fields {name}}}}`), ResolveOptions{NoMeta: true})
+	for _, err := range errs {
+		panic(err)
+	}
+	a.Equal(t, `{"__schema":{"subscriptionType":{"name":"TestSubscribeData","fields":[{"name":"counter"},{"name":"fails"},{"name":"unauthorized"},{"name":"untilCancelled"}]}}}`, string(s.Result))
+
+	res := bytecodeParseAndExpectNoErrs(t, `{__schema {subscriptionType {name}}}`, TestResolveSimpleQueryData{}, M{})
+	a.Equal(t, `{"__schema":{"subscriptionType":null}}`, res)
+}
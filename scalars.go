@@ -0,0 +1,209 @@
+package yarql
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/mjarkk/yarql/bytecode"
+	h "github.com/mjarkk/yarql/helpers"
+	"github.com/valyala/fastjson"
+)
+
+type scalar struct {
+	goType    reflect.Type
+	typeName  string
+	serialize func(value interface{}) ([]byte, error)
+	parse     func(value *fastjson.Value) (interface{}, error)
+	qlType    qlType
+}
+
+// ScalarOptions contains optional information about a custom scalar shown in the introspection
+type ScalarOptions struct {
+	Description    string
+	SpecifiedByURL string // A link to the specification of the scalar's format
+}
+
+// RegisterScalar registers a custom scalar type
+// Every field and argument with the go type of goType will be of this scalar type, goType is a value of the go type, for example uuid.UUID{}
+//
+// serialize converts a go value of goType into JSON, the returned bytes must be valid JSON
+// parse converts the JSON value from a query or the variables into a go value, the returned value must be assignable to goType
+// If parse returns nil the go value is kept at it's zero value, the provided *fastjson.Value is only valid during the parse call
+//
+// Also the .RegisterScalar(..) method must be called before .Parse(..)
+func (s *Schema) RegisterScalar(name string, goType interface{}, serialize func(value interface{}) ([]byte, error), parse func(value *fastjson.Value) (interface{}, error), options ...ScalarOptions) error {
+	if s.parsed {
+		return errors.New("(*yarql.Schema).RegisterScalar() cannot be ran after (*yarql.Schema).Parse()")
+	}
+
+	err := validGraphQlName([]byte(name))
+	if err != nil {
+		return errors.New("RegisterScalar name must be a valid graphql name, name given: " + name)
+	}
+	if goType == nil {
+		return errors.New("RegisterScalar goType cannot be nil")
+	}
+	if serialize == nil || parse == nil {
+		return errors.New("RegisterScalar serialize and parse must be defined")
+	}
+
+	_, isBuildIn := scalars[name]
+	if isBuildIn {
+		return errors.New("cannot register scalar " + name + " as it's a build in scalar")
+	}
+
+	t := reflect.TypeOf(goType)
+	for _, definedScalar := range s.definedScalars {
+		if definedScalar.typeName == name {
+			return errors.New("scalar " + name + " is already registered")
+		}
+		if definedScalar.goType == t {
+			return errors.New("the go type " + t.String() + " is already registered as scalar " + definedScalar.typeName)
+		}
+	}
+
+	res := scalar{
+		goType:    t,
+		typeName:  name,
+		serialize: serialize,
+		parse:     parse,
+		qlType: qlType{
+			Kind:        typeKindScalar,
+			Name:        h.StrPtr(name),
+			Description: h.PtrToEmptyStr,
+		},
+	}
+	if len(options) > 0 {
+		if len(options[0].Description) > 0 {
+			res.qlType.Description = h.StrPtr(options[0].Description)
+		}
+		if len(options[0].SpecifiedByURL) > 0 {
+			res.qlType.SpecifiedByURL = h.StrPtr(options[0].SpecifiedByURL)
+		}
+	}
+
+	s.definedScalars = append(s.definedScalars, res)
+	return nil
+}
+
+func (s *Schema) getScalar(t reflect.Type) (int, *scalar) {
+	for i := range s.definedScalars {
+		if s.definedScalars[i].goType == t {
+			return i, &s.definedScalars[i]
+		}
+	}
+	return -1, nil
+}
+
+func (m *scalar) copy() *scalar {
+	return &scalar{
+		goType:    m.goType,
+		typeName:  m.typeName,
+		serialize: m.serialize,
+		parse:     m.parse,
+		qlType:    *m.qlType.copy(),
+	}
+}
+
+// resolveScalarValue writes the serialized go value of a custom scalar to the result
+func (ctx *Ctx) resolveScalarValue(goValue reflect.Value, scalarIndex int) {
+	if goValue.Kind() == reflect.Ptr && goValue.IsNil() {
+		ctx.writeNull()
+		return
+	}
+
+	value, err := ctx.schema.definedScalars[scalarIndex].serialize(goValue.Interface())
+	if err != nil {
+		ctx.writeNull()
+		ctx.addErr(err)
+		return
+	}
+	ctx.write(value)
+}
+
+// bindScalarValue binds the value in the query to goValue
+// Expects ctx.charNr to be at the start of the value and moves ctx.charNr to the instruction after the value
+func (ctx *Ctx) bindScalarValue(goValue *reflect.Value, valueStructure *input) (valueSet bool, criticalErr bool) {
+	start := ctx.charNr - 1
+	ctx.charNr = start + 8 + int(ctx.readUint32(start+3))
+
+	if ctx.query.Res[start+2] == bytecode.ValueNull {
+		// keep goValue at it's default
+		return false, false
+	}
+
+	ctx.scalarArena.Reset()
+	return ctx.assignScalarValue(goValue, valueStructure, ctx.bytecodeValueToJSON(start))
+}
+
+// assignScalarValue parses the JSON value using the custom scalar's parse function and assigns the result to goValue
+func (ctx *Ctx) assignScalarValue(goValue *reflect.Value, valueStructure *input, jsonData *fastjson.Value) (valueSet bool, criticalErr bool) {
+	if jsonData.Type() == fastjson.TypeNull {
+		// keep goValue at it's default
+		return false, false
+	}
+
+	scalar := ctx.schema.definedScalars[valueStructure.scalarIndex]
+	value, err := scalar.parse(jsonData)
+	if err != nil {
+		return false, ctx.err(err.Error())
+	}
+	if value == nil {
+		return false, false
+	}
+
+	reflectValue := reflect.ValueOf(value)
+	if !reflectValue.Type().AssignableTo(goValue.Type()) {
+		return false, ctx.errf("internal error: parsed value of scalar %s is of type %s and cannot be assigned to %s", scalar.typeName, reflectValue.Type().String(), goValue.Type().String())
+	}
+	goValue.Set(reflectValue)
+	return true, false
+}
+
+// bytecodeValueToJSON converts the value starting at pos into a JSON value allocated on ctx.scalarArena
+// Variables used inside the value are replaced with their value from the request variables
+func (ctx *Ctx) bytecodeValueToJSON(pos int) *fastjson.Value {
+	res := ctx.query.Res
+	arena := &ctx.scalarArena
+	start := pos + 7
+	end := start + int(ctx.readUint32(pos+3))
+
+	switch res[pos+2] {
+	case bytecode.ValueVariable:
+		hasVariables, _ := ctx.parseVariables()
+		if hasVariables {
+			value := ctx.variables.Get(b2s(res[start:end]))
+			if value != nil {
+				return value
+			}
+		}
+		return arena.NewNull()
+	case bytecode.ValueInt, bytecode.ValueFloat:
+		return arena.NewNumberString(b2s(res[start:end]))
+	case bytecode.ValueString, bytecode.ValueEnum:
+		return arena.NewStringBytes(res[start:end])
+	case bytecode.ValueBoolean:
+		if res[start] == '1' {
+			return arena.NewTrue()
+		}
+		return arena.NewFalse()
+	case bytecode.ValueList:
+		list := arena.NewArray()
+		idx := 0
+		for item := start; res[item+1] != bytecode.ActionEnd; idx++ {
+			list.SetArrayItem(idx, ctx.bytecodeValueToJSON(item))
+			item = item + 7 + int(ctx.readUint32(item+3))
+		}
+		return list
+	case bytecode.ValueObject:
+		object := arena.NewObject()
+		for field := start; res[field+1] == bytecode.ActionObjectValueField; {
+			name, valueStart := ctx.readNameAt(field + 2)
+			object.Set(b2s(name), ctx.bytecodeValueToJSON(valueStart))
+			field = valueStart + 7 + int(ctx.readUint32(valueStart+3))
+		}
+		return object
+	default:
+		return arena.NewNull()
+	}
+}
@@ -0,0 +1,166 @@
+package yarql
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+	"github.com/valyala/fastjson"
+)
+
+type TestScalarBigInt struct {
+	Value string
+}
+
+func serializeTestScalarBigInt(value interface{}) ([]byte, error) {
+	bigInt := value.(TestScalarBigInt)
+	if bigInt.Value == "invalid" {
+		return nil, errors.New("invalid big int")
+	}
+	return []byte(strconv.Quote(bigInt.Value)), nil
+}
+
+func parseTestScalarBigInt(value *fastjson.Value) (interface{}, error) {
+	switch value.Type() {
+	case fastjson.TypeString:
+		return TestScalarBigInt{Value: string(value.GetStringBytes())}, nil
+	case fastjson.TypeNumber:
+		return TestScalarBigInt{Value: value.String()}, nil
+	default:
+		return nil, errors.New("BigInt must be a string or number")
+	}
+}
+
+func TestRegisterScalarFails(t *testing.T) {
+	s := NewSchema()
+	err := s.RegisterScalar("BigInt", TestScalarBigInt{}, serializeTestScalarBigInt, parseTestScalarBigInt)
+	a.NoError(t, err)
+
+	err = s.RegisterScalar("BigInt", 0, serializeTestScalarBigInt, parseTestScalarBigInt)
+	a.Error(t, err, "scalar names must be unique")
+
+	err = s.RegisterScalar("OtherBigInt", TestScalarBigInt{}, serializeTestScalarBigInt, parseTestScalarBigInt)
+	a.Error(t, err, "go types can only be registered once")
+
+	err = s.RegisterScalar("String", 0, serializeTestScalarBigInt, parseTestScalarBigInt)
+	a.Error(t, err, "build in scalars cannot be overwritten")
+
+	err = s.RegisterScalar("foo bar", 0, serializeTestScalarBigInt, parseTestScalarBigInt)
+	a.Error(t, err, "scalar names must be valid graphql names")
+
+	err = s.RegisterScalar("Foo", 0, nil, nil)
+	a.Error(t, err, "serialize and parse are required")
+
+	err = s.Parse(TestResolveEmptyQueryDataQ{}, M{}, nil)
+	a.NoError(t, err)
+	err = s.RegisterScalar("Foo", 0, serializeTestScalarBigInt, parseTestScalarBigInt)
+	a.Error(t, err, "scalars cannot be registered after parsing")
+}
+
+type TestScalarData struct {
+	Big    TestScalarBigInt
+	BigPtr *TestScalarBigInt
+	Bigs   []TestScalarBigInt
+}
+
+func (TestScalarData) ResolveEcho(args struct{ Value TestScalarBigInt }) TestScalarBigInt {
+	return args.Value
+}
+
+func (TestScalarData) ResolveEchoPtr(args struct{ Value *TestScalarBigInt }) *TestScalarBigInt {
+	return args.Value
+}
+
+func (TestScalarData) ResolveEchoList(args struct{ Values []TestScalarBigInt }) []TestScalarBigInt {
+	return args.Values
+}
+
+func TestScalarOutput(t *testing.T) {
+	data := TestScalarData{
+		Big:  TestScalarBigInt{"1"},
+		Bigs: []TestScalarBigInt{{"2"}, {"3"}},
+	}
+	s := NewSchema()
+	err := s.RegisterScalar("BigInt", TestScalarBigInt{}, serializeTestScalarBigInt, parseTestScalarBigInt)
+	a.NoError(t, err)
+
+	res, errs := bytecodeParse(t, s, `{big bigPtr bigs}`, data, M{})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"big":"1","bigPtr":null,"bigs":["2","3"]}`, res)
+
+	s = NewSchema()
+	err = s.RegisterScalar("BigInt", TestScalarBigInt{}, serializeTestScalarBigInt, parseTestScalarBigInt)
+	a.NoError(t, err)
+
+	data.Big = TestScalarBigInt{"invalid"}
+	res, errs = bytecodeParse(t, s, `{big}`, data, M{})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "invalid big int", errs[0].Error())
+	a.Equal(t, `null`, res)
+}
+
+func TestScalarInput(t *testing.T) {
+	testCases := []struct {
+		name      string
+		query     string
+		variables string
+		expect    string
+	}{
+		{"string literal", `{echo(value: "123")}`, "", `{"echo":"123"}`},
+		{"number literal", `{echo(value: 123)}`, "", `{"echo":"123"}`},
+		{"null literal", `{echoPtr(value: null)}`, "", `{"echoPtr":null}`},
+		{"pointer", `{echoPtr(value: 123)}`, "", `{"echoPtr":"123"}`},
+		{"list", `{echoList(values: [1, "2"])}`, "", `{"echoList":["1","2"]}`},
+		{"variable", `query ($value: BigInt!) {echo(value: $value)}`, `{"value": 456}`, `{"echo":"456"}`},
+		{"variable with default", `query ($value: BigInt = "789") {echo(value: $value)}`, "", `{"echo":"789"}`},
+		{"variable list", `query ($values: [BigInt!]) {echoList(values: $values)}`, `{"values": ["1", 2]}`, `{"echoList":["1","2"]}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			s := NewSchema()
+			err := s.RegisterScalar("BigInt", TestScalarBigInt{}, serializeTestScalarBigInt, parseTestScalarBigInt)
+			a.NoError(t, err)
+
+			res, errs := bytecodeParse(t, s, testCase.query, TestScalarData{}, M{}, ResolveOptions{
+				NoMeta:    true,
+				Variables: testCase.variables,
+			})
+			a.Equal(t, 0, len(errs))
+			a.Equal(t, testCase.expect, res)
+		})
+	}
+}
+
+func TestScalarInputErrors(t *testing.T) {
+	s := NewSchema()
+	err := s.RegisterScalar("BigInt", TestScalarBigInt{}, serializeTestScalarBigInt, parseTestScalarBigInt)
+	a.NoError(t, err)
+
+	_, errs := bytecodeParse(t, s, `{echo(value: true)}`, TestScalarData{}, M{})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "BigInt must be a string or number", errs[0].Error())
+
+	s = NewSchema()
+	err = s.RegisterScalar("BigInt", TestScalarBigInt{}, serializeTestScalarBigInt, parseTestScalarBigInt)
+	a.NoError(t, err)
+
+	_, errs = bytecodeParse(t, s, `query ($value: String!) {echo(value: $value)}`, TestScalarData{}, M{}, ResolveOptions{Variables: `{"value": "1"}`})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, `Variable "$value" of type "String!" used in position expecting type "BigInt!".`, errs[0].Error())
+}
+
+func TestScalarIntrospection(t *testing.T) {
+	s := NewSchema()
+	err := s.RegisterScalar("BigInt", TestScalarBigInt{}, serializeTestScalarBigInt, parseTestScalarBigInt, ScalarOptions{
+		SpecifiedByURL: "https://example.com/bigint",
+	})
+	a.NoError(t, err)
+
+	res, errs := bytecodeParse(t, s, `{
+		__type(name: "BigInt") {kind name specifiedByURL}
+	}`, TestScalarData{}, M{})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"__type":{"kind":"SCALAR","name":"BigInt","specifiedByURL":"https://example.com/bigint"}}`, res)
+}
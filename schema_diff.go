@@ -0,0 +1,433 @@
+package yarql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	h "github.com/mjarkk/yarql/helpers"
+)
+
+// SchemaChangeLevel defines how a schema change affects existing clients
+type SchemaChangeLevel uint8
+
+const (
+	// SchemaChangeBreaking will break existing queries, for example a removed field or a new required argument
+	SchemaChangeBreaking SchemaChangeLevel = iota
+	// SchemaChangeDangerous won't break existing queries but might change their behavior, for example a new enum value that clients don't know about
+	SchemaChangeDangerous
+	// SchemaChangeSafe doesn't affect existing queries, for example a new field
+	SchemaChangeSafe
+)
+
+// String returns the SchemaChangeLevel as a string
+func (l SchemaChangeLevel) String() string {
+	switch l {
+	case SchemaChangeBreaking:
+		return "BREAKING"
+	case SchemaChangeDangerous:
+		return "DANGEROUS"
+	case SchemaChangeSafe:
+		return "SAFE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// SchemaChange is a single difference between two schemas
+type SchemaChange struct {
+	Level SchemaChangeLevel
+	// Path to the changed schema element, for example: User, User.name or User.posts.limit
+	Path    string
+	Message string
+}
+
+// String returns the change as a human readable string
+func (c SchemaChange) String() string {
+	return c.Level.String() + ": " + c.Message
+}
+
+// DiffSchemas compares two parsed schemas and returns the changes needed to go from the old to the new schema
+// This can be used to detect breaking changes, for example a field that is renamed because it's go name changed
+//
+// Both schemas must be parsed, otherwise an error is returned
+func DiffSchemas(before, after *Schema) ([]SchemaChange, error) {
+	if !before.parsed || !after.parsed {
+		return nil, errors.New("yarql.DiffSchemas() cannot be ran before (*yarql.Schema).Parse()")
+	}
+
+	d := &schemaDiffer{changes: []SchemaChange{}}
+
+	d.diffRootType("query", &before.rootQuery.typeName, &after.rootQuery.typeName)
+	d.diffRootType("mutation", rootTypeName(before, before.rootMethod), rootTypeName(after, after.rootMethod))
+	d.diffRootType("subscription", rootTypeName(before, before.rootSubscription), rootTypeName(after, after.rootSubscription))
+
+	newTypes := map[string]qlType{}
+	for _, t := range after.getAllQLTypes() {
+		if !introspectionTypes[*t.Name] {
+			newTypes[*t.Name] = t
+		}
+	}
+
+	oldTypes := map[string]bool{}
+	for _, oldType := range before.getAllQLTypes() {
+		name := *oldType.Name
+		if introspectionTypes[name] {
+			continue
+		}
+		oldTypes[name] = true
+		newType, ok := newTypes[name]
+		if !ok {
+			d.add(SchemaChangeBreaking, name, "Type %q was removed", name)
+			continue
+		}
+		d.diffType(oldType, newType)
+	}
+	for _, newType := range after.getAllQLTypes() {
+		name := *newType.Name
+		if !introspectionTypes[name] && !oldTypes[name] {
+			d.add(SchemaChangeSafe, name, "Type %q was added", name)
+		}
+	}
+
+	d.diffDirectives(before.getDirectives(), after.getDirectives())
+
+	return d.changes, nil
+}
+
+// rootTypeName returns the name of a root type or nil if the root type is not defined or has no fields
+func rootTypeName(s *Schema, root *obj) *string {
+	if root == nil || len(s.getObjFields(root)) == 0 {
+		return nil
+	}
+	return &root.typeName
+}
+
+type schemaDiffer struct {
+	changes []SchemaChange
+}
+
+func (d *schemaDiffer) add(level SchemaChangeLevel, path string, message string, args ...interface{}) {
+	d.changes = append(d.changes, SchemaChange{
+		Level:   level,
+		Path:    path,
+		Message: fmt.Sprintf(message, args...),
+	})
+}
+
+func (d *schemaDiffer) diffRootType(operation string, before, after *string) {
+	switch {
+	case before == nil && after == nil:
+		return
+	case before == nil:
+		d.add(SchemaChangeSafe, *after, "Schema %s root type %q was added", operation, *after)
+	case after == nil:
+		d.add(SchemaChangeBreaking, *before, "Schema %s root type %q was removed", operation, *before)
+	case *before != *after:
+		d.add(SchemaChangeBreaking, *after, "Schema %s root type changed from %q to %q", operation, *before, *after)
+	}
+}
+
+func (d *schemaDiffer) diffDescription(path string, before, after *string) {
+	oldDescription, newDescription := "", ""
+	if before != nil {
+		oldDescription = *before
+	}
+	if after != nil {
+		newDescription = *after
+	}
+	if oldDescription != newDescription {
+		d.add(SchemaChangeSafe, path, "Description of %q changed", path)
+	}
+}
+
+func (d *schemaDiffer) diffDeprecation(path string, before, after *string) {
+	if before == nil && after != nil {
+		d.add(SchemaChangeSafe, path, "%q was deprecated", path)
+	} else if before != nil && after == nil {
+		d.add(SchemaChangeSafe, path, "%q is no longer deprecated", path)
+	}
+}
+
+func (d *schemaDiffer) diffType(before, after qlType) {
+	name := *before.Name
+	if before.Kind != after.Kind {
+		d.add(SchemaChangeBreaking, name, "%q changed from a %s type to a %s type", name, before.Kind.String(), after.Kind.String())
+		return
+	}
+
+	d.diffDescription(name, before.Description, after.Description)
+
+	includeAll := isDeprecatedArgs{IncludeDeprecated: h.BoolPtr(true)}
+	switch before.Kind {
+	case typeKindObject, typeKindInterface:
+		d.diffInterfaces(name, before.Interfaces, after.Interfaces)
+		d.diffFields(name, before.Fields(includeAll), after.Fields(includeAll))
+	case typeKindUnion:
+		d.diffUnionMembers(name, before.PossibleTypes(), after.PossibleTypes())
+	case typeKindEnum:
+		d.diffEnumValues(name, before.EnumValues(includeAll), after.EnumValues(includeAll))
+	case typeKindInputObject:
+		d.diffInputValues(name, "input field", before.InputFields(includeAll), after.InputFields(includeAll))
+	}
+}
+
+func (d *schemaDiffer) diffInterfaces(typeName string, before, after []qlType) {
+	oldNames := qlTypeNames(before)
+	newNames := qlTypeNames(after)
+	for _, name := range oldNames {
+		if !containsString(newNames, name) {
+			d.add(SchemaChangeBreaking, typeName, "%q no longer implements interface %q", typeName, name)
+		}
+	}
+	for _, name := range newNames {
+		if !containsString(oldNames, name) {
+			d.add(SchemaChangeDangerous, typeName, "%q now implements interface %q", typeName, name)
+		}
+	}
+}
+
+func (d *schemaDiffer) diffUnionMembers(typeName string, before, after []qlType) {
+	oldNames := qlTypeNames(before)
+	newNames := qlTypeNames(after)
+	for _, name := range oldNames {
+		if !containsString(newNames, name) {
+			d.add(SchemaChangeBreaking, typeName, "Member %q was removed from union %q", name, typeName)
+		}
+	}
+	for _, name := range newNames {
+		if !containsString(oldNames, name) {
+			d.add(SchemaChangeDangerous, typeName, "Member %q was added to union %q", name, typeName)
+		}
+	}
+}
+
+func (d *schemaDiffer) diffEnumValues(typeName string, before, after []qlEnumValue) {
+	newValues := map[string]qlEnumValue{}
+	for _, value := range after {
+		newValues[value.Name] = value
+	}
+
+	oldValues := map[string]bool{}
+	for _, oldValue := range before {
+		oldValues[oldValue.Name] = true
+		path := typeName + "." + oldValue.Name
+		newValue, ok := newValues[oldValue.Name]
+		if !ok {
+			d.add(SchemaChangeBreaking, path, "Enum value %q was removed from enum %q", oldValue.Name, typeName)
+			continue
+		}
+		d.diffDescription(path, oldValue.Description, newValue.Description)
+		d.diffDeprecation(path, oldValue.DeprecationReason, newValue.DeprecationReason)
+	}
+
+	for _, newValue := range after {
+		if !oldValues[newValue.Name] {
+			d.add(SchemaChangeDangerous, typeName+"."+newValue.Name, "Enum value %q was added to enum %q", newValue.Name, typeName)
+		}
+	}
+}
+
+func (d *schemaDiffer) diffFields(typeName string, before, after []qlField) {
+	includeAll := isDeprecatedArgs{IncludeDeprecated: h.BoolPtr(true)}
+
+	newFields := map[string]qlField{}
+	for _, field := range after {
+		newFields[field.Name] = field
+	}
+
+	oldFields := map[string]bool{}
+	for _, oldField := range before {
+		oldFields[oldField.Name] = true
+		path := typeName + "." + oldField.Name
+		newField, ok := newFields[oldField.Name]
+		if !ok {
+			d.add(SchemaChangeBreaking, path, "Field %q was removed", path)
+			continue
+		}
+
+		oldType := qlTypeString(oldField.Type)
+		newType := qlTypeString(newField.Type)
+		if oldType != newType {
+			level := SchemaChangeBreaking
+			if isSafeOutputTypeChange(oldField.Type, newField.Type) {
+				level = SchemaChangeSafe
+			}
+			d.add(level, path, "Field %q changed type from %q to %q", path, oldType, newType)
+		}
+
+		d.diffDescription(path, oldField.Description, newField.Description)
+		d.diffDeprecation(path, oldField.DeprecationReason, newField.DeprecationReason)
+		d.diffInputValues(path, "argument", oldField.Args(includeAll), newField.Args(includeAll))
+	}
+
+	for _, newField := range after {
+		if !oldFields[newField.Name] {
+			path := typeName + "." + newField.Name
+			d.add(SchemaChangeSafe, path, "Field %q was added", path)
+		}
+	}
+}
+
+// diffInputValues compares arguments or input object fields, kind is used in the messages to describe the values
+func (d *schemaDiffer) diffInputValues(parentPath string, kind string, before, after []qlInputValue) {
+	capitalizedKind := strings.ToUpper(kind[:1]) + kind[1:]
+
+	newValues := map[string]qlInputValue{}
+	for _, value := range after {
+		newValues[value.Name] = value
+	}
+
+	oldValues := map[string]bool{}
+	for _, oldValue := range before {
+		oldValues[oldValue.Name] = true
+		path := parentPath + "." + oldValue.Name
+		newValue, ok := newValues[oldValue.Name]
+		if !ok {
+			d.add(SchemaChangeBreaking, path, "%s %q was removed", capitalizedKind, path)
+			continue
+		}
+
+		oldType := qlTypeString(oldValue.Type)
+		newType := qlTypeString(newValue.Type)
+		if oldType != newType {
+			level := SchemaChangeBreaking
+			if isSafeInputTypeChange(oldValue.Type, newValue.Type) {
+				level = SchemaChangeSafe
+			}
+			d.add(level, path, "%s %q changed type from %q to %q", capitalizedKind, path, oldType, newType)
+		}
+
+		oldDefault, newDefault := "", ""
+		if oldValue.DefaultValue != nil {
+			oldDefault = *oldValue.DefaultValue
+		}
+		if newValue.DefaultValue != nil {
+			newDefault = *newValue.DefaultValue
+		}
+		if oldDefault != newDefault {
+			d.add(SchemaChangeDangerous, path, "Default value of %s %q changed from %q to %q", kind, path, oldDefault, newDefault)
+		}
+
+		d.diffDescription(path, oldValue.Description, newValue.Description)
+		d.diffDeprecation(path, oldValue.DeprecationReason, newValue.DeprecationReason)
+	}
+
+	for _, newValue := range after {
+		if oldValues[newValue.Name] {
+			continue
+		}
+		path := parentPath + "." + newValue.Name
+		if newValue.Type.Kind == typeKindNonNull && newValue.DefaultValue == nil {
+			d.add(SchemaChangeBreaking, path, "Required %s %q was added", kind, path)
+		} else {
+			d.add(SchemaChangeDangerous, path, "Optional %s %q was added", kind, path)
+		}
+	}
+}
+
+func (d *schemaDiffer) diffDirectives(before, after []qlDirective) {
+	includeAll := isDeprecatedArgs{IncludeDeprecated: h.BoolPtr(true)}
+
+	newDirectives := map[string]qlDirective{}
+	for _, directive := range after {
+		newDirectives[directive.Name] = directive
+	}
+
+	oldDirectives := map[string]bool{}
+	for _, oldDirective := range before {
+		oldDirectives[oldDirective.Name] = true
+		path := "@" + oldDirective.Name
+		newDirective, ok := newDirectives[oldDirective.Name]
+		if !ok {
+			d.add(SchemaChangeBreaking, path, "Directive %q was removed", path)
+			continue
+		}
+
+		for _, location := range oldDirective.Locations {
+			if !containsDirectiveLocation(newDirective.Locations, location) {
+				d.add(SchemaChangeBreaking, path, "Location %s was removed from directive %q", location.String(), path)
+			}
+		}
+		for _, location := range newDirective.Locations {
+			if !containsDirectiveLocation(oldDirective.Locations, location) {
+				d.add(SchemaChangeSafe, path, "Location %s was added to directive %q", location.String(), path)
+			}
+		}
+
+		d.diffDescription(path, oldDirective.Description, newDirective.Description)
+		d.diffInputValues(path, "argument", oldDirective.Args(includeAll), newDirective.Args(includeAll))
+	}
+
+	for _, newDirective := range after {
+		if !oldDirectives[newDirective.Name] {
+			path := "@" + newDirective.Name
+			d.add(SchemaChangeSafe, path, "Directive %q was added", path)
+		}
+	}
+}
+
+// isSafeOutputTypeChange returns true if every value of the new type is also a valid value of the old type
+// For example String to String! is safe as clients already handle strings
+func isSafeOutputTypeChange(before, after qlType) bool {
+	switch before.Kind {
+	case typeKindList:
+		if after.Kind == typeKindNonNull {
+			return isSafeOutputTypeChange(before, *after.OfType)
+		}
+		return after.Kind == typeKindList && isSafeOutputTypeChange(*before.OfType, *after.OfType)
+	case typeKindNonNull:
+		return after.Kind == typeKindNonNull && isSafeOutputTypeChange(*before.OfType, *after.OfType)
+	default:
+		if after.Kind == typeKindNonNull {
+			return isSafeOutputTypeChange(before, *after.OfType)
+		}
+		return after.Kind != typeKindList && *before.Name == *after.Name
+	}
+}
+
+// isSafeInputTypeChange returns true if every value accepted by the old type is also accepted by the new type
+// For example String! to String is safe as clients only send strings
+func isSafeInputTypeChange(before, after qlType) bool {
+	switch before.Kind {
+	case typeKindList:
+		return after.Kind == typeKindList && isSafeInputTypeChange(*before.OfType, *after.OfType)
+	case typeKindNonNull:
+		if after.Kind == typeKindNonNull {
+			return isSafeInputTypeChange(*before.OfType, *after.OfType)
+		}
+		return isSafeInputTypeChange(*before.OfType, after)
+	default:
+		return after.Kind != typeKindList && after.Kind != typeKindNonNull && *before.Name == *after.Name
+	}
+}
+
+func qlTypeString(t qlType) string {
+	return string(appendSDLTypeName(nil, t))
+}
+
+func qlTypeNames(types []qlType) []string {
+	res := make([]string, len(types))
+	for idx, t := range types {
+		res[idx] = *t.Name
+	}
+	return res
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsDirectiveLocation(list []__DirectiveLocation, value __DirectiveLocation) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package yarql
+
+import (
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+func TestDiffSchemasNoChanges(t *testing.T) {
+	type Role string
+	type Filter struct {
+		Name string
+	}
+	type Query struct {
+		Name   string
+		Age    *int
+		Email  string
+		Role   Role
+		Search func(args struct {
+			Query  *string
+			Limit  int
+			Filter *Filter
+		}) []string
+	}
+
+	before := NewSchema()
+	_, err := before.RegisterEnum(map[string]Role{"ADMIN": "admin", "USER": "user"})
+	a.NoError(t, err)
+	err = before.Parse(Query{}, M{}, nil)
+	a.NoError(t, err)
+
+	after := NewSchema()
+	_, err = after.RegisterEnum(map[string]Role{"ADMIN": "admin", "USER": "user"})
+	a.NoError(t, err)
+	err = after.Parse(Query{}, M{}, nil)
+	a.NoError(t, err)
+
+	changes, err := DiffSchemas(before, after)
+	a.NoError(t, err)
+	a.Equal(t, 0, len(changes))
+}
+
+func TestDiffSchemas(t *testing.T) {
+	// The go types of both schema versions have the same names so they are defined in their own scope
+	before := NewSchema()
+	{
+		type Role string
+		type Filter struct {
+			Name string
+		}
+		type Query struct {
+			Name   string
+			Age    *int
+			Email  string
+			Role   Role
+			Search func(args struct {
+				Query  *string
+				Limit  int
+				Filter *Filter
+			}) []string
+		}
+
+		_, err := before.RegisterEnum(map[string]Role{"ADMIN": "admin", "USER": "user"})
+		a.NoError(t, err)
+		err = before.Parse(Query{}, M{}, nil)
+		a.NoError(t, err)
+	}
+
+	after := NewSchema()
+	{
+		type Role string
+		type Filter struct {
+			Name  *string
+			Limit int
+		}
+		type Query struct {
+			Name   string `gq:",deprecated"`
+			Age    int
+			Phone  string
+			Role   Role
+			Search func(args struct {
+				Query  string
+				Limit  *int
+				Page   int
+				Offset *int
+				Filter *Filter
+			}) []string
+		}
+
+		_, err := after.RegisterEnum(map[string]Role{"ADMIN": "admin", "GUEST": "guest"})
+		a.NoError(t, err)
+		err = after.Parse(Query{}, M{}, nil)
+		a.NoError(t, err)
+	}
+
+	changes, err := DiffSchemas(before, after)
+	a.NoError(t, err)
+
+	res := []string{}
+	for _, change := range changes {
+		res = append(res, change.String())
+	}
+	a.Equal(t, []string{
+		`SAFE: Input field "Filter.name" changed type from "String!" to "String"`,
+		`BREAKING: Required input field "Filter.limit" was added`,
+		`SAFE: Field "Query.age" changed type from "Int" to "Int!"`,
+		`BREAKING: Field "Query.email" was removed`,
+		`SAFE: "Query.name" was deprecated`,
+		`SAFE: Argument "Query.search.limit" changed type from "Int!" to "Int"`,
+		`BREAKING: Argument "Query.search.query" changed type from "String" to "String!"`,
+		`DANGEROUS: Optional argument "Query.search.offset" was added`,
+		`BREAKING: Required argument "Query.search.page" was added`,
+		`SAFE: Field "Query.phone" was added`,
+		`BREAKING: Enum value "USER" was removed from enum "Role"`,
+		`DANGEROUS: Enum value "GUEST" was added to enum "Role"`,
+	}, res)
+
+	a.Equal(t, "Query.email", changes[3].Path)
+	a.Equal(t, SchemaChangeBreaking, changes[3].Level)
+}
+
+func TestDiffSchemasTypes(t *testing.T) {
+	Implements((*InterfaceType)(nil), BarWImpl{})
+	Implements((*InterfaceType)(nil), BazWImpl{})
+
+	type Query struct {
+		Search []TestUnionSearchResult
+	}
+	type QueryV2 struct {
+		Bar BarWImpl
+	}
+	TypeRename(QueryV2{}, "Query")
+
+	before := NewSchema()
+	err := before.RegisterUnion("SearchResult", (*TestUnionSearchResult)(nil), TestUnionUser{}, TestUnionPost{}, TestUnionComment{})
+	a.NoError(t, err)
+	err = before.Parse(Query{}, M{}, nil)
+	a.NoError(t, err)
+
+	after := NewSchema()
+	err = after.Parse(QueryV2{}, M{}, nil)
+	a.NoError(t, err)
+
+	changes, err := DiffSchemas(before, after)
+	a.NoError(t, err)
+	res := map[string]SchemaChangeLevel{}
+	for _, change := range changes {
+		res[change.Message] = change.Level
+	}
+
+	a.Equal(t, SchemaChangeBreaking, res[`Type "SearchResult" was removed`])
+	a.Equal(t, SchemaChangeBreaking, res[`Field "Query.search" was removed`])
+	a.Equal(t, SchemaChangeSafe, res[`Type "InterfaceType" was added`])
+	a.Equal(t, SchemaChangeSafe, res[`Field "Query.bar" was added`])
+}
+
+func TestDiffSchemasUnparsed(t *testing.T) {
+	_, err := DiffSchemas(NewSchema(), NewSchema())
+	a.Error(t, err)
+}
@@ -0,0 +1,312 @@
+package yarql
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	h "github.com/mjarkk/yarql/helpers"
+)
+
+// introspectionTypes are the types used by the introspection, these are part of every graphql schema and are thus not included in the SDL
+var introspectionTypes = map[string]bool{
+	"__Schema":            true,
+	"__Type":              true,
+	"__TypeKind":          true,
+	"__Field":             true,
+	"__InputValue":        true,
+	"__EnumValue":         true,
+	"__Directive":         true,
+	"__DirectiveLocation": true,
+}
+
+// buildInScalars are the scalars every graphql implementation has, these are not included in the SDL
+var buildInScalars = map[string]bool{
+	"Boolean": true,
+	"Int":     true,
+	"Float":   true,
+	"String":  true,
+	"ID":      true,
+}
+
+// buildInDirectives are the directives defined by the graphql spec, these are not included in the SDL
+var buildInDirectives = map[string]bool{
+	"skip":       true,
+	"include":    true,
+	"deprecated": true,
+}
+
+// SDL returns the schema in the graphql schema definition language
+// This is mainly useful to generate a schema file for tools like graphql-codegen or relay-compiler
+//
+// The schema must be parsed before this method can be used
+func (s *Schema) SDL() (string, error) {
+	var res strings.Builder
+	err := s.WriteSDL(&res)
+	return res.String(), err
+}
+
+// WriteSDL writes the schema in the graphql schema definition language to w
+func (s *Schema) WriteSDL(w io.Writer) error {
+	if !s.parsed {
+		return errors.New("(*yarql.Schema).WriteSDL() cannot be ran before (*yarql.Schema).Parse()")
+	}
+
+	_, err := w.Write(s.appendSDL([]byte{}))
+	return err
+}
+
+func (s *Schema) appendSDL(res []byte) []byte {
+	hasMutations := len(s.getObjFields(s.rootMethod)) > 0
+
+	// The schema definition can only be omitted if the root types use the default names
+	if s.rootQuery.typeName != "Query" ||
+		(hasMutations && s.rootMethod.typeName != "Mutation") ||
+		(s.rootSubscription != nil && s.rootSubscription.typeName != "Subscription") {
+		res = append(res, "schema {\n  query: "...)
+		res = append(res, s.rootQuery.typeName...)
+		if hasMutations {
+			res = append(res, "\n  mutation: "...)
+			res = append(res, s.rootMethod.typeName...)
+		}
+		if s.rootSubscription != nil {
+			res = append(res, "\n  subscription: "...)
+			res = append(res, s.rootSubscription.typeName...)
+		}
+		res = append(res, "\n}\n"...)
+	}
+
+	includeAll := isDeprecatedArgs{IncludeDeprecated: h.BoolPtr(true)}
+
+	for _, directive := range s.getDirectives() {
+		if buildInDirectives[directive.Name] {
+			continue
+		}
+
+		res = appendSDLSeparator(res)
+		res = appendSDLDescription(res, directive.Description, "")
+		res = append(res, "directive @"...)
+		res = append(res, directive.Name...)
+		res = appendSDLArgs(res, directive.Args(includeAll), "")
+		res = append(res, " on "...)
+		for idx, location := range directive.Locations {
+			if idx > 0 {
+				res = append(res, " | "...)
+			}
+			res = append(res, location.String()...)
+		}
+		res = append(res, '\n')
+	}
+
+	for _, t := range s.getAllQLTypes() {
+		name := *t.Name
+		if introspectionTypes[name] || (t.Kind == typeKindScalar && buildInScalars[name]) {
+			continue
+		}
+		if name == s.rootMethod.typeName && !hasMutations {
+			continue
+		}
+
+		res = appendSDLSeparator(res)
+		res = appendSDLDescription(res, t.Description, "")
+		switch t.Kind {
+		case typeKindScalar:
+			res = append(res, "scalar "...)
+			res = append(res, name...)
+			if t.SpecifiedByURL != nil {
+				res = append(res, " @specifiedBy(url: "...)
+				h.StringToJSON(*t.SpecifiedByURL, &res)
+				res = append(res, ')')
+			}
+			res = append(res, '\n')
+		case typeKindObject, typeKindInterface:
+			if t.Kind == typeKindObject {
+				res = append(res, "type "...)
+			} else {
+				res = append(res, "interface "...)
+			}
+			res = append(res, name...)
+			for idx, implementation := range t.Interfaces {
+				if idx == 0 {
+					res = append(res, " implements "...)
+				} else {
+					res = append(res, " & "...)
+				}
+				res = append(res, *implementation.Name...)
+			}
+
+			fields := t.Fields(includeAll)
+			if len(fields) == 0 {
+				res = append(res, '\n')
+				continue
+			}
+			res = append(res, " {\n"...)
+			for idx, field := range fields {
+				if idx > 0 && field.Description != nil && len(*field.Description) > 0 {
+					res = append(res, '\n')
+				}
+				res = appendSDLDescription(res, field.Description, "  ")
+				res = append(res, "  "...)
+				res = append(res, field.Name...)
+				res = appendSDLArgs(res, field.Args(includeAll), "  ")
+				res = append(res, ": "...)
+				res = appendSDLTypeName(res, field.Type)
+				res = appendSDLDeprecated(res, field.DeprecationReason)
+				res = append(res, '\n')
+			}
+			res = append(res, "}\n"...)
+		case typeKindUnion:
+			res = append(res, "union "...)
+			res = append(res, name...)
+			for idx, possibleType := range t.PossibleTypes() {
+				if idx == 0 {
+					res = append(res, " = "...)
+				} else {
+					res = append(res, " | "...)
+				}
+				res = append(res, *possibleType.Name...)
+			}
+			res = append(res, '\n')
+		case typeKindEnum:
+			res = append(res, "enum "...)
+			res = append(res, name...)
+			res = append(res, " {\n"...)
+			for idx, value := range t.EnumValues(includeAll) {
+				if idx > 0 && value.Description != nil && len(*value.Description) > 0 {
+					res = append(res, '\n')
+				}
+				res = appendSDLDescription(res, value.Description, "  ")
+				res = append(res, "  "...)
+				res = append(res, value.Name...)
+				res = appendSDLDeprecated(res, value.DeprecationReason)
+				res = append(res, '\n')
+			}
+			res = append(res, "}\n"...)
+		case typeKindInputObject:
+			res = append(res, "input "...)
+			res = append(res, name...)
+			inputFields := t.InputFields(includeAll)
+			if len(inputFields) == 0 {
+				res = append(res, '\n')
+				continue
+			}
+			res = append(res, " {\n"...)
+			for idx, field := range inputFields {
+				if idx > 0 && field.Description != nil && len(*field.Description) > 0 {
+					res = append(res, '\n')
+				}
+				res = appendSDLInputValue(res, field, "  ")
+				res = append(res, '\n')
+			}
+			res = append(res, "}\n"...)
+		}
+	}
+
+	return res
+}
+
+// appendSDLSeparator adds a empty line between definitions
+func appendSDLSeparator(res []byte) []byte {
+	if len(res) == 0 {
+		return res
+	}
+	return append(res, '\n')
+}
+
+func appendSDLDescription(res []byte, description *string, indent string) []byte {
+	if description == nil || len(*description) == 0 {
+		return res
+	}
+
+	value := strings.ReplaceAll(*description, `"""`, `\"""`)
+	res = append(res, indent...)
+	if !strings.Contains(value, "\n") && !strings.HasSuffix(value, `"`) {
+		res = append(res, `"""`...)
+		res = append(res, value...)
+		return append(res, "\"\"\"\n"...)
+	}
+
+	res = append(res, "\"\"\"\n"...)
+	for _, line := range strings.Split(value, "\n") {
+		if len(line) > 0 {
+			res = append(res, indent...)
+			res = append(res, line...)
+		}
+		res = append(res, '\n')
+	}
+	res = append(res, indent...)
+	return append(res, "\"\"\"\n"...)
+}
+
+// appendSDLArgs writes the arguments of a field or directive
+// If one of the arguments has a description every argument is placed on it's own line
+func appendSDLArgs(res []byte, args []qlInputValue, indent string) []byte {
+	if len(args) == 0 {
+		return res
+	}
+
+	multiline := false
+	for _, arg := range args {
+		if arg.Description != nil && len(*arg.Description) > 0 {
+			multiline = true
+			break
+		}
+	}
+
+	res = append(res, '(')
+	for idx, arg := range args {
+		if multiline {
+			res = append(res, '\n')
+			res = appendSDLInputValue(res, arg, indent+"  ")
+		} else {
+			if idx > 0 {
+				res = append(res, ", "...)
+			}
+			res = appendSDLInputValue(res, arg, "")
+		}
+	}
+	if multiline {
+		res = append(res, '\n')
+		res = append(res, indent...)
+	}
+	return append(res, ')')
+}
+
+func appendSDLInputValue(res []byte, value qlInputValue, indent string) []byte {
+	res = appendSDLDescription(res, value.Description, indent)
+	res = append(res, indent...)
+	res = append(res, value.Name...)
+	res = append(res, ": "...)
+	res = appendSDLTypeName(res, value.Type)
+	if value.DefaultValue != nil {
+		res = append(res, " = "...)
+		res = append(res, *value.DefaultValue...)
+	}
+	return appendSDLDeprecated(res, value.DeprecationReason)
+}
+
+func appendSDLDeprecated(res []byte, reason *string) []byte {
+	if reason == nil {
+		return res
+	}
+	if *reason == defaultDeprecationReason {
+		return append(res, " @deprecated"...)
+	}
+	res = append(res, " @deprecated(reason: "...)
+	h.StringToJSON(*reason, &res)
+	return append(res, ')')
+}
+
+func appendSDLTypeName(res []byte, t qlType) []byte {
+	switch t.Kind {
+	case typeKindNonNull:
+		res = appendSDLTypeName(res, *t.OfType)
+		return append(res, '!')
+	case typeKindList:
+		res = append(res, '[')
+		res = appendSDLTypeName(res, *t.OfType)
+		return append(res, ']')
+	default:
+		return append(res, *t.Name...)
+	}
+}
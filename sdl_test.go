@@ -0,0 +1,163 @@
+package yarql
+
+import (
+	"strings"
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+type TestSDLQuery struct {
+	Name   string `gqdesc:"The name"`
+	Old    *int   `gq:",deprecated=Use name"`
+	Search []TestUnionSearchResult
+	Bar    BarWImpl
+	At     TestSDLTime
+}
+
+type TestSDLTime struct {
+	CreatedAt TestScalarBigInt
+}
+
+func (TestSDLQuery) ResolveGreet(args struct {
+	Name   string `gqdesc:"Who to greet"`
+	Filter *TestDescriptionsFilter
+}) TestDescriptionsEnum {
+	return ""
+}
+
+type TestSDLMutation struct{}
+
+func (TestSDLMutation) ResolveUpdate(args struct{ Id int }) bool { return true }
+
+func TestSDL(t *testing.T) {
+	Implements((*InterfaceType)(nil), BarWImpl{})
+	Implements((*InterfaceType)(nil), BazWImpl{})
+
+	s := NewSchema()
+	err := s.RegisterUnion("SearchResult", (*TestUnionSearchResult)(nil), TestUnionUser{}, TestUnionPost{}, TestUnionComment{})
+	a.NoError(t, err)
+	_, err = s.RegisterEnum(map[string]TestDescriptionsEnum{"A": "a", "B": "b"}, EnumOptions{
+		Description:      "A enum",
+		DeprecatedValues: map[string]string{"B": ""},
+	})
+	a.NoError(t, err)
+	err = s.RegisterScalar("BigInt", TestScalarBigInt{}, serializeTestScalarBigInt, parseTestScalarBigInt)
+	a.NoError(t, err)
+	err = s.RegisterDirective(Directive{
+		Name:        "upper",
+		Where:       []DirectiveLocation{DirectiveLocationField, DirectiveLocationFragmentInline},
+		Method:      func(args struct{ Force *bool }) DirectiveModifier { return DirectiveModifier{} },
+		Description: "Uppercases the field",
+	})
+	a.NoError(t, err)
+
+	err = s.WriteSDL(&strings.Builder{})
+	a.Error(t, err, "the schema must be parsed")
+	_, err = s.SDL()
+	a.Error(t, err, "the schema must be parsed")
+
+	err = s.Parse(TestSDLQuery{}, TestSDLMutation{}, nil)
+	a.NoError(t, err)
+
+	expect := `schema {
+  query: TestSDLQuery
+  mutation: TestSDLMutation
+}
+
+"""Uppercases the field"""
+directive @upper(force: Boolean) on FIELD | INLINE_FRAGMENT
+
+type BarWImpl implements InterfaceType {
+  bar: String!
+  extraBarField: String!
+  foo: String!
+}
+
+type BazWImpl implements InterfaceType {
+  bar: String!
+  extraBazField: String!
+  foo: String!
+}
+
+scalar BigInt
+
+"""The File scalar type references to a multipart file, often used to upload files to the server. Expects a string with the form file field name"""
+scalar File @specifiedBy(url: "https://github.com/mjarkk/yarql#file-upload")
+
+interface InterfaceType {
+  bar: String!
+  foo: String!
+}
+
+union SearchResult = TestUnionUser | TestUnionPost | TestUnionComment
+
+"""A enum"""
+enum TestDescriptionsEnum {
+  A
+  B @deprecated
+}
+
+"""A filter"""
+input TestDescriptionsFilter {
+  """The max amount of results"""
+  limit: Int!
+}
+
+type TestSDLMutation {
+  update(id: Int!): Boolean!
+}
+
+type TestSDLQuery {
+  at: TestSDLTime!
+  bar: BarWImpl!
+  greet(
+    filter: TestDescriptionsFilter
+    """Who to greet"""
+    name: String!
+  ): TestDescriptionsEnum!
+
+  """The name"""
+  name: String!
+  old: Int @deprecated(reason: "Use name")
+  search: [SearchResult]
+}
+
+type TestSDLTime {
+  createdAt: BigInt!
+}
+
+type TestUnionComment {
+  message: String!
+}
+
+type TestUnionPost {
+  title: String!
+}
+
+type TestUnionUser {
+  name: String!
+}
+
+"""The Time scalar type references to a ISO 8601 date+time, often used to insert and/or view dates. Expects a string with the ISO 8601 format"""
+scalar Time @specifiedBy(url: "https://en.wikipedia.org/wiki/ISO_8601")
+`
+	sdl, err := s.SDL()
+	a.NoError(t, err)
+	a.Equal(t, expect, sdl)
+}
+
+func TestSDLDefaultRootNames(t *testing.T) {
+	type Query struct{ Name string }
+	type Mutation struct{}
+
+	s := NewSchema()
+	err := s.Parse(Query{}, Mutation{}, nil)
+	a.NoError(t, err)
+
+	sdl, err := s.SDL()
+	a.NoError(t, err)
+	a.False(t, strings.HasPrefix(sdl, "schema"), "the schema definition should be omitted for default root type names")
+	a.False(t, strings.Contains(sdl, "type Mutation"), "the mutation type should be omitted if it has no fields")
+	a.True(t, strings.Contains(sdl, "type Query {\n  name: String!\n}\n"))
+}
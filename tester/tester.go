@@ -26,7 +26,7 @@ func GetTypeByName(s *graphql.Schema, typename string) *Type {
 	vars := map[string]string{"typename": typename}
 	varsJSON, _ := json.Marshal(vars)
 
-	query := `query ($typename: String) {
+	query := `query ($typename: String!) {
 		__type(name: $typename) {
 			kind
 			fields {
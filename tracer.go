@@ -0,0 +1,116 @@
+package yarql
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Tracer receives spans for the different stages of resolving a request
+// The same tracer is used by all requests, with parallel execution fields might be started from multiple goroutines at once
+//
+// Tracers can be set using SchemaOptions.Tracer or ResolveOptions.Tracer,
+// apollo tracing (ResolveOptions.Tracing) is also implemented as a tracer and can be used next to a custom tracer
+type Tracer interface {
+	// StartOperation is called when a request starts, the span ends after the response is written
+	// query is only valid until the span ends
+	StartOperation(ctx *Ctx, query []byte, operationName string) Span
+	// StartParsing is called before the query is parsed
+	StartParsing(ctx *Ctx) Span
+	// StartValidation is called before the query is validated
+	StartValidation(ctx *Ctx) Span
+	// StartField is called before a field is resolved
+	StartField(ctx *Ctx, field TracedField) Span
+}
+
+// Span is a traced stage of a request
+type Span interface {
+	// End is called when the traced stage is done
+	End()
+}
+
+// TracedField describes the field a span is started for
+type TracedField struct {
+	ParentType string
+	FieldName  string
+	Alias      string
+	ReturnType string
+	// Path is the graphql path to the field json encoded, see (*Ctx).GetPath
+	Path json.RawMessage
+}
+
+// multiTracer sends spans to multiple tracers
+type multiTracer []Tracer
+
+type multiSpan []Span
+
+func (t multiTracer) StartOperation(ctx *Ctx, query []byte, operationName string) Span {
+	spans := make(multiSpan, len(t))
+	for idx, tracer := range t {
+		spans[idx] = tracer.StartOperation(ctx, query, operationName)
+	}
+	return spans
+}
+
+func (t multiTracer) StartParsing(ctx *Ctx) Span {
+	spans := make(multiSpan, len(t))
+	for idx, tracer := range t {
+		spans[idx] = tracer.StartParsing(ctx)
+	}
+	return spans
+}
+
+func (t multiTracer) StartValidation(ctx *Ctx) Span {
+	spans := make(multiSpan, len(t))
+	for idx, tracer := range t {
+		spans[idx] = tracer.StartValidation(ctx)
+	}
+	return spans
+}
+
+func (t multiTracer) StartField(ctx *Ctx, field TracedField) Span {
+	spans := make(multiSpan, len(t))
+	for idx, tracer := range t {
+		spans[idx] = tracer.StartField(ctx, field)
+	}
+	return spans
+}
+
+func (s multiSpan) End() {
+	// End the spans in reverse order so the outer most tracer ends last
+	for i := len(s) - 1; i >= 0; i-- {
+		s[i].End()
+	}
+}
+
+// requestTracer returns the tracer used by a request, nil if nothing is traced
+func (s *Schema) requestTracer(opts *ResolveOptions) Tracer {
+	if opts.validateOnly {
+		// The query is traced when it's executed
+		return nil
+	}
+	tracer := opts.Tracer
+	if tracer == nil {
+		tracer = s.tracer
+	}
+	if !opts.Tracing {
+		return tracer
+	}
+	if tracer == nil {
+		return apolloTracer{}
+	}
+	return multiTracer{apolloTracer{}, tracer}
+}
+
+// tracedField returns the description of a field passed to the tracer
+func (ctx *Ctx) tracedField(typeObj *obj, field *obj, name []byte, alias []byte) *TracedField {
+	returnType := bytes.NewBuffer(nil)
+	ctx.schema.objToQlTypeName(field, returnType)
+
+	return &TracedField{
+		ParentType: typeObj.typeName,
+		FieldName:  string(name),
+		Alias:      string(alias),
+		ReturnType: returnType.String(),
+		Path:       ctx.GetPath(),
+	}
+}
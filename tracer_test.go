@@ -0,0 +1,143 @@
+package yarql
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+// testRecordingTracer records all started and ended spans
+type testRecordingTracer struct {
+	lock   sync.Mutex
+	events []string
+}
+
+type testRecordingSpan struct {
+	tracer *testRecordingTracer
+	name   string
+}
+
+func (t *testRecordingTracer) record(event string) {
+	t.lock.Lock()
+	t.events = append(t.events, event)
+	t.lock.Unlock()
+}
+
+func (t *testRecordingTracer) start(name string) Span {
+	t.record("start " + name)
+	return &testRecordingSpan{tracer: t, name: name}
+}
+
+func (s *testRecordingSpan) End() {
+	s.tracer.record("end " + s.name)
+}
+
+func (t *testRecordingTracer) StartOperation(ctx *Ctx, query []byte, operationName string) Span {
+	return t.start("operation " + operationName + " " + string(query))
+}
+
+func (t *testRecordingTracer) StartParsing(ctx *Ctx) Span {
+	return t.start("parsing")
+}
+
+func (t *testRecordingTracer) StartValidation(ctx *Ctx) Span {
+	return t.start("validation")
+}
+
+func (t *testRecordingTracer) StartField(ctx *Ctx, field TracedField) Span {
+	return t.start("field " + field.ParentType + "." + field.FieldName + " " + field.Alias + " " + field.ReturnType + " " + string(field.Path))
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &testRecordingTracer{}
+	query := `query Foo {a {foo bar: bar}}`
+
+	foo := "foo"
+	res, errs := bytecodeParse(t, NewSchema(), query, TestResolveSchemaRequestWithFieldsData{A: TestResolveSchemaRequestWithFieldsDataInnerStruct{Foo: &foo, Bar: "bar"}}, M{}, ResolveOptions{
+		NoMeta: true,
+		Tracer: tracer,
+	})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"a":{"foo":"foo","bar":"bar"}}`, res)
+
+	a.Equal(t, []string{
+		"start operation  " + query,
+		"start parsing",
+		"end parsing",
+		"start validation",
+		"end validation",
+		`start field TestResolveSchemaRequestWithFieldsData.a a TestResolveSchemaRequestWithFieldsDataInnerStruct! ["a"]`,
+		`start field TestResolveSchemaRequestWithFieldsDataInnerStruct.foo foo String ["a","foo"]`,
+		`end field TestResolveSchemaRequestWithFieldsDataInnerStruct.foo foo String ["a","foo"]`,
+		`start field TestResolveSchemaRequestWithFieldsDataInnerStruct.bar bar String! ["a","bar"]`,
+		`end field TestResolveSchemaRequestWithFieldsDataInnerStruct.bar bar String! ["a","bar"]`,
+		`end field TestResolveSchemaRequestWithFieldsData.a a TestResolveSchemaRequestWithFieldsDataInnerStruct! ["a"]`,
+		"end operation  " + query,
+	}, tracer.events)
+}
+
+func TestTracerFromSchemaOptions(t *testing.T) {
+	tracer := &testRecordingTracer{}
+	s := NewSchema()
+	err := s.Parse(TestResolveSchemaRequestWithFieldsData{}, M{}, &SchemaOptions{Tracer: tracer})
+	a.NoError(t, err)
+
+	errs := s.Resolve([]byte(`{a {bar}}`), ResolveOptions{NoMeta: true})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, 10, len(tracer.events))
+
+	// ResolveOptions.Tracer overwrites the tracer of the schema
+	otherTracer := &testRecordingTracer{}
+	errs = s.Resolve([]byte(`{a {bar}}`), ResolveOptions{NoMeta: true, Tracer: otherTracer})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, 10, len(tracer.events))
+	a.Equal(t, 10, len(otherTracer.events))
+}
+
+func TestTracerWithApolloTracing(t *testing.T) {
+	recorder := &testRecordingTracer{}
+	res, errs := bytecodeParse(t, NewSchema(), `{a {bar}}`, TestResolveSchemaRequestWithFieldsData{}, M{}, ResolveOptions{
+		Tracing: true,
+		Tracer:  recorder,
+	})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, 10, len(recorder.events))
+
+	parsedRes := struct {
+		Extensions struct {
+			Tracing tracer `json:"tracing"`
+		} `json:"extensions"`
+	}{}
+	err := json.Unmarshal([]byte(res), &parsedRes)
+	a.NoError(t, err)
+	a.Equal(t, 2, len(parsedRes.Extensions.Tracing.Execution.Resolvers))
+}
+
+func TestTracerParallel(t *testing.T) {
+	tracer := &testRecordingTracer{}
+	_, errs := bytecodeParse(t, NewSchema(), `{a b {d}}`, TestParallelData{}, TestParallelMethods{}, ResolveOptions{
+		NoMeta:   true,
+		Parallel: true,
+		Tracer:   tracer,
+	})
+	a.Equal(t, 0, len(errs))
+
+	fieldEvents := []string{}
+	for _, event := range tracer.events {
+		if event[len(event)-1] == ']' {
+			fieldEvents = append(fieldEvents, event)
+		}
+	}
+	sort.Strings(fieldEvents)
+	a.Equal(t, []string{
+		`end field TestParallelData.a a String! ["a"]`,
+		`end field TestParallelData.b b TestParallelInner! ["b"]`,
+		`end field TestParallelInner.d d [Int!] ["b","d"]`,
+		`start field TestParallelData.a a String! ["a"]`,
+		`start field TestParallelData.b b TestParallelInner! ["b"]`,
+		`start field TestParallelInner.d d [Int!] ["b","d"]`,
+	}, fieldEvents)
+}
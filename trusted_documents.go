@@ -0,0 +1,134 @@
+package yarql
+
+import (
+	"errors"
+	"sort"
+)
+
+// trustedDocument is a query registered using (*Schema).RegisterTrustedDocuments
+// The query is parsed and validated once when it's registered, the variable values are validated for every request
+type trustedDocument struct {
+	query             []byte
+	res               []byte
+	fragmentLocations []int
+	locations         []int
+	targetIdx         int            // The operation that is executed if no operation name is given
+	operations        map[string]int // The res index of every named operation
+}
+
+// RegisterTrustedDocuments registers queries that can be executed by (*Schema).HandleRequest using their id
+// The documents are parsed and validated against the schema when they are registered, if one of them is invalid an error is returned and none of the documents are registered
+//
+// Clients reference a document using the documentId field of the request or the sha256Hash of a automatic persisted query
+// Set (RequestOptions).TrustedDocumentsOnly to only allow registered documents
+//
+// The schema must be parsed before this method can be used
+// RegisterTrustedDocuments is not safe to be used while requests are handled
+func (s *Schema) RegisterTrustedDocuments(documents map[string]string) error {
+	if !s.parsed {
+		return errors.New("(*yarql.Schema).RegisterTrustedDocuments() cannot be ran before (*yarql.Schema).Parse()")
+	}
+
+	ids := make([]string, 0, len(documents))
+	for id := range documents {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	ctx := s.getPooledCtx()
+	defer s.ctxPool.Put(ctx)
+
+	parsedDocuments := make(map[string]*trustedDocument, len(documents))
+	for _, id := range ids {
+		if len(id) == 0 {
+			return errors.New("trusted document id cannot be empty")
+		}
+
+		document, err := ctx.parseTrustedDocument(documents[id])
+		if err != nil {
+			return errors.New("invalid trusted document " + id + ": " + err.Error())
+		}
+		parsedDocuments[id] = document
+	}
+
+	if s.trustedDocuments == nil {
+		s.trustedDocuments = parsedDocuments
+		return nil
+	}
+	for id, document := range parsedDocuments {
+		s.trustedDocuments[id] = document
+	}
+	return nil
+}
+
+func (ctx *Ctx) parseTrustedDocument(query string) (*trustedDocument, error) {
+	ctx.query.Query = append(ctx.query.Query[:0], query...)
+	ctx.query.ParseQueryToBytecode(nil)
+	if len(ctx.query.Errors) == 0 {
+		// The variable values are validated when the document is executed
+		ctx.validateDocument()
+	}
+	if len(ctx.query.Errors) > 0 {
+		return nil, ctx.query.Errors[0]
+	}
+
+	document := &trustedDocument{
+		query:             []byte(query),
+		res:               append([]byte{}, ctx.query.Res...),
+		fragmentLocations: append([]int{}, ctx.query.FragmentLocations...),
+		locations:         append([]int{}, ctx.query.Locations...),
+		targetIdx:         ctx.query.TargetIdx,
+		operations:        map[string]int{},
+	}
+	for _, definition := range ctx.validator.definitions {
+		if !definition.isFragment && len(definition.name) > 0 {
+			document.operations[string(definition.name)] = definition.start
+		}
+	}
+	return document, nil
+}
+
+// load loads the parsed document into the parser so the query doesn't have to be parsed again
+func (d *trustedDocument) load(ctx *Ctx, operationName string) {
+	targetIdx := d.targetIdx
+	if len(operationName) > 0 {
+		idx, ok := d.operations[operationName]
+		if !ok {
+			idx = -1
+		}
+		targetIdx = idx
+	}
+	ctx.query.LoadBytecode(d.query, d.res, d.fragmentLocations, d.locations, targetIdx)
+}
+
+// requestQuery returns the query of a request using the trusted documents and automatic persisted queries
+// errMsg is set if the request is not allowed or the query cannot be found
+func (s *Schema) requestQuery(req request, options *RequestOptions) (query string, document *trustedDocument, errMsg string) {
+	if len(req.documentID) > 0 {
+		document, ok := s.trustedDocuments[req.documentID]
+		if !ok {
+			return "", nil, "unknown document id " + req.documentID
+		}
+		return string(document.query), document, ""
+	}
+
+	if len(req.persistedQueryHash) > 0 {
+		document, ok := s.trustedDocuments[req.persistedQueryHash]
+		if ok {
+			return string(document.query), document, ""
+		}
+	}
+
+	if options != nil && options.TrustedDocumentsOnly {
+		if len(req.persistedQueryHash) > 0 {
+			return "", nil, persistedQueryNotFound
+		}
+		return "", nil, "only trusted documents are allowed"
+	}
+
+	if len(req.persistedQueryHash) > 0 {
+		query, errMsg = s.persistedQuery(req.query, req.persistedQueryHash)
+		return query, nil, errMsg
+	}
+	return req.query, nil, ""
+}
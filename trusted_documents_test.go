@@ -0,0 +1,135 @@
+package yarql
+
+import (
+	"errors"
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+func handleTrustedDocumentRequest(s *Schema, body string, options *RequestOptions) (string, []error) {
+	res, errs := s.HandleRequest(
+		"POST",
+		func(key string) string { return "" },
+		func(key string) (string, error) { return "", errors.New("this should not be called") },
+		func() []byte { return []byte(body) },
+		"application/json",
+		options,
+	)
+	return string(res), errs
+}
+
+func TestTrustedDocuments(t *testing.T) {
+	foo := "bar"
+	s := NewSchema()
+	err := s.Parse(TestResolveSchemaRequestWithFieldsData{A: TestResolveSchemaRequestWithFieldsDataInnerStruct{Foo: &foo, Bar: "baz"}}, M{}, nil)
+	a.NoError(t, err)
+
+	err = s.RegisterTrustedDocuments(map[string]string{
+		"bar":  `{a {bar}}`,
+		"both": `query Foo {a {foo}} query Bar {a {...bar}} fragment bar on TestResolveSchemaRequestWithFieldsDataInnerStruct {bar}`,
+	})
+	a.NoError(t, err)
+	onlyTrusted := &RequestOptions{TrustedDocumentsOnly: true}
+
+	res, errs := handleTrustedDocumentRequest(s, `{"documentId":"bar"}`, onlyTrusted)
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"a":{"bar":"baz"}}}`, res)
+
+	res, errs = handleTrustedDocumentRequest(s, `{"documentId":"both","operationName":"Foo"}`, onlyTrusted)
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"a":{"foo":"bar"}}}`, res)
+
+	res, errs = handleTrustedDocumentRequest(s, `{"documentId":"both","operationName":"Bar"}`, onlyTrusted)
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"a":{"bar":"baz"}}}`, res)
+
+	_, errs = handleTrustedDocumentRequest(s, `{"documentId":"both","operationName":"Baz"}`, onlyTrusted)
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "no operator with name Baz found", errs[0].Error())
+
+	// Documents can also be referenced using the persisted query hash
+	res, errs = handleTrustedDocumentRequest(s, `{"extensions":{"persistedQuery":{"version":1,"sha256Hash":"bar"}}}`, onlyTrusted)
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"a":{"bar":"baz"}}}`, res)
+
+	// GET requests
+	getRes, errs := s.HandleRequest(
+		"GET",
+		func(key string) string {
+			if key == "documentId" {
+				return "bar"
+			}
+			return ""
+		},
+		func(key string) (string, error) { return "", errors.New("this should not be called") },
+		func() []byte { return nil },
+		"",
+		onlyTrusted,
+	)
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"a":{"bar":"baz"}}}`, string(getRes))
+}
+
+func TestTrustedDocumentsOnly(t *testing.T) {
+	foo := "bar"
+	s := NewSchema()
+	err := s.Parse(TestResolveSchemaRequestWithFieldsData{A: TestResolveSchemaRequestWithFieldsDataInnerStruct{Foo: &foo, Bar: "baz"}}, M{}, nil)
+	a.NoError(t, err)
+
+	res, errs := handleTrustedDocumentRequest(s, `{"query":"{a {bar}}"}`, &RequestOptions{TrustedDocumentsOnly: true})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, `{"data":{},"errors":[{"message":"only trusted documents are allowed"}],"extensions":{}}`, res)
+
+	_, errs = handleTrustedDocumentRequest(s, `{"documentId":"foo"}`, &RequestOptions{TrustedDocumentsOnly: true})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, "unknown document id foo", errs[0].Error())
+
+	// Without TrustedDocumentsOnly free-form queries are still allowed
+	res, errs = handleTrustedDocumentRequest(s, `{"query":"{a {foo}}"}`, &RequestOptions{})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"a":{"foo":"bar"}}}`, res)
+}
+
+func TestRegisterInvalidTrustedDocuments(t *testing.T) {
+	err := NewSchema().RegisterTrustedDocuments(map[string]string{"foo": `{a {bar}}`})
+	a.Error(t, err)
+
+	s := NewSchema()
+	err = s.Parse(TestResolveSchemaRequestWithFieldsData{}, M{}, nil)
+	a.NoError(t, err)
+
+	err = s.RegisterTrustedDocuments(map[string]string{"foo": `{a {`})
+	a.Error(t, err)
+
+	err = s.RegisterTrustedDocuments(map[string]string{"foo": `{a {baz}}`})
+	a.Error(t, err)
+	a.Equal(t, `invalid trusted document foo: Cannot query field "baz" on type "TestResolveSchemaRequestWithFieldsDataInnerStruct".`, err.Error())
+
+	_, errs := handleTrustedDocumentRequest(s, `{"documentId":"foo"}`, &RequestOptions{})
+	a.Equal(t, 1, len(errs))
+}
+
+type TestTrustedDocumentsVariablesData struct{}
+
+func (TestTrustedDocumentsVariablesData) ResolveEcho(args struct{ Value string }) string {
+	return args.Value
+}
+
+func TestTrustedDocumentsVariables(t *testing.T) {
+	s := NewSchema()
+	err := s.Parse(TestTrustedDocumentsVariablesData{}, M{}, nil)
+	a.NoError(t, err)
+
+	// Required variables are only validated when the document is executed
+	err = s.RegisterTrustedDocuments(map[string]string{"echo": `query ($v: String!) {echo(value: $v)}`})
+	a.NoError(t, err)
+
+	res, errs := handleTrustedDocumentRequest(s, `{"documentId":"echo","variables":{"v":"foo"}}`, &RequestOptions{TrustedDocumentsOnly: true})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"data":{"echo":"foo"}}`, res)
+
+	_, errs = handleTrustedDocumentRequest(s, `{"documentId":"echo"}`, &RequestOptions{TrustedDocumentsOnly: true})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, `Variable "$v" of required type "String!" was not provided.`, errs[0].Error())
+}
@@ -0,0 +1,110 @@
+package yarql
+
+import (
+	"errors"
+	"reflect"
+)
+
+type union struct {
+	goType   reflect.Type
+	typeName string
+	members  []reflect.Type
+}
+
+// RegisterUnion registers a go interface as graphql union type
+// A union is a list of object types that do not need to share any fields, it's mostly used to return one of multiple types from a field
+//
+// The unionValue should be a pointer to the interface type like: (*SearchResult)(nil)
+// The members should be empty structs that implement the interface, every value returned as the interface type must be one of the members
+//
+// Example:
+//   type SearchResult interface {
+//   	IsSearchResult()
+//   }
+//
+//   s.RegisterUnion("SearchResult", (*SearchResult)(nil), User{}, Post{}, Comment{})
+//
+// Also the .RegisterUnion(..) method must be called before .Parse(..)
+func (s *Schema) RegisterUnion(name string, unionValue interface{}, members ...interface{}) error {
+	if s.parsed {
+		return errors.New("(*yarql.Schema).RegisterUnion() cannot be ran after (*yarql.Schema).Parse()")
+	}
+
+	err := validGraphQlName([]byte(name))
+	if err != nil {
+		return errors.New("RegisterUnion name must be a valid graphql name, name given: " + name)
+	}
+
+	if unionValue == nil {
+		return errors.New("RegisterUnion unionValue cannot be nil")
+	}
+	unionType := reflect.TypeOf(unionValue)
+	if unionType.Kind() != reflect.Ptr || unionType.Elem().Kind() != reflect.Interface {
+		return errors.New("RegisterUnion unionValue should be a pointer to a interface")
+	}
+	unionType = unionType.Elem()
+	if unionType.Name() == "" || unionType.PkgPath() == "" {
+		return errors.New("RegisterUnion unionValue should be a pointer to a named interface, not a inline interface")
+	}
+
+	if len(members) == 0 {
+		return errors.New("RegisterUnion requires at least one member")
+	}
+
+	res := union{
+		goType:   unionType,
+		typeName: name,
+		members:  make([]reflect.Type, len(members)),
+	}
+	for idx, member := range members {
+		if member == nil {
+			return errors.New("RegisterUnion members cannot be nil")
+		}
+		memberType := reflect.TypeOf(member)
+		if memberType.Kind() != reflect.Struct {
+			return errors.New("RegisterUnion members must be structs")
+		}
+		if memberType.Name() == "" || memberType.PkgPath() == "" {
+			return errors.New("RegisterUnion members cannot be inline structs")
+		}
+		if !memberType.Implements(unionType) {
+			return errors.New(memberType.PkgPath() + "." + memberType.Name() + " does not implement " + unionType.PkgPath() + "." + unionType.Name())
+		}
+		res.members[idx] = memberType
+	}
+
+	for _, definedUnion := range s.definedUnions {
+		if definedUnion.typeName == name {
+			return errors.New("union " + name + " is already registered")
+		}
+		if definedUnion.goType == unionType {
+			return errors.New("the go type " + unionType.String() + " is already registered as union " + definedUnion.typeName)
+		}
+	}
+
+	s.definedUnions = append(s.definedUnions, res)
+	return nil
+}
+
+func (s *Schema) getUnion(t reflect.Type) *union {
+	for idx := range s.definedUnions {
+		if s.definedUnions[idx].goType == t {
+			return &s.definedUnions[idx]
+		}
+	}
+	return nil
+}
+
+// unionsWithMember returns the go types of all unions the struct type is a member of
+func (s *Schema) unionsWithMember(t reflect.Type) []reflect.Type {
+	var res []reflect.Type
+	for _, union := range s.definedUnions {
+		for _, member := range union.members {
+			if member == t {
+				res = append(res, union.goType)
+				break
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,132 @@
+package yarql
+
+import (
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+)
+
+type TestUnionSearchResult interface {
+	IsSearchResult()
+}
+
+type TestUnionUser struct {
+	Name string
+}
+
+func (TestUnionUser) IsSearchResult() {}
+
+type TestUnionPost struct {
+	Title string
+}
+
+func (TestUnionPost) IsSearchResult() {}
+
+type TestUnionComment struct {
+	Message string
+}
+
+func (TestUnionComment) IsSearchResult() {}
+
+type TestUnionNotAMember struct{}
+
+func (TestUnionNotAMember) IsSearchResult() {}
+
+type TestUnionData struct{}
+
+func (TestUnionData) ResolveSearch() []TestUnionSearchResult {
+	return []TestUnionSearchResult{
+		TestUnionUser{Name: "user"},
+		TestUnionPost{Title: "post"},
+		TestUnionComment{Message: "comment"},
+		TestUnionNotAMember{},
+		nil,
+	}
+}
+
+func TestRegisterUnionFails(t *testing.T) {
+	s := NewSchema()
+	err := s.RegisterUnion("SearchResult", (*TestUnionSearchResult)(nil), TestUnionUser{}, TestUnionPost{}, TestUnionComment{})
+	a.NoError(t, err)
+
+	err = s.RegisterUnion("SearchResult", (*InterfaceType)(nil), BarWImpl{})
+	a.Error(t, err, "union names must be unique")
+
+	err = s.RegisterUnion("OtherSearchResult", (*TestUnionSearchResult)(nil), TestUnionUser{})
+	a.Error(t, err, "interfaces can only be registered once as union")
+
+	err = s.RegisterUnion("Foo", TestUnionUser{}, TestUnionUser{})
+	a.Error(t, err, "unionValue must be a pointer to a interface")
+
+	err = s.RegisterUnion("Foo", (*InterfaceType)(nil))
+	a.Error(t, err, "unions must have members")
+
+	err = s.RegisterUnion("Foo", (*InterfaceType)(nil), TestUnionUser{})
+	a.Error(t, err, "members must implement the interface")
+
+	err = s.RegisterUnion("foo bar", (*InterfaceType)(nil), BarWImpl{})
+	a.Error(t, err, "union names must be valid graphql names")
+
+	err = s.Parse(TestResolveEmptyQueryDataQ{}, M{}, nil)
+	a.NoError(t, err)
+	err = s.RegisterUnion("Foo", (*InterfaceType)(nil), BarWImpl{})
+	a.Error(t, err, "unions cannot be registered after parsing")
+}
+
+func TestUnionResolve(t *testing.T) {
+	query := `{
+		search {
+			__typename
+			... on TestUnionUser {name}
+			...post
+			... on SearchResult {
+				... on TestUnionComment {message}
+			}
+		}
+	}
+	fragment post on TestUnionPost {title}`
+
+	s := NewSchema()
+	err := s.RegisterUnion("SearchResult", (*TestUnionSearchResult)(nil), TestUnionUser{}, TestUnionPost{}, TestUnionComment{})
+	a.NoError(t, err)
+
+	res, errs := bytecodeParse(t, s, query, TestUnionData{}, M{})
+	for _, err := range errs {
+		panic(err.Error())
+	}
+	a.Equal(t, `{"search":[{"__typename":"TestUnionUser","name":"user"},{"__typename":"TestUnionPost","title":"post"},{"__typename":"TestUnionComment","message":"comment"},null,null]}`, res)
+}
+
+func TestUnionValidation(t *testing.T) {
+	s := NewSchema()
+	err := s.RegisterUnion("SearchResult", (*TestUnionSearchResult)(nil), TestUnionUser{}, TestUnionPost{}, TestUnionComment{})
+	a.NoError(t, err)
+
+	_, errs := bytecodeParse(t, s, `{search {name}}`, TestUnionData{}, M{})
+	a.Equal(t, 1, len(errs))
+	a.Equal(t, `Cannot query field "name" on type "SearchResult".`, errs[0].Error())
+}
+
+func TestUnionIntrospection(t *testing.T) {
+	query := `{
+		union: __type(name: "SearchResult") {
+			kind
+			name
+			fields {name}
+			possibleTypes {name}
+		}
+		member: __type(name: "TestUnionUser") {
+			interfaces {name}
+		}
+	}`
+
+	s := NewSchema()
+	err := s.RegisterUnion("SearchResult", (*TestUnionSearchResult)(nil), TestUnionUser{}, TestUnionPost{}, TestUnionComment{})
+	a.NoError(t, err)
+
+	res, errs := bytecodeParse(t, s, query, TestUnionData{}, M{})
+	for _, err := range errs {
+		panic(err.Error())
+	}
+	a.Equal(t, `{"union":{"kind":"UNION","name":"SearchResult","fields":null,"possibleTypes":[{"name":"TestUnionUser"},{"name":"TestUnionPost"},{"name":"TestUnionComment"}]},"member":{"interfaces":[]}}`, res)
+}
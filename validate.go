@@ -0,0 +1,1389 @@
+package yarql
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/mjarkk/yarql/bytecode"
+	"github.com/mjarkk/yarql/helpers"
+	"github.com/valyala/fastjson"
+)
+
+// This file contains the validation phase that runs between parsing the query and executing it
+// https://spec.graphql.org/October2021/#sec-Validation
+//
+// The validation walks over the bytecode of all operations and fragments of a document and checks them against the schema
+// No resolvers are executed during validation
+
+// validator contains the state of the validation phase
+// It lives inside the ctx so the allocated slices can be re-used by the next request
+type validator struct {
+	definitions    []validationDefinition
+	spreads        []validationSpread
+	variableUsages []validationVariableUsage
+	variables      []validationVariable // The variable definitions of all operations
+	names          [][]byte             // Used to detect duplicated names, used as a stack
+	stack          []int                // Used to walk over the fragments used by a operation
+	current        int                  // The index of the definition that is being validated
+}
+
+// validationDefinition is a operation or fragment definition
+type validationDefinition struct {
+	start          int // Res index of the definition
+	isFragment     bool
+	kind           bytecode.OperatorKind
+	name           []byte
+	typeObj        *obj // The root type of a operation or the type condition of a fragment, nil if unknown
+	selectionStart int
+
+	spreadsStart        int
+	spreadsEnd          int
+	variableUsagesStart int
+	variableUsagesEnd   int
+	variablesStart      int // Only set on operations
+	variablesEnd        int // Only set on operations
+
+	visitedBy  int   // Index + 1 of the last operation that used this fragment, 0 if the fragment is never used
+	cycleState uint8 // Used to detect fragment cycles, 0 = unvisited, 1 = visiting, 2 = visited
+}
+
+// validationSpread is a named fragment spread
+type validationSpread struct {
+	start    int // Res index of the spread
+	fragment int // Index of the spread fragment in the definitions
+}
+
+// validationVariableUsage is a place where a variable is used
+type validationVariableUsage struct {
+	start       int // Res index of the variable value
+	name        []byte
+	location    input // The input type of the location the variable is used in
+	hasLocation bool  // The location type is known
+}
+
+// validationVariable is a variable defined by a operation
+type validationVariable struct {
+	start             int // Res index of the variable definition
+	name              []byte
+	typeStart         int // Res index of the graphql type of the variable
+	hasDefault        bool
+	hasNonNullDefault bool
+	used              bool
+}
+
+// validate validates the parsed query and the variable values of the operation that is executed, errors are added to ctx.query.Errors
+func (ctx *Ctx) validate() {
+	ctx.validateDocument()
+
+	for i, definition := range ctx.validator.definitions {
+		if !definition.isFragment && definition.start == ctx.query.TargetIdx {
+			ctx.validateVariableValues(i)
+		}
+	}
+}
+
+// validateDocument validates the parsed query without looking at the variable values, errors are added to ctx.query.Errors
+func (ctx *Ctx) validateDocument() {
+	v := &ctx.validator
+	*v = validator{
+		definitions:    v.definitions[:0],
+		spreads:        v.spreads[:0],
+		variableUsages: v.variableUsages[:0],
+		variables:      v.variables[:0],
+		names:          v.names[:0],
+		stack:          v.stack[:0],
+	}
+	res := ctx.query.Res
+
+	// Collect all fragments first so we can check spreads to fragments that are defined after the spread
+	for _, location := range ctx.query.FragmentLocations {
+		start := location - 1
+		name, nameEnd := ctx.readNameAt(location + 1)
+		typeName, selectionStart := ctx.readNameAt(nameEnd + 1)
+
+		for _, definition := range v.definitions {
+			if bytes.Equal(definition.name, name) {
+				ctx.validationErrf(start, `There can be only one fragment named "%s".`, name)
+				break
+			}
+		}
+
+		v.definitions = append(v.definitions, validationDefinition{
+			start:          start,
+			isFragment:     true,
+			name:           name,
+			typeObj:        ctx.validateTypeCondition(start, typeName, name),
+			selectionStart: selectionStart,
+		})
+	}
+	fragmentsCount := len(v.definitions)
+
+	fragmentIdx := 0
+	for pos := 0; pos < len(res); {
+		if res[pos+1] == bytecode.ActionFragment {
+			pos = ctx.validateFragmentDefinition(fragmentIdx)
+			fragmentIdx++
+		} else {
+			pos = ctx.validateOperation(pos)
+		}
+	}
+
+	operationsCount := len(v.definitions) - fragmentsCount
+	for i := fragmentsCount; i < len(v.definitions); i++ {
+		operation := v.definitions[i]
+		if len(operation.name) == 0 && operationsCount > 1 {
+			ctx.validationErr(operation.start, "This anonymous operation must be the only defined operation.")
+		}
+	}
+
+	for i := 0; i < fragmentsCount; i++ {
+		if v.definitions[i].cycleState == 0 {
+			ctx.validateFragmentCycles(i)
+		}
+	}
+
+	for i := fragmentsCount; i < len(v.definitions); i++ {
+		ctx.validateOperationVariables(i)
+	}
+
+	for i := 0; i < fragmentsCount; i++ {
+		fragment := v.definitions[i]
+		if fragment.visitedBy == 0 {
+			ctx.validationErrf(fragment.start, `Fragment "%s" is never used.`, fragment.name)
+		}
+	}
+}
+
+// validationErr adds a validation error with the location of the instruction that starts at resIdx
+func (ctx *Ctx) validationErr(resIdx int, msg string) {
+	err := errors.New(msg)
+	line, column, ok := ctx.query.LocationOf(resIdx)
+	if !ok {
+		ctx.query.Errors = append(ctx.query.Errors, err)
+		return
+	}
+	ctx.query.Errors = append(ctx.query.Errors, bytecode.ErrorWLocation{
+		Err:    err,
+		Line:   line,
+		Column: column,
+	})
+}
+
+func (ctx *Ctx) validationErrf(resIdx int, msg string, args ...interface{}) {
+	ctx.validationErr(resIdx, fmt.Sprintf(msg, args...))
+}
+
+// readNameAt reads a name starting at start that ends with a 0 byte
+// returns the name and the index of the 0 byte, this is the start of the next instruction
+func (ctx *Ctx) readNameAt(start int) (name []byte, end int) {
+	end = start
+	for ctx.query.Res[end] != 0 {
+		end++
+	}
+	return ctx.query.Res[start:end], end
+}
+
+func (ctx *Ctx) validateOperation(start int) (end int) {
+	v := &ctx.validator
+	res := ctx.query.Res
+
+	kind := res[start+2]
+	hasArguments := res[start+3] == 't'
+	directivesCount := res[start+4]
+	name, pos := ctx.readNameAt(start + 5)
+
+	if len(name) > 0 {
+		for _, definition := range v.definitions {
+			if !definition.isFragment && bytes.Equal(definition.name, name) {
+				ctx.validationErrf(start, `There can be only one operation named "%s".`, name)
+				break
+			}
+		}
+	}
+
+	var typeObj *obj
+	var location DirectiveLocation
+	var locationName string
+	switch kind {
+	case bytecode.OperatorMutation:
+		typeObj = ctx.schema.rootMethod
+		location = DirectiveLocationMutation
+		locationName = "MUTATION"
+	case bytecode.OperatorSubscription:
+		typeObj = ctx.schema.rootSubscription
+		location = DirectiveLocationSubscription
+		locationName = "SUBSCRIPTION"
+		if typeObj == nil {
+			ctx.validationErr(start, "Schema is not configured to execute subscription operation.")
+		}
+	default:
+		typeObj = ctx.schema.rootQuery
+		location = DirectiveLocationQuery
+		locationName = "QUERY"
+	}
+
+	v.current = len(v.definitions)
+	v.definitions = append(v.definitions, validationDefinition{
+		start:               start,
+		kind:                kind,
+		name:                name,
+		typeObj:             typeObj,
+		spreadsStart:        len(v.spreads),
+		variableUsagesStart: len(v.variableUsages),
+		variablesStart:      len(v.variables),
+	})
+
+	if hasArguments {
+		// The name is followed by the length of the arguments encoded as uint32 and the arguments themself
+		argumentsEnd := pos + 5 + int(ctx.readUint32(pos+1))
+		ctx.validateVariableDefinitions(pos + 7)
+		pos = argumentsEnd
+	}
+
+	pos = ctx.validateDirectives(pos, directivesCount, ctx.schema.definedDirectives[location], locationName)
+	selectionStart := pos
+	end = ctx.validateSelectionSet(pos, typeObj)
+
+	definition := &v.definitions[v.current]
+	definition.selectionStart = selectionStart
+	definition.spreadsEnd = len(v.spreads)
+	definition.variableUsagesEnd = len(v.variableUsages)
+	definition.variablesEnd = len(v.variables)
+
+	if kind == bytecode.OperatorSubscription && typeObj != nil {
+		namesStart := len(v.names)
+		ctx.collectResponseNames(selectionStart, 0)
+		if len(v.names)-namesStart > 1 {
+			if len(name) > 0 {
+				ctx.validationErrf(start, `Subscription "%s" must select only one top level field.`, name)
+			} else {
+				ctx.validationErr(start, "Anonymous Subscription must select only one top level field.")
+			}
+		}
+		v.names = v.names[:namesStart]
+	}
+
+	return end
+}
+
+// validateVariableDefinitions validates the variables defined by a operation
+// pos is the start of the first variable definition
+func (ctx *Ctx) validateVariableDefinitions(pos int) {
+	v := &ctx.validator
+	res := ctx.query.Res
+	operation := v.definitions[v.current]
+
+	for res[pos+1] == bytecode.ActionOperatorArg {
+		start := pos
+		end := pos + 1 + int(ctx.readUint32(pos+2))
+		name, nameEnd := ctx.readNameAt(pos + 6)
+		typeStart := nameEnd + 1
+		_, typeEnd := ctx.readNameAt(typeStart)
+		hasDefault := res[typeEnd+1] == 't'
+		pos = end
+
+		for _, variable := range v.variables[operation.variablesStart:] {
+			if bytes.Equal(variable.name, name) {
+				ctx.validationErrf(start, `There can be only one variable named "$%s".`, name)
+				break
+			}
+		}
+
+		namedTypeStart := typeStart
+		for res[namedTypeStart] == 'l' || res[namedTypeStart] == 'L' {
+			namedTypeStart++
+		}
+		typeName := res[namedTypeStart+1 : typeEnd]
+		namedType, ok := ctx.schema.inputTypeByName(typeName)
+		if !ok {
+			if ctx.schema.isOutputTypeName(typeName) {
+				ctx.validationErrf(start, `Variable "$%s" cannot be non-input type "%s".`, name, ctx.variableTypeString(typeStart))
+			} else {
+				ctx.validationErrf(start, `Unknown type "%s".`, typeName)
+			}
+		}
+
+		hasNonNullDefault := false
+		if hasDefault {
+			defaultStart := typeEnd + 2
+			hasNonNullDefault = res[defaultStart+2] != bytecode.ValueNull
+			if ok {
+				ctx.validateDefaultValue(defaultStart, typeStart, &namedType)
+			}
+		}
+
+		v.variables = append(v.variables, validationVariable{
+			start:             start,
+			name:              name,
+			typeStart:         typeStart,
+			hasDefault:        hasDefault,
+			hasNonNullDefault: hasNonNullDefault,
+		})
+	}
+}
+
+// validateDefaultValue validates the default value of a variable starting at pos
+// typeStart is the start of the variable's graphql type, namedType is the input matching the graphql type's name
+func (ctx *Ctx) validateDefaultValue(pos int, typeStart int, namedType *input) {
+	res := ctx.query.Res
+	typeKind := res[typeStart]
+	valueKind := res[pos+2]
+
+	switch valueKind {
+	case bytecode.ValueVariable:
+		ctx.validationErrf(pos, `Unexpected variable "%s" in constant value.`, ctx.appendValue(pos, nil))
+		return
+	case bytecode.ValueNull:
+		if typeKind == 'N' || typeKind == 'L' {
+			ctx.validationErrf(pos, `Expected value of type "%s", found null.`, ctx.variableTypeString(typeStart))
+		}
+		return
+	}
+
+	if typeKind == 'l' || typeKind == 'L' {
+		if valueKind != bytecode.ValueList {
+			ctx.validationErrf(pos, `Expected value of type "%s", found %s.`, ctx.variableTypeString(typeStart), ctx.appendValue(pos, nil))
+			return
+		}
+		for item := pos + 7; res[item+1] != bytecode.ActionEnd; {
+			end := item + 7 + int(ctx.readUint32(item+3))
+			ctx.validateDefaultValue(item, typeStart+1, namedType)
+			item = end
+		}
+		return
+	}
+
+	// Default values cannot contain variables, validateValue collects them as usages
+	v := &ctx.validator
+	usagesStart := len(v.variableUsages)
+	ctx.validateValue(pos, namedType)
+	for _, usage := range v.variableUsages[usagesStart:] {
+		ctx.validationErrf(usage.start, `Unexpected variable "$%s" in constant value.`, usage.name)
+	}
+	v.variableUsages = v.variableUsages[:usagesStart]
+}
+
+func (ctx *Ctx) validateFragmentDefinition(idx int) (end int) {
+	v := &ctx.validator
+	v.current = idx
+
+	definition := &v.definitions[idx]
+	definition.spreadsStart = len(v.spreads)
+	definition.variableUsagesStart = len(v.variableUsages)
+
+	end = ctx.validateSelectionSet(definition.selectionStart, definition.typeObj)
+
+	definition = &v.definitions[idx]
+	definition.spreadsEnd = len(v.spreads)
+	definition.variableUsagesEnd = len(v.variableUsages)
+	return end
+}
+
+// validateTypeCondition returns the type targeted by a fragment's type condition
+// Returns nil if the type doesn't exist or isn't a object or interface
+// fragmentName is empty for inline fragments
+func (ctx *Ctx) validateTypeCondition(start int, typeName []byte, fragmentName []byte) *obj {
+	typeObj, ok := ctx.schema.types[b2s(typeName)]
+	if ok {
+		return typeObj
+	}
+	typeObj, ok = ctx.schema.interfaces[b2s(typeName)]
+	if ok {
+		return typeObj
+	}
+
+	_, isInput := ctx.schema.inputTypeByName(typeName)
+	if !isInput {
+		ctx.validationErrf(start, `Unknown type "%s".`, typeName)
+	} else if len(fragmentName) > 0 {
+		ctx.validationErrf(start, `Fragment "%s" cannot condition on non composite type "%s".`, fragmentName, typeName)
+	} else {
+		ctx.validationErrf(start, `Fragment cannot condition on non composite type "%s".`, typeName)
+	}
+	return nil
+}
+
+// validateSelectionSet validates the fields and fragment spreads of the selection set starting at pos
+// parentType is nil if the type of the selection set is unknown, in that case only the used fragments and variables are collected
+// Returns the start of the instruction after the selection set
+func (ctx *Ctx) validateSelectionSet(pos int, parentType *obj) (end int) {
+	res := ctx.query.Res
+	for {
+		switch res[pos+1] {
+		case bytecode.ActionField:
+			pos = ctx.validateField(pos, parentType)
+		case bytecode.ActionSpread:
+			pos = ctx.validateSpread(pos, parentType)
+		default:
+			// bytecode.ActionEnd
+			return pos + 2
+		}
+	}
+}
+
+func (ctx *Ctx) validateField(start int, parentType *obj) (end int) {
+	v := &ctx.validator
+	res := ctx.query.Res
+
+	directivesCount := res[start+2]
+	end = start + 11 + int(ctx.readUint32(start+3))
+	nameKey := ctx.readUint32(start + 7)
+
+	aliasLen := int(res[start+11])
+	nameStart := start + 12
+	nameEnd := nameStart + aliasLen
+	nameLen := int(res[nameEnd])
+	pos := nameEnd + 1 + nameLen
+	if nameLen != 0 {
+		nameStart = nameEnd + 1
+		nameEnd = pos
+	}
+	name := res[nameStart:nameEnd]
+
+	var field *obj
+	isTypename := false
+	if parentType != nil {
+		field = parentType.objContents[nameKey]
+		if field == nil {
+			isTypename = b2s(name) == "__typename"
+			if !isTypename {
+				ctx.validationErrf(start, `Cannot query field "%s" on type "%s".`, name, parentType.typeName)
+			}
+		}
+	}
+
+	pos = ctx.validateDirectives(pos, directivesCount, ctx.schema.definedDirectives[DirectiveLocationField], "FIELD")
+
+	if res[pos+1] == bytecode.ActionValue {
+		checkArguments := field != nil || isTypename
+		var inFields map[string]referToInput
+		if field != nil && field.valueType == valueTypeMethod {
+			inFields = field.method.inFields
+		}
+
+		namesStart := len(v.names)
+		owner := ""
+		if checkArguments {
+			owner = parentType.typeName + "." + b2s(name)
+		}
+		pos = ctx.validateArguments(pos, inFields, checkArguments, false, owner)
+		if inFields != nil {
+			ctx.validateRequiredArguments(start, inFields, v.names[namesStart:], false, owner)
+		}
+		v.names = v.names[:namesStart]
+	} else if field != nil && field.valueType == valueTypeMethod {
+		ctx.validateRequiredArguments(start, field.method.inFields, nil, false, parentType.typeName+"."+b2s(name))
+	}
+
+	hasSelection := res[pos+1] != bytecode.ActionEnd
+	if field == nil {
+		if isTypename && hasSelection {
+			ctx.validationErr(start, `Field "__typename" must not have a selection since type "String!" has no subfields.`)
+		}
+		if hasSelection {
+			ctx.validateSelectionSet(pos, nil)
+		}
+		return end
+	}
+
+	fieldType := ctx.schema.namedOutputType(field)
+	isComposite := fieldType.valueType == valueTypeObj || fieldType.valueType == valueTypeInterface
+	if isComposite {
+		if hasSelection {
+			ctx.validateSelectionSet(pos, fieldType)
+		} else {
+			typeName := bytes.NewBuffer(nil)
+			ctx.schema.objToQlTypeName(field, typeName)
+			ctx.validationErrf(start, `Field "%s" of type "%s" must have a selection of subfields.`, name, typeName.String())
+		}
+	} else if hasSelection {
+		typeName := bytes.NewBuffer(nil)
+		ctx.schema.objToQlTypeName(field, typeName)
+		ctx.validationErrf(start, `Field "%s" must not have a selection since type "%s" has no subfields.`, name, typeName.String())
+		ctx.validateSelectionSet(pos, nil)
+	}
+
+	return end
+}
+
+func (ctx *Ctx) validateSpread(start int, parentType *obj) (end int) {
+	v := &ctx.validator
+	res := ctx.query.Res
+
+	isInline := res[start+2] == 't'
+	directivesCount := res[start+3]
+	end = start + 8 + int(ctx.readUint32(start+4))
+	name, pos := ctx.readNameAt(start + 8)
+
+	if isInline {
+		pos = ctx.validateDirectives(pos, directivesCount, ctx.schema.definedDirectives[DirectiveLocationFragmentInline], "INLINE_FRAGMENT")
+
+		typeObj := ctx.validateTypeCondition(start, name, nil)
+		if typeObj != nil && parentType != nil && !typesOverlap(parentType, typeObj) {
+			ctx.validationErrf(start, `Fragment cannot be spread here as objects of type "%s" can never be of type "%s".`, parentType.typeName, typeObj.typeName)
+		}
+		ctx.validateSelectionSet(pos, typeObj)
+		return end
+	}
+
+	ctx.validateDirectives(pos, directivesCount, ctx.schema.definedDirectives[DirectiveLocationFragment], "FRAGMENT_SPREAD")
+
+	fragment := -1
+	for i, definition := range v.definitions {
+		if !definition.isFragment {
+			break
+		}
+		if bytes.Equal(definition.name, name) {
+			fragment = i
+			break
+		}
+	}
+	if fragment == -1 {
+		ctx.validationErrf(start, `Unknown fragment "%s".`, name)
+		return end
+	}
+
+	v.spreads = append(v.spreads, validationSpread{
+		start:    start,
+		fragment: fragment,
+	})
+
+	typeObj := v.definitions[fragment].typeObj
+	if typeObj != nil && parentType != nil && !typesOverlap(parentType, typeObj) {
+		ctx.validationErrf(start, `Fragment "%s" cannot be spread here as objects of type "%s" can never be of type "%s".`, name, parentType.typeName, typeObj.typeName)
+	}
+
+	return end
+}
+
+// validateDirectives validates the directives starting at pos
+// allowed contains the directives that can be used on this location, locationName is the graphql name of the location
+// Returns the start of the instruction after the directives
+func (ctx *Ctx) validateDirectives(pos int, count uint8, allowed []*Directive, locationName string) (end int) {
+	v := &ctx.validator
+	res := ctx.query.Res
+
+	namesStart := len(v.names)
+	for i := uint8(0); i < count; i++ {
+		start := pos
+		hasArguments := res[pos+2] == 't'
+		var name []byte
+		name, pos = ctx.readNameAt(pos + 3)
+
+		var directive *Directive
+		for _, allowedDirective := range allowed {
+			if allowedDirective.Name == b2s(name) {
+				directive = allowedDirective
+				break
+			}
+		}
+		if directive == nil {
+			if ctx.schema.directiveExists(b2s(name)) {
+				ctx.validationErrf(start, `Directive "@%s" may not be used on %s.`, name, locationName)
+			} else {
+				ctx.validationErrf(start, `Unknown directive "@%s".`, name)
+			}
+		}
+
+		for _, otherName := range v.names[namesStart:] {
+			if bytes.Equal(otherName, name) {
+				ctx.validationErrf(start, `The directive "@%s" can only be used once at this location.`, name)
+				break
+			}
+		}
+		v.names = append(v.names, name)
+
+		argumentsStart := len(v.names)
+		var owner string
+		if directive != nil {
+			owner = "@" + directive.Name
+		}
+		if hasArguments {
+			var inFields map[string]referToInput
+			if directive != nil {
+				inFields = directive.parsedMethod.inFields
+			}
+			pos = ctx.validateArguments(pos, inFields, directive != nil, true, owner)
+		}
+		if directive != nil {
+			ctx.validateRequiredArguments(start, directive.parsedMethod.inFields, v.names[argumentsStart:], true, owner)
+		}
+		v.names = v.names[:argumentsStart]
+	}
+	v.names = v.names[:namesStart]
+
+	return pos
+}
+
+// validateArguments validates the arguments starting at pos against the inputs of a field or directive
+// The argument names are appended to ctx.validator.names so the caller can check if all required arguments are provided
+// If check is false the arguments are only walked to collect the used variables
+// Returns the start of the instruction after the arguments
+func (ctx *Ctx) validateArguments(pos int, inFields map[string]referToInput, check bool, isDirective bool, owner string) (end int) {
+	v := &ctx.validator
+	res := ctx.query.Res
+
+	end = pos + 7 + int(ctx.readUint32(pos+3))
+	namesStart := len(v.names)
+	for pos += 7; res[pos+1] == bytecode.ActionObjectValueField; {
+		start := pos
+		name, valueStart := ctx.readNameAt(pos + 2)
+
+		if !check {
+			pos = ctx.validateValue(valueStart, nil)
+			continue
+		}
+
+		for _, otherName := range v.names[namesStart:] {
+			if bytes.Equal(otherName, name) {
+				ctx.validationErrf(start, `There can be only one argument named "%s".`, name)
+				break
+			}
+		}
+		v.names = append(v.names, name)
+
+		inField, ok := inFields[b2s(name)]
+		if !ok {
+			if isDirective {
+				ctx.validationErrf(start, `Unknown argument "%s" on directive "%s".`, name, owner)
+			} else {
+				ctx.validationErrf(start, `Unknown argument "%s" on field "%s".`, name, owner)
+			}
+			pos = ctx.validateValue(valueStart, nil)
+			continue
+		}
+		pos = ctx.validateValue(valueStart, &inField.input)
+	}
+
+	return end
+}
+
+// validateRequiredArguments checks if all required arguments of a field or directive are provided
+func (ctx *Ctx) validateRequiredArguments(start int, inFields map[string]referToInput, provided [][]byte, isDirective bool, owner string) {
+	var missing []string
+	for name, inField := range inFields {
+		if !inputIsRequired(&inField.input) || containsName(provided, name) {
+			continue
+		}
+		missing = append(missing, name)
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	sort.Strings(missing)
+	for _, name := range missing {
+		inField := inFields[name]
+		typeName := bytes.NewBuffer(nil)
+		ctx.schema.inputToQlTypeName(&inField.input, typeName)
+		if isDirective {
+			ctx.validationErrf(start, `Directive "%s" argument "%s" of type "%s" is required, but it was not provided.`, owner, name, typeName.String())
+		} else {
+			ctx.validationErrf(start, `Field "%s" argument "%s" of type "%s" is required, but it was not provided.`, owner, name, typeName.String())
+		}
+	}
+}
+
+func containsName(names [][]byte, name string) bool {
+	for _, entry := range names {
+		if b2s(entry) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateValue validates the value starting at pos against the expected input type
+// expected is nil if the input type is unknown, in that case only the used variables are collected
+// Returns the start of the instruction after the value
+func (ctx *Ctx) validateValue(pos int, expected *input) (end int) {
+	v := &ctx.validator
+	res := ctx.query.Res
+
+	kind := res[pos+2]
+	end = pos + 7 + int(ctx.readUint32(pos+3))
+
+	if kind == bytecode.ValueVariable {
+		usage := validationVariableUsage{
+			start: pos,
+			name:  res[pos+7 : end],
+		}
+		if expected != nil {
+			usage.location = *expected
+			usage.hasLocation = true
+		}
+		v.variableUsages = append(v.variableUsages, usage)
+		return end
+	}
+
+	if expected == nil {
+		// Walk over the list items and object fields to collect the used variables
+		switch kind {
+		case bytecode.ValueList:
+			for item := pos + 7; res[item+1] != bytecode.ActionEnd; {
+				item = ctx.validateValue(item, nil)
+			}
+		case bytecode.ValueObject:
+			for field := pos + 7; res[field+1] == bytecode.ActionObjectValueField; {
+				_, valueStart := ctx.readNameAt(field + 2)
+				field = ctx.validateValue(valueStart, nil)
+			}
+		}
+		return end
+	}
+
+	if kind == bytecode.ValueNull {
+		if inputIsNonNull(expected) {
+			ctx.validateValueErr(pos, expected)
+		}
+		return end
+	}
+
+	in := expected
+	if in.kind == reflect.Ptr && !in.isFile && !in.isScalar {
+		in = in.elem
+	}
+
+	valid := false
+	switch {
+	case in.isScalar || in.isJSON:
+		// Custom scalars accept all kinds of values, the scalar's parse function decides if the value is valid
+		ctx.validateValue(pos, nil)
+		return end
+	case in.kind == reflect.Array || in.kind == reflect.Slice:
+		if kind != bytecode.ValueList {
+			// A single value is coerced into a list with one item
+			return ctx.validateValue(pos, in.elem)
+		}
+		for item := pos + 7; res[item+1] != bytecode.ActionEnd; {
+			item = ctx.validateValue(item, in.elem)
+		}
+		return end
+	case in.kind == reflect.Struct:
+		if kind != bytecode.ValueObject {
+			break
+		}
+		ctx.validateInputObject(pos, in)
+		return end
+	case in.isEnum:
+		if kind != bytecode.ValueEnum {
+			break
+		}
+		enum := ctx.schema.definedEnums[in.enumTypeIndex]
+		value := res[pos+7 : end]
+		for _, entry := range enum.entries {
+			if bytes.Equal(entry.keyBytes, value) {
+				return end
+			}
+		}
+		ctx.validationErrf(pos, `Value "%s" does not exist in "%s" enum.`, value, enum.typeName)
+		return end
+	case in.isID:
+		valid = kind == bytecode.ValueString || kind == bytecode.ValueInt
+	case in.isTime || in.isFile:
+		valid = kind == bytecode.ValueString
+	default:
+		switch in.kind {
+		case reflect.Bool:
+			valid = kind == bytecode.ValueBoolean
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			valid = kind == bytecode.ValueInt
+			if valid {
+				value := res[pos+7 : end]
+				if _, err := strconv.ParseInt(b2s(value), 10, 32); err != nil {
+					ctx.validationErrf(pos, `Int cannot represent non 32-bit signed integer value: %s`, value)
+					return end
+				}
+			}
+		case reflect.Float32, reflect.Float64:
+			valid = kind == bytecode.ValueInt || kind == bytecode.ValueFloat
+		case reflect.String:
+			valid = kind == bytecode.ValueString
+		}
+	}
+
+	if !valid {
+		ctx.validateValueErr(pos, expected)
+		// Still collect the variables inside this value
+		ctx.validateValue(pos, nil)
+	}
+	return end
+}
+
+func (ctx *Ctx) validateValueErr(pos int, expected *input) {
+	typeName := bytes.NewBuffer(nil)
+	ctx.schema.inputToQlTypeName(expected, typeName)
+	ctx.validationErrf(pos, `Expected value of type "%s", found %s.`, typeName.String(), ctx.appendValue(pos, nil))
+}
+
+// validateInputObject validates the input object value starting at pos
+func (ctx *Ctx) validateInputObject(pos int, in *input) {
+	v := &ctx.validator
+	res := ctx.query.Res
+
+	structure := in
+	if in.isStructPointers {
+		structure = ctx.schema.inTypes[in.structName]
+	}
+
+	namesStart := len(v.names)
+	for field := pos + 7; res[field+1] == bytecode.ActionObjectValueField; {
+		start := field
+		name, valueStart := ctx.readNameAt(field + 2)
+
+		for _, otherName := range v.names[namesStart:] {
+			if bytes.Equal(otherName, name) {
+				ctx.validationErrf(start, `There can be only one input field named "%s".`, name)
+				break
+			}
+		}
+		v.names = append(v.names, name)
+
+		fieldInput, ok := structure.structContent[b2s(name)]
+		if !ok {
+			ctx.validationErrf(start, `Field "%s" is not defined by type "%s".`, name, structure.structName)
+			field = ctx.validateValue(valueStart, nil)
+			continue
+		}
+		field = ctx.validateValue(valueStart, &fieldInput)
+	}
+
+	var missing []string
+	for name, fieldInput := range structure.structContent {
+		if inputIsRequired(&fieldInput) && !containsName(v.names[namesStart:], name) {
+			missing = append(missing, name)
+		}
+	}
+	v.names = v.names[:namesStart]
+
+	sort.Strings(missing)
+	for _, name := range missing {
+		fieldInput := structure.structContent[name]
+		typeName := bytes.NewBuffer(nil)
+		ctx.schema.inputToQlTypeName(&fieldInput, typeName)
+		ctx.validationErrf(pos, `Field "%s.%s" of required type "%s" was not provided.`, structure.structName, name, typeName.String())
+	}
+}
+
+// validateFragmentCycles reports fragments that spread themselves directly or via other fragments
+func (ctx *Ctx) validateFragmentCycles(fragment int) {
+	v := &ctx.validator
+	definition := &v.definitions[fragment]
+	definition.cycleState = 1
+
+	for _, spread := range v.spreads[definition.spreadsStart:definition.spreadsEnd] {
+		switch v.definitions[spread.fragment].cycleState {
+		case 0:
+			ctx.validateFragmentCycles(spread.fragment)
+		case 1:
+			ctx.validationErrf(spread.start, `Cannot spread fragment "%s" within itself.`, v.definitions[spread.fragment].name)
+		}
+	}
+
+	v.definitions[fragment].cycleState = 2
+}
+
+// validateOperationVariables checks the variables used by a operation and all fragments it uses
+func (ctx *Ctx) validateOperationVariables(operation int) {
+	v := &ctx.validator
+
+	v.stack = append(v.stack[:0], operation)
+	for len(v.stack) > 0 {
+		definitionIdx := v.stack[len(v.stack)-1]
+		v.stack = v.stack[:len(v.stack)-1]
+		definition := v.definitions[definitionIdx]
+
+		for _, usage := range v.variableUsages[definition.variableUsagesStart:definition.variableUsagesEnd] {
+			ctx.validateVariableUsage(operation, usage)
+		}
+
+		for _, spread := range v.spreads[definition.spreadsStart:definition.spreadsEnd] {
+			fragment := &v.definitions[spread.fragment]
+			if fragment.visitedBy == operation+1 {
+				continue
+			}
+			fragment.visitedBy = operation + 1
+			v.stack = append(v.stack, spread.fragment)
+		}
+	}
+
+	definition := v.definitions[operation]
+	for _, variable := range v.variables[definition.variablesStart:definition.variablesEnd] {
+		if variable.used {
+			continue
+		}
+		if len(definition.name) > 0 {
+			ctx.validationErrf(variable.start, `Variable "$%s" is never used in operation "%s".`, variable.name, definition.name)
+		} else {
+			ctx.validationErrf(variable.start, `Variable "$%s" is never used.`, variable.name)
+		}
+	}
+}
+
+func (ctx *Ctx) validateVariableUsage(operation int, usage validationVariableUsage) {
+	v := &ctx.validator
+	definition := v.definitions[operation]
+
+	var variable *validationVariable
+	for i := definition.variablesStart; i < definition.variablesEnd; i++ {
+		if bytes.Equal(v.variables[i].name, usage.name) {
+			variable = &v.variables[i]
+			break
+		}
+	}
+	if variable == nil {
+		if len(definition.name) > 0 {
+			ctx.validationErrf(usage.start, `Variable "$%s" is not defined by operation "%s".`, usage.name, definition.name)
+		} else {
+			ctx.validationErrf(usage.start, `Variable "$%s" is not defined.`, usage.name)
+		}
+		return
+	}
+	variable.used = true
+
+	if usage.hasLocation && !ctx.variableTypeAllowed(variable, &usage.location) {
+		typeName := bytes.NewBuffer(nil)
+		ctx.schema.inputToQlTypeName(&usage.location, typeName)
+		ctx.validationErrf(usage.start, `Variable "$%s" of type "%s" used in position expecting type "%s".`, usage.name, ctx.variableTypeString(variable.typeStart), typeName.String())
+	}
+}
+
+// variableTypeAllowed returns true if the variable can be used on a location of the input type
+// https://spec.graphql.org/October2021/#sec-All-Variable-Usages-Are-Allowed
+func (ctx *Ctx) variableTypeAllowed(variable *validationVariable, location *input) bool {
+	// A nullable variable is allowed in a non null location with a default value
+	return ctx.variableTypeFits(variable.typeStart, location, variable.hasNonNullDefault || location.defaultValue != nil)
+}
+
+// variableTypeFits returns true if the graphql type starting at typeStart is compatible with the input type
+// If nonNullByDefault is true the variable's type is treated as non null as it has a default value
+func (ctx *Ctx) variableTypeFits(typeStart int, location *input, nonNullByDefault bool) bool {
+	res := ctx.query.Res
+
+	typeKind := res[typeStart]
+	isNonNull := typeKind == 'N' || typeKind == 'L' || nonNullByDefault
+	if inputIsNonNull(location) && !isNonNull {
+		return false
+	}
+
+	in := location
+	if in.kind == reflect.Ptr && !in.isFile && !in.isScalar {
+		in = in.elem
+	}
+
+	if !in.isScalar && (in.kind == reflect.Array || in.kind == reflect.Slice) {
+		if typeKind != 'l' && typeKind != 'L' {
+			return false
+		}
+		return ctx.variableTypeFits(typeStart+1, in.elem, false)
+	}
+
+	if typeKind != 'n' && typeKind != 'N' {
+		return false
+	}
+	typeName, _ := ctx.readNameAt(typeStart + 1)
+	return b2s(typeName) == ctx.schema.inputNamedTypeName(in)
+}
+
+// validateVariableValues checks if values for all required variables of the operation are provided and Int values fit in 32 bits
+func (ctx *Ctx) validateVariableValues(operation int) {
+	v := &ctx.validator
+	res := ctx.query.Res
+	definition := v.definitions[operation]
+
+	for _, variable := range v.variables[definition.variablesStart:definition.variablesEnd] {
+		typeKind := res[variable.typeStart]
+		required := !variable.hasDefault && (typeKind == 'N' || typeKind == 'L')
+
+		hasVariables, criticalErr := ctx.parseVariables()
+		if criticalErr {
+			return
+		}
+
+		var value *fastjson.Value
+		if hasVariables {
+			value = ctx.variables.Get(b2s(variable.name))
+		}
+		if value == nil {
+			if required {
+				ctx.validationErrf(variable.start, `Variable "$%s" of required type "%s" was not provided.`, variable.name, ctx.variableTypeString(variable.typeStart))
+			}
+		} else if value.Type() == fastjson.TypeNull {
+			if required {
+				ctx.validationErrf(variable.start, `Variable "$%s" of non-null type "%s" must not be null.`, variable.name, ctx.variableTypeString(variable.typeStart))
+			}
+		} else if invalid := ctx.variableIntOutOfRange(value, variable.typeStart); invalid != nil {
+			ctx.validationErrf(variable.start, `Variable "$%s" got invalid value %s; Int cannot represent non 32-bit signed integer value: %s`, variable.name, value, invalid)
+		}
+	}
+}
+
+// variableIntOutOfRange returns the first Int within the variable value that doesn't fit in 32 bits, nil if there is none
+// typeStart is the start of the variable's graphql type
+func (ctx *Ctx) variableIntOutOfRange(value *fastjson.Value, typeStart int) *fastjson.Value {
+	res := ctx.query.Res
+	if res[typeStart] == 'l' || res[typeStart] == 'L' {
+		if value.Type() != fastjson.TypeArray {
+			// A single value is coerced into a list
+			return ctx.variableIntOutOfRange(value, typeStart+1)
+		}
+		for _, item := range value.GetArray() {
+			if item.Type() == fastjson.TypeNull {
+				continue
+			}
+			if invalid := ctx.variableIntOutOfRange(item, typeStart+1); invalid != nil {
+				return invalid
+			}
+		}
+		return nil
+	}
+
+	typeName, _ := ctx.readNameAt(typeStart + 1)
+	namedType, ok := ctx.schema.inputTypeByName(typeName)
+	if !ok {
+		return nil
+	}
+	return ctx.schema.inputIntOutOfRange(value, &namedType)
+}
+
+// inputIntOutOfRange returns the first Int within the json value bound to in that doesn't fit in 32 bits, nil if there is none
+// Other type mismatches are reported when the value is bound
+func (s *Schema) inputIntOutOfRange(value *fastjson.Value, in *input) *fastjson.Value {
+	if value.Type() == fastjson.TypeNull {
+		return nil
+	}
+	if in.kind == reflect.Ptr && !in.isFile && !in.isScalar {
+		in = in.elem
+	}
+	if in.isScalar || in.isJSON || in.isEnum || in.isID || in.isTime || in.isFile {
+		return nil
+	}
+
+	switch in.kind {
+	case reflect.Array, reflect.Slice:
+		if value.Type() != fastjson.TypeArray {
+			return s.inputIntOutOfRange(value, in.elem)
+		}
+		for _, item := range value.GetArray() {
+			if invalid := s.inputIntOutOfRange(item, in.elem); invalid != nil {
+				return invalid
+			}
+		}
+	case reflect.Struct:
+		if value.Type() != fastjson.TypeObject {
+			return nil
+		}
+		structure := in
+		if in.isStructPointers {
+			structure = s.inTypes[in.structName]
+		}
+		for name, fieldInput := range structure.structContent {
+			fieldValue := value.Get(name)
+			if fieldValue == nil {
+				continue
+			}
+			if invalid := s.inputIntOutOfRange(fieldValue, &fieldInput); invalid != nil {
+				return invalid
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Type() != fastjson.TypeNumber {
+			return nil
+		}
+		number := value.GetFloat64()
+		if number == math.Trunc(number) && (number > math.MaxInt32 || number < math.MinInt32) {
+			return value
+		}
+	}
+	return nil
+}
+
+// collectResponseNames appends the response names of the fields selected by the selection set starting at pos to ctx.validator.names
+// Fragment spreads are followed, depth is used to stop following fragments that spread themselves
+func (ctx *Ctx) collectResponseNames(pos int, depth int) {
+	v := &ctx.validator
+	res := ctx.query.Res
+
+	namesStart := len(v.names)
+	if depth > 0 {
+		// We're inside a fragment, the names of the caller are before ours
+		namesStart = 0
+	}
+
+	for {
+		switch res[pos+1] {
+		case bytecode.ActionField:
+			end := pos + 11 + int(ctx.readUint32(pos+3))
+			alias := res[pos+12 : pos+12+int(res[pos+11])]
+			if !containsName(v.names[namesStart:], b2s(alias)) {
+				v.names = append(v.names, alias)
+			}
+			pos = end
+		case bytecode.ActionSpread:
+			isInline := res[pos+2] == 't'
+			directivesCount := res[pos+3]
+			end := pos + 8 + int(ctx.readUint32(pos+4))
+			name, selectionStart := ctx.readNameAt(pos + 8)
+			if isInline {
+				ctx.collectResponseNames(ctx.skipDirectives(selectionStart, directivesCount), depth+1)
+			} else if depth < len(v.definitions) {
+				for _, definition := range v.definitions {
+					if definition.isFragment && bytes.Equal(definition.name, name) {
+						ctx.collectResponseNames(definition.selectionStart, depth+1)
+						break
+					}
+				}
+			}
+			pos = end
+		default:
+			return
+		}
+	}
+}
+
+// skipDirectives returns the start of the instruction after the directives starting at pos
+func (ctx *Ctx) skipDirectives(pos int, count uint8) int {
+	for i := uint8(0); i < count; i++ {
+		hasArguments := ctx.query.Res[pos+2] == 't'
+		_, pos = ctx.readNameAt(pos + 3)
+		if hasArguments {
+			pos += 7 + int(ctx.readUint32(pos+3))
+		}
+	}
+	return pos
+}
+
+// variableTypeString returns the graphql type starting at typeStart as a string
+func (ctx *Ctx) variableTypeString(typeStart int) string {
+	res := ctx.query.Res
+	prefix := []byte{}
+	suffix := []byte{}
+	for {
+		switch res[typeStart] {
+		case 'l':
+			prefix = append(prefix, '[')
+			suffix = append([]byte{']'}, suffix...)
+		case 'L':
+			prefix = append(prefix, '[')
+			suffix = append([]byte{']', '!'}, suffix...)
+		case 'n', 'N':
+			name, _ := ctx.readNameAt(typeStart + 1)
+			prefix = append(prefix, name...)
+			if res[typeStart] == 'N' {
+				prefix = append(prefix, '!')
+			}
+			return string(append(prefix, suffix...))
+		}
+		typeStart++
+	}
+}
+
+// appendValue appends the graphql representation of the value starting at pos to target
+func (ctx *Ctx) appendValue(pos int, target []byte) []byte {
+	res := ctx.query.Res
+	kind := res[pos+2]
+	start := pos + 7
+	end := start + int(ctx.readUint32(pos+3))
+
+	switch kind {
+	case bytecode.ValueVariable:
+		target = append(target, '$')
+		target = append(target, res[start:end]...)
+	case bytecode.ValueInt, bytecode.ValueFloat, bytecode.ValueEnum:
+		target = append(target, res[start:end]...)
+	case bytecode.ValueString:
+		helpers.StringToJSON(b2s(res[start:end]), &target)
+	case bytecode.ValueBoolean:
+		if res[start] == '1' {
+			target = append(target, "true"...)
+		} else {
+			target = append(target, "false"...)
+		}
+	case bytecode.ValueNull:
+		target = append(target, "null"...)
+	case bytecode.ValueList:
+		target = append(target, '[')
+		for item := start; res[item+1] != bytecode.ActionEnd; {
+			if item != start {
+				target = append(target, ", "...)
+			}
+			target = ctx.appendValue(item, target)
+			item = item + 7 + int(ctx.readUint32(item+3))
+		}
+		target = append(target, ']')
+	case bytecode.ValueObject:
+		target = append(target, '{')
+		for field := start; res[field+1] == bytecode.ActionObjectValueField; {
+			if field != start {
+				target = append(target, ", "...)
+			}
+			name, valueStart := ctx.readNameAt(field + 2)
+			target = append(target, name...)
+			target = append(target, ": "...)
+			target = ctx.appendValue(valueStart, target)
+			field = valueStart + 7 + int(ctx.readUint32(valueStart+3))
+		}
+		target = append(target, '}')
+	}
+	return target
+}
+
+// typesOverlap returns true if there is a object type that can be both of type a and b
+// a and b must be objects or interfaces
+func typesOverlap(a, b *obj) bool {
+	if a.typeName == b.typeName {
+		return true
+	}
+	if a.valueType == valueTypeObj && b.valueType == valueTypeObj {
+		return false
+	}
+	if a.valueType == valueTypeObj {
+		return typeConditionMatches(a, b.typeNameBytes)
+	}
+	if b.valueType == valueTypeObj {
+		return typeConditionMatches(b, a.typeNameBytes)
+	}
+
+	for _, aImplementation := range a.implementations {
+		for _, bImplementation := range b.implementations {
+			if aImplementation.typeName == bImplementation.typeName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// namedOutputType returns the object, interface or scalar type a field resolves to without the list and pointer wrappers
+func (s *Schema) namedOutputType(item *obj) *obj {
+	for {
+		switch item.valueType {
+		case valueTypeMethod:
+			item = &item.method.outType
+		case valueTypeArray, valueTypePtr:
+			item = item.innerContent
+		case valueTypeObjRef:
+			return s.types[item.typeName]
+		case valueTypeInterfaceRef:
+			return s.interfaces[item.typeName]
+		default:
+			return item
+		}
+	}
+}
+
+// inputTypeByName returns the input matching a graphql input type name
+func (s *Schema) inputTypeByName(name []byte) (input, bool) {
+	switch b2s(name) {
+	case "Boolean":
+		return input{kind: reflect.Bool}, true
+	case "Int":
+		return input{kind: reflect.Int}, true
+	case "Float":
+		return input{kind: reflect.Float64}, true
+	case "String":
+		return input{kind: reflect.String}, true
+	case "ID":
+		return input{kind: reflect.String, isID: true}, true
+	case "Time":
+		return input{kind: reflect.String, isTime: true}, true
+	case "File":
+		return input{kind: reflect.Ptr, isFile: true}, true
+	}
+
+	if s.usesJSONScalar && b2s(name) == "JSON" {
+		return input{kind: reflect.Map, isJSON: true}, true
+	}
+
+	for idx, enum := range s.definedEnums {
+		if enum.typeName == b2s(name) {
+			return input{kind: enum.contentKind, isEnum: true, enumTypeIndex: idx}, true
+		}
+	}
+
+	for idx, scalar := range s.definedScalars {
+		if scalar.typeName == b2s(name) {
+			return input{kind: scalar.goType.Kind(), isScalar: true, scalarIndex: idx}, true
+		}
+	}
+
+	_, ok := s.inTypes[b2s(name)]
+	if ok {
+		return input{kind: reflect.Struct, structName: string(name), isStructPointers: true}, true
+	}
+
+	return input{}, false
+}
+
+// isOutputTypeName returns true if name is the name of a object or interface
+func (s *Schema) isOutputTypeName(name []byte) bool {
+	_, ok := s.types[b2s(name)]
+	if ok {
+		return true
+	}
+	_, ok = s.interfaces[b2s(name)]
+	return ok
+}
+
+// inputNamedTypeName returns the graphql type name of a input without list and non null wrappers
+func (s *Schema) inputNamedTypeName(in *input) string {
+	switch {
+	case in.isID:
+		return "ID"
+	case in.isTime:
+		return "Time"
+	case in.isFile:
+		return "File"
+	case in.isJSON:
+		return "JSON"
+	case in.isEnum:
+		return s.definedEnums[in.enumTypeIndex].typeName
+	case in.isScalar:
+		return s.definedScalars[in.scalarIndex].typeName
+	}
+
+	switch in.kind {
+	case reflect.Bool:
+		return "Boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "Int"
+	case reflect.Float32, reflect.Float64:
+		return "Float"
+	case reflect.String:
+		return "String"
+	case reflect.Struct:
+		return in.structName
+	default:
+		return ""
+	}
+}
+
+// inputIsRequired returns true if the input must be provided, this is the case for non null inputs without a default value
+func inputIsRequired(in *input) bool {
+	return in.defaultValue == nil && inputIsNonNull(in)
+}
+
+// inputIsNonNull returns true if the graphql type of the input is non null, this matches the output of (*Schema).inputToQLType
+func inputIsNonNull(in *input) bool {
+	if in.isScalar {
+		return in.kind != reflect.Ptr
+	}
+	return !in.isFile && !in.isJSON && in.kind != reflect.Ptr && in.kind != reflect.Array && in.kind != reflect.Slice
+}
+
+// directiveExists returns true if a directive with the name is defined for any location
+func (s *Schema) directiveExists(name string) bool {
+	for _, directives := range s.definedDirectives {
+		for _, directive := range directives {
+			if directive.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package yarql
+
+import (
+	"testing"
+
+	a "github.com/mjarkk/yarql/assert"
+	"github.com/mjarkk/yarql/bytecode"
+)
+
+type TestValidateData struct {
+	Name    string
+	User    TestValidateUser
+	Generic InterfaceType
+	Bar     BarWImpl
+}
+
+func (TestValidateData) ResolveGreet(ctx *Ctx, args struct {
+	Name  string
+	Times *int
+}) string {
+	ctx.SetValue("called", true)
+	return "hello " + args.Name
+}
+
+func (TestValidateData) ResolveSearch(args struct{ Filter TestValidateFilter }) []TestValidateUser {
+	return []TestValidateUser{}
+}
+
+func (TestValidateData) ResolveIds(args struct{ Ids []int }) []int {
+	return args.Ids
+}
+
+type TestValidateFilter struct {
+	Name  string
+	Limit *int
+}
+
+type TestValidateUser struct {
+	Id   uint `gq:",id"`
+	Name string
+}
+
+type TestValidateSubscription struct{}
+
+func (TestValidateSubscription) ResolveA() <-chan string { return nil }
+func (TestValidateSubscription) ResolveB() <-chan string { return nil }
+
+func validateQuery(t *testing.T, query string, opts ...ResolveOptions) []string {
+	Implements((*InterfaceType)(nil), BarWImpl{})
+	Implements((*InterfaceType)(nil), BazWImpl{})
+
+	s := NewSchema()
+	err := s.Parse(TestValidateData{}, M{}, &SchemaOptions{Subscriptions: TestValidateSubscription{}})
+	a.NoError(t, err)
+
+	if len(opts) == 0 {
+		opts = []ResolveOptions{{NoMeta: true}}
+	}
+	errs := s.Resolve([]byte(query), opts[0])
+	res := []string{}
+	for _, err := range errs {
+		res = append(res, err.Error())
+	}
+	return res
+}
+
+func TestValidateValidQueries(t *testing.T) {
+	queries := []string{
+		`{name user {id name}}`,
+		`{greet(name: "foo") greet2: greet(name: "bar", times: 2)}`,
+		`query ($name: String = "foo") {greet(name: $name)}`,
+		`{search(filter: {name: "foo"}) {id}}`,
+		`{ids(ids: [1, 2, 3])}`,
+		`{ids(ids: 1)}`,
+		`{__typename user {__typename}}`,
+		`{...fragmentA} fragment fragmentA on TestValidateData {name}`,
+		`{generic {foo ... on BarWImpl {extraBarField}}}`,
+		`{bar {...fragmentA}} fragment fragmentA on InterfaceType {foo}`,
+		`{name @skip(if: true)}`,
+		`query a {name} query b {name}`,
+	}
+
+	for _, query := range queries {
+		a.Equal(t, []string{}, validateQuery(t, query), query)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		query  string
+		expect string
+	}{
+		{"unknown field", `{foo}`, `Cannot query field "foo" on type "TestValidateData".`},
+		{"unknown nested field", `{user {foo}}`, `Cannot query field "foo" on type "TestValidateUser".`},
+		{"leaf with selection", `{name {foo}}`, `Field "name" must not have a selection since type "String!" has no subfields.`},
+		{"object without selection", `{user}`, `Field "user" of type "TestValidateUser!" must have a selection of subfields.`},
+		{"unknown argument", `{greet(name: "a", foo: 1)}`, `Unknown argument "foo" on field "TestValidateData.greet".`},
+		{"duplicated argument", `{greet(name: "a", name: "b")}`, `There can be only one argument named "name".`},
+		{"missing required argument", `{greet}`, `Field "TestValidateData.greet" argument "name" of type "String!" is required, but it was not provided.`},
+		{"wrong argument type", `{greet(name: 1)}`, `Expected value of type "String!", found 1.`},
+		{"null for required argument", `{greet(name: null)}`, `Expected value of type "String!", found null.`},
+		{"unknown input field", `{search(filter: {name: "a", foo: 1}) {id}}`, `Field "foo" is not defined by type "TestValidateFilter".`},
+		{"missing input field", `{search(filter: {limit: 1}) {id}}`, `Field "TestValidateFilter.name" of required type "String!" was not provided.`},
+		{"wrong list item", `{ids(ids: [1, "a"])}`, `Expected value of type "Int!", found "a".`},
+		{"wrong single list item", `{ids(ids: "a")}`, `Expected value of type "Int!", found "a".`},
+		{"int out of range", `{ids(ids: [1, 4611686018427387904])}`, `Int cannot represent non 32-bit signed integer value: 4611686018427387904`},
+		{"input field int out of range", `{search(filter: {name: "a", limit: -2147483649}) {id}}`, `Int cannot represent non 32-bit signed integer value: -2147483649`},
+		{"unknown fragment", `{...foo}`, `Unknown fragment "foo".`},
+		{"unused fragment", `{name} fragment foo on TestValidateData {name}`, `Fragment "foo" is never used.`},
+		{"duplicated fragment", `{...foo} fragment foo on TestValidateData {name} fragment foo on TestValidateData {name}`, `There can be only one fragment named "foo".`},
+		{"fragment on unknown type", `{...foo} fragment foo on Foo {name}`, `Unknown type "Foo".`},
+		{"fragment on scalar", `{...foo} fragment foo on String {name}`, `Fragment "foo" cannot condition on non composite type "String".`},
+		{"fragment cycle", `{...foo} fragment foo on TestValidateData {...bar} fragment bar on TestValidateData {...foo}`, `Cannot spread fragment "foo" within itself.`},
+		{"fragment type mismatch", `{user {...foo}} fragment foo on TestValidateData {name}`, `Fragment "foo" cannot be spread here as objects of type "TestValidateUser" can never be of type "TestValidateData".`},
+		{"inline fragment type mismatch", `{user {... on BarWImpl {foo}}}`, `Fragment cannot be spread here as objects of type "TestValidateUser" can never be of type "BarWImpl".`},
+		{"undefined variable", `{greet(name: $name)}`, `Variable "$name" is not defined.`},
+		{"undefined variable in named operation", `query foo {greet(name: $name)}`, `Variable "$name" is not defined by operation "foo".`},
+		{"unused variable", `query ($name: String!) {name}`, `Variable "$name" is never used.`},
+		{"duplicated variable", `query ($name: String!, $name: String!) {greet(name: $name)}`, `There can be only one variable named "$name".`},
+		{"variable of unknown type", `query ($name: Foo) {greet(name: $name)}`, `Unknown type "Foo".`},
+		{"variable of output type", `query ($user: TestValidateUser) {name(a: $user)}`, `Variable "$user" cannot be non-input type "TestValidateUser".`},
+		{"nullable variable in non null position", `query ($name: String) {greet(name: $name)}`, `Variable "$name" of type "String" used in position expecting type "String!".`},
+		{"unknown directive", `{name @foo}`, `Unknown directive "@foo".`},
+		{"duplicated directive", `{name @skip(if: false) @skip(if: false)}`, `The directive "@skip" can only be used once at this location.`},
+		{"directive on wrong location", `query @skip(if: true) {name}`, `Directive "@skip" may not be used on QUERY.`},
+		{"missing directive argument", `{name @skip}`, `Directive "@skip" argument "if" of type "Boolean!" is required, but it was not provided.`},
+		{"duplicated operation", `query a {name} query a {name}`, `There can be only one operation named "a".`},
+		{"anonymous operation not alone", `{name} query a {name}`, `This anonymous operation must be the only defined operation.`},
+		{"multiple subscription fields", `subscription foo {a b}`, `Subscription "foo" must select only one top level field.`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			errs := validateQuery(t, testCase.query)
+			a.NotEqual(t, 0, len(errs), testCase.query)
+			if len(errs) > 0 {
+				a.Equal(t, testCase.expect, errs[0], testCase.query)
+			}
+		})
+	}
+}
+
+func TestValidateRequiredVariables(t *testing.T) {
+	query := `query ($name: String!) {greet(name: $name)}`
+
+	errs := validateQuery(t, query)
+	a.Equal(t, []string{`Variable "$name" of required type "String!" was not provided.`}, errs)
+
+	errs = validateQuery(t, query, ResolveOptions{Variables: `{"name": null}`})
+	a.Equal(t, []string{`Variable "$name" of non-null type "String!" must not be null.`}, errs)
+
+	errs = validateQuery(t, query, ResolveOptions{Variables: `{"name": "foo"}`})
+	a.Equal(t, []string{}, errs)
+
+	// Only the variables of the executed operation must be provided
+	errs = validateQuery(t, `query a($name: String!) {greet(name: $name)} query b {name}`, ResolveOptions{OperatorTarget: "b"})
+	a.Equal(t, []string{}, errs)
+}
+
+func TestValidateIntVariableRange(t *testing.T) {
+	query := `query ($ids: [Int!]!) {ids(ids: $ids)}`
+
+	errs := validateQuery(t, query, ResolveOptions{Variables: `{"ids": [2147483647, -2147483648]}`})
+	a.Equal(t, []string{}, errs)
+
+	errs = validateQuery(t, query, ResolveOptions{Variables: `{"ids": [1, 4611686018427387904]}`})
+	a.Equal(t, []string{`Variable "$ids" got invalid value [1,4611686018427387904]; Int cannot represent non 32-bit signed integer value: 4611686018427387904`}, errs)
+
+	errs = validateQuery(t, query, ResolveOptions{Variables: `{"ids": 2147483648}`})
+	a.Equal(t, []string{`Variable "$ids" got invalid value 2147483648; Int cannot represent non 32-bit signed integer value: 2147483648`}, errs)
+
+	query = `query ($filter: TestValidateFilter!) {search(filter: $filter) {id}}`
+	errs = validateQuery(t, query, ResolveOptions{Variables: `{"filter": {"name": "a", "limit": 2147483648}}`})
+	a.Equal(t, []string{`Variable "$filter" got invalid value {"name":"a","limit":2147483648}; Int cannot represent non 32-bit signed integer value: 2147483648`}, errs)
+}
+
+func TestValidateSingleValueList(t *testing.T) {
+	// A single value is coerced into a list with one item
+	res, errs := bytecodeParse(t, NewSchema(), `{ids(ids: 1)}`, TestValidateData{}, M{}, ResolveOptions{NoMeta: true})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"ids":[1]}`, res)
+
+	res, errs = bytecodeParse(t, NewSchema(), `query ($ids: [Int!]!) {ids(ids: $ids)}`, TestValidateData{}, M{}, ResolveOptions{
+		NoMeta:    true,
+		Variables: `{"ids": 2}`,
+	})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, `{"ids":[2]}`, res)
+}
+
+func TestValidateErrorLocation(t *testing.T) {
+	Implements((*InterfaceType)(nil), BarWImpl{})
+	Implements((*InterfaceType)(nil), BazWImpl{})
+
+	s := NewSchema()
+	err := s.Parse(TestValidateData{}, M{}, nil)
+	a.NoError(t, err)
+
+	errs := s.Resolve([]byte("{\n  name\n  foo\n}"), ResolveOptions{})
+	a.Equal(t, 1, len(errs))
+	errWLocation, ok := errs[0].(bytecode.ErrorWLocation)
+	a.True(t, ok)
+	a.Equal(t, uint(3), errWLocation.Line)
+	a.Equal(t, uint(2), errWLocation.Column)
+	a.Equal(t, `{"data":{},"errors":[{"message":"Cannot query field \"foo\" on type \"TestValidateData\".","locations":[{"line":3,"column":3}]}],"extensions":{}}`, string(s.Result))
+}
+
+func TestValidateDoesNotExecute(t *testing.T) {
+	Implements((*InterfaceType)(nil), BarWImpl{})
+	Implements((*InterfaceType)(nil), BazWImpl{})
+
+	s := NewSchema()
+	err := s.Parse(TestValidateData{}, M{}, nil)
+	a.NoError(t, err)
+
+	values := map[string]interface{}{}
+	errs := s.Resolve([]byte(`{greet(name: "foo") foo}`), ResolveOptions{Values: &values})
+	a.Equal(t, 1, len(errs))
+	a.Nil(t, values["called"])
+
+	errs = s.Resolve([]byte(`{greet(name: "foo")}`), ResolveOptions{Values: &values})
+	a.Equal(t, 0, len(errs))
+	a.Equal(t, true, values["called"])
+}
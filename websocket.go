@@ -0,0 +1,318 @@
+package yarql
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// This file contains a minimal websocket implementation as described in RFC 6455
+// It only implements what's required for (*Schema).WebSocketHandler so we don't need an extra dependency
+
+const (
+	wsOpContinuation byte = 0x0
+	wsOpText         byte = 0x1
+	wsOpBinary       byte = 0x2
+	wsOpClose        byte = 0x8
+	wsOpPing         byte = 0x9
+	wsOpPong         byte = 0xA
+)
+
+// Close status codes defined by RFC 6455
+const (
+	wsCloseProtocolError uint16 = 1002
+	wsCloseNoStatus      uint16 = 1005
+	wsCloseTooBig        uint16 = 1009
+)
+
+const wsMaxMessageSize = 4 << 20 // 4mb
+
+const wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// wsCloseError is returned when reading from a connection that is closed by the other side
+type wsCloseError struct {
+	code   uint16
+	reason string
+}
+
+func (e wsCloseError) Error() string {
+	return fmt.Sprintf("websocket closed with code %d: %s", e.code, e.reason)
+}
+
+type wsConn struct {
+	conn      net.Conn
+	reader    *bufio.Reader
+	isClient  bool // Frames send by the client must be masked
+	writeLock sync.Mutex
+	closed    bool // A close frame has been send
+}
+
+func wsAcceptKey(key string) string {
+	hasher := sha1.New()
+	hasher.Write([]byte(key))
+	hasher.Write([]byte(wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(hasher.Sum(nil))
+}
+
+// headerContainsToken returns true if one of the comma separated values in the header equals token (case insensitive)
+func headerContainsToken(header http.Header, key string, token string) bool {
+	for _, value := range header.Values(key) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wsUpgrade upgrades a http request to a websocket connection using the sub protocol
+// On errors a http error response is written
+func wsUpgrade(w http.ResponseWriter, r *http.Request, protocol string) (*wsConn, error) {
+	fail := func(status int, msg string) (*wsConn, error) {
+		http.Error(w, msg, status)
+		return nil, errors.New(msg)
+	}
+
+	if r.Method != http.MethodGet {
+		return fail(http.StatusMethodNotAllowed, "websocket upgrade requires a GET request")
+	}
+	if !headerContainsToken(r.Header, "Connection", "upgrade") || !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		return fail(http.StatusBadRequest, "expected a websocket upgrade request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return fail(http.StatusBadRequest, "unsupported websocket version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return fail(http.StatusBadRequest, "missing Sec-WebSocket-Key header")
+	}
+	if !headerContainsToken(r.Header, "Sec-WebSocket-Protocol", protocol) {
+		return fail(http.StatusBadRequest, "unsupported websocket sub protocol, expected "+protocol)
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return fail(http.StatusInternalServerError, "websocket upgrade not supported by the http server")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return fail(http.StatusInternalServerError, err.Error())
+	}
+
+	_, err = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + wsAcceptKey(key) + "\r\n" +
+		"Sec-WebSocket-Protocol: " + protocol + "\r\n\r\n")
+	if err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &wsConn{
+		conn:   conn,
+		reader: rw.Reader,
+	}, nil
+}
+
+// readMessage reads the next text or binary message
+// Control frames are handled internally, if the connection is closed by the other side a wsCloseError is returned
+func (c *wsConn) readMessage() (opcode byte, message []byte, err error) {
+	for {
+		fin, frameOpcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch frameOpcode {
+		case wsOpPing:
+			err = c.writeFrame(wsOpPong, payload)
+			if err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			closeErr := wsCloseError{code: wsCloseNoStatus}
+			if len(payload) >= 2 {
+				closeErr.code = binary.BigEndian.Uint16(payload)
+				closeErr.reason = string(payload[2:])
+			}
+			c.writeClose(closeErr.code, "")
+			return 0, nil, closeErr
+		case wsOpText, wsOpBinary:
+			if opcode != 0 {
+				c.writeClose(wsCloseProtocolError, "expected continuation frame")
+				return 0, nil, errors.New("expected continuation frame")
+			}
+			opcode = frameOpcode
+		case wsOpContinuation:
+			if opcode == 0 {
+				c.writeClose(wsCloseProtocolError, "unexpected continuation frame")
+				return 0, nil, errors.New("unexpected continuation frame")
+			}
+		default:
+			c.writeClose(wsCloseProtocolError, "unknown opcode")
+			return 0, nil, fmt.Errorf("unknown websocket opcode %d", frameOpcode)
+		}
+
+		if len(message)+len(payload) > wsMaxMessageSize {
+			c.writeClose(wsCloseTooBig, "message too big")
+			return 0, nil, errors.New("websocket message too big")
+		}
+		message = append(message, payload...)
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	_, err = io.ReadFull(c.reader, header[:])
+	if err != nil {
+		return
+	}
+
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	if masked == c.isClient {
+		// Frames from the client must be masked and frames from the server must not be masked
+		c.writeClose(wsCloseProtocolError, "invalid frame masking")
+		err = errors.New("invalid websocket frame masking")
+		return
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		_, err = io.ReadFull(c.reader, extended[:])
+		if err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		_, err = io.ReadFull(c.reader, extended[:])
+		if err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if opcode >= wsOpClose && (length > 125 || !fin) {
+		c.writeClose(wsCloseProtocolError, "invalid control frame")
+		err = errors.New("invalid websocket control frame")
+		return
+	}
+	if length > wsMaxMessageSize {
+		c.writeClose(wsCloseTooBig, "message too big")
+		err = errors.New("websocket message too big")
+		return
+	}
+
+	var mask [4]byte
+	if masked {
+		_, err = io.ReadFull(c.reader, mask[:])
+		if err != nil {
+			return
+		}
+	}
+
+	payload = make([]byte, length)
+	_, err = io.ReadFull(c.reader, payload)
+	if err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
+
+// writeFrame writes a single frame, it's safe to be called from multiple goroutines
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	if c.closed {
+		return errors.New("websocket closed")
+	}
+	if opcode == wsOpClose {
+		c.closed = true
+	}
+
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, 0x80|opcode)
+
+	var maskBit byte
+	if c.isClient {
+		maskBit = 0x80
+	}
+
+	length := len(payload)
+	switch {
+	case length <= 125:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, maskBit|126, byte(length>>8), byte(length))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = append(frame, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[len(frame)-8:], uint64(length))
+	}
+
+	if c.isClient {
+		var mask [4]byte
+		_, err := rand.Read(mask[:])
+		if err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+func (c *wsConn) writeText(message []byte) error {
+	return c.writeFrame(wsOpText, message)
+}
+
+// writeClose sends a close frame, after this no other frames can be written
+func (c *wsConn) writeClose(code uint16, reason string) error {
+	payload := []byte{}
+	if code != wsCloseNoStatus {
+		payload = make([]byte, 2, 2+len(reason))
+		binary.BigEndian.PutUint16(payload, code)
+		payload = append(payload, reason...)
+	}
+	return c.writeFrame(wsOpClose, payload)
+}
+
+func (c *wsConn) close() error {
+	return c.conn.Close()
+}